@@ -0,0 +1,211 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"crucible/internal/logging"
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/archive"
+	"crucible/internal/monitor/storage"
+)
+
+// runExport writes entities, events and metrics of the monitoring database to files
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configFile := fs.String("config", "", "Path to configuration file")
+	data := fs.String("data", strings.Join(archive.Datasets, ","), "Comma-separated datasets: entities, events, metrics")
+	format := fs.String("format", archive.FormatCSV, "Output format: csv, jsonl or parquet")
+	since := fs.String("since", "", "Start of the time range, RFC3339 or a duration before now (e.g. 168h)")
+	until := fs.String("until", "", "End of the time range, RFC3339 or a duration before now")
+	metric := fs.String("metric", "", "Only export this metric")
+	output := fs.String("output", ".", "Output directory, or - to write a single dataset to stdout")
+	fs.Parse(args)
+
+	options := archive.ExportOptions{Format: *format, MetricName: *metric}
+	var err error
+	if options.Since, err = parseTimeFlag(*since); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -since: %v\n", err)
+		return 2
+	}
+	if options.Until, err = parseTimeFlag(*until); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -until: %v\n", err)
+		return 2
+	}
+	if _, err := archive.ContentType(*format); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	datasets := strings.Split(*data, ",")
+	for _, dataset := range datasets {
+		if !archive.IsDataset(dataset) {
+			fmt.Fprintf(os.Stderr, "Unknown dataset: %s\n", dataset)
+			return 2
+		}
+	}
+	if *output == "-" && len(datasets) != 1 {
+		fmt.Fprintf(os.Stderr, "Writing to stdout needs a single dataset, use -data\n")
+		return 2
+	}
+
+	store, err := openArchiveStorage(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	if *output == "-" {
+		if _, err := archive.Export(store, os.Stdout, datasets[0], options); err != nil {
+			fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create output directory: %v\n", err)
+		return 1
+	}
+	for _, dataset := range datasets {
+		path := filepath.Join(*output, archive.FileName(dataset, *format))
+		count, err := exportFile(store, path, dataset, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Export of %s failed: %v\n", dataset, err)
+			return 1
+		}
+		fmt.Printf("Exported %d %s to %s\n", count, dataset, path)
+	}
+
+	return 0
+}
+
+// exportFile exports a dataset into a new file
+func exportFile(store storage.Storage, path, dataset string, options archive.ExportOptions) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	count, err := archive.Export(store, file, dataset, options)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return count, err
+}
+
+// runImport reads exported files into the monitoring database
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configFile := fs.String("config", "", "Path to configuration file")
+	format := fs.String("format", "", "Input format: csv, jsonl or parquet (default: detected)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import [flags] file... (- reads stdin)\n", AppName)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	// Open all files first so entities are imported before what references them
+	type importFile struct {
+		name   string
+		reader *archive.Reader
+	}
+	var files []importFile
+	for _, name := range fs.Args() {
+		var input io.Reader = os.Stdin
+		if name != "-" {
+			file, err := os.Open(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				return 1
+			}
+			defer file.Close()
+			input = file
+		}
+
+		reader, err := archive.NewReader(input, *format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", name, err)
+			return 1
+		}
+		files = append(files, importFile{name: name, reader: reader})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return datasetOrder(files[i].reader.Dataset) < datasetOrder(files[j].reader.Dataset)
+	})
+
+	store, err := openArchiveStorage(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	for _, file := range files {
+		result, err := archive.Import(store, file.reader)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Import of %s failed after %d rows: %v\n", file.name, result.Imported, err)
+			return 1
+		}
+		fmt.Printf("%s: %d %s imported, %d updated, %d already present\n",
+			file.name, result.Imported, result.Dataset, result.Updated, result.Skipped)
+	}
+
+	return 0
+}
+
+// datasetOrder returns the import position of a dataset
+func datasetOrder(dataset string) int {
+	for i, name := range archive.Datasets {
+		if name == dataset {
+			return i
+		}
+	}
+	return len(archive.Datasets)
+}
+
+// openArchiveStorage opens the database of the configured agent
+func openArchiveStorage(configFile string) (storage.Storage, error) {
+	config, err := monitor.LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if config.Storage.Type != "sqlite" {
+		return nil, fmt.Errorf("%s storage is not persistent, use the export and import API of the running agent", config.Storage.Type)
+	}
+
+	logger, err := logging.NewLogger(fmt.Sprintf("/tmp/%s-archive.log", AppName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	// Keep stdout for exported data
+	logger.SetOutput(os.Stderr)
+	return storage.NewStorage(config, logger)
+}
+
+// parseTimeFlag parses an RFC3339 time or a duration before now
+func parseTimeFlag(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected RFC3339 time or duration: %s", value)
+	}
+	t := time.Now().Add(-d)
+	return &t, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"crucible/internal/logging"
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/storage"
+)
+
+// dbCommands lists the subcommands of db with their descriptions
+var dbCommands = [][2]string{
+	{"status", "Show the database file, size and migration status"},
+	{"migrate", "Apply pending migrations, backing up the database first"},
+	{"rollback", "Roll back the last applied migration, backing up the database first"},
+	{"validate", "Check that the schema matches this version"},
+	{"vacuum", "Rebuild the database file to reclaim free space"},
+	{"integrity", "Run SQLite integrity and foreign key checks"},
+}
+
+// runDB runs maintenance commands on the monitoring database
+func runDB(args []string) int {
+	fs := flag.NewFlagSet("db", flag.ExitOnError)
+	configFile := fs.String("config", "", "Path to configuration file")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s db [flags] command\n\nCommands:\n", AppName)
+		for _, command := range dbCommands {
+			fmt.Fprintf(fs.Output(), "  %-10s %s\n", command[0], command[1])
+		}
+		fmt.Fprintf(fs.Output(), "\nmigrate and rollback refuse to run while the agent has the database open.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	command := fs.Arg(0)
+	known := false
+	for _, c := range dbCommands {
+		known = known || c[0] == command
+	}
+	if !known {
+		fmt.Fprintf(os.Stderr, "Unknown db command: %s\n", command)
+		fs.Usage()
+		return 2
+	}
+
+	config, err := monitor.LoadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	if config.Storage.Type != "sqlite" {
+		fmt.Fprintf(os.Stderr, "%s storage has no database to maintain\n", config.Storage.Type)
+		return 1
+	}
+
+	logger, err := logging.NewLogger(fmt.Sprintf("/tmp/%s-db.log", AppName))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return 1
+	}
+	logger.SetOutput(os.Stderr)
+
+	db, err := storage.OpenDatabaseMaintenance(storage.NewSQLiteConfig(config), logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	switch command {
+	case "status":
+		return dbStatus(db)
+	case "migrate":
+		applied, backup, err := db.Migrate()
+		if backup != "" {
+			fmt.Printf("Backup written to %s\n", backup)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+			return 0
+		}
+		for _, version := range applied {
+			fmt.Printf("Applied migration %s\n", version)
+		}
+	case "rollback":
+		version, backup, err := db.Rollback()
+		if backup != "" {
+			fmt.Printf("Backup written to %s\n", backup)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Rolled back migration %s\n", version)
+		fmt.Println("Install the version to downgrade to before starting the agent, which applies pending migrations on start")
+	case "validate":
+		problems, err := db.Validate()
+		return reportProblems("Schema is valid", problems, err)
+	case "vacuum":
+		before, after, err := db.Vacuum()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Vacuum failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Database size %.1f MB -> %.1f MB\n", float64(before)/1e6, float64(after)/1e6)
+	case "integrity":
+		problems, err := db.IntegrityCheck()
+		return reportProblems("Database is intact", problems, err)
+	}
+
+	return 0
+}
+
+// dbStatus prints the database status
+func dbStatus(db *storage.DatabaseMaintenance) int {
+	status, err := db.Status()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get database status: %v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Database\t%s\n", status.Path)
+	fmt.Fprintf(w, "Driver\t%s\n", status.Driver)
+	fmt.Fprintf(w, "Journal mode\t%s\n", status.JournalMode)
+	fmt.Fprintf(w, "File size\t%.1f MB\n", float64(status.FileSize)/1e6)
+	fmt.Fprintf(w, "Used size\t%.1f MB (%d free pages of %d bytes)\n",
+		float64(status.UsedSize)/1e6, status.FreePages, status.PageSize)
+	schema := "valid"
+	if status.SchemaError != "" {
+		schema = status.SchemaError
+	}
+	fmt.Fprintf(w, "Schema\t%s\n", schema)
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "VERSION\tSTATUS\tAPPLIED AT\tDESCRIPTION\n")
+	for _, migration := range status.Migrations {
+		state, appliedAt := "pending", "-"
+		if migration.Applied {
+			state = "applied"
+			appliedAt = migration.AppliedAt.Local().Format(time.DateTime)
+		}
+		switch {
+		case migration.Unknown:
+			state += " (unknown)"
+		case migration.Modified:
+			state += " (modified)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", migration.Version, state, appliedAt, migration.Description)
+	}
+	w.Flush()
+
+	return 0
+}
+
+// reportProblems prints the problems of a check, exiting with 1 when any
+// were found
+func reportProblems(ok string, problems []string, err error) int {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Check failed: %v\n", err)
+		return 1
+	}
+	if len(problems) == 0 {
+		fmt.Println(ok)
+		return 0
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	return 1
+}
@@ -30,6 +30,17 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "db":
+			os.Exit(runDB(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
 
 	// Show version and exit if requested
@@ -84,9 +95,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Set up signal handling for graceful shutdown
+	// Set up signal handling for graceful shutdown and configuration reload
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Start the agent in a goroutine
 	errChan := make(chan error, 1)
@@ -98,13 +109,23 @@ func main() {
 
 	logger.Info("Monitoring agent started successfully")
 
-	// Wait for shutdown signal or error
-	select {
-	case sig := <-sigChan:
-		logger.Info("Received shutdown signal", "signal", sig.String())
-	case err := <-errChan:
-		logger.Error("Agent startup failed", "error", err)
-		os.Exit(1)
+	// Wait for shutdown signal or error, reloading the configuration on SIGHUP
+	for running := true; running; {
+		select {
+		case sig := <-sigChan:
+			if sig == syscall.SIGHUP {
+				logger.Info("Received reload signal, reloading configuration")
+				if _, err := monitorAgent.Reload(); err != nil {
+					logger.Error("Failed to reload configuration, keeping current configuration", "error", err)
+				}
+				continue
+			}
+			logger.Info("Received shutdown signal", "signal", sig.String())
+			running = false
+		case err := <-errChan:
+			logger.Error("Agent startup failed", "error", err)
+			os.Exit(1)
+		}
 	}
 
 	// Graceful shutdown
@@ -3,16 +3,32 @@ package agent
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
 	"crucible/internal/logging"
 	"crucible/internal/monitor"
+	"crucible/internal/monitor/ai"
 	"crucible/internal/monitor/alerts"
 	"crucible/internal/monitor/collectors"
+	"crucible/internal/monitor/discovery"
+	"crucible/internal/monitor/statuspage"
 	"crucible/internal/monitor/storage"
 )
 
+// maxLogEvents bounds the number of matched journal entries kept in memory
+const maxLogEvents = 500
+
+// alertEvaluationInterval is how often alert rules are evaluated
+const alertEvaluationInterval = 30 * time.Second
+
+// customCollector pairs a generic collector with its collection interval
+type customCollector struct {
+	collector collectors.Collector
+	interval  time.Duration
+}
+
 // Agent represents the monitoring agent
 type Agent struct {
 	config    *monitor.Config
@@ -25,6 +41,11 @@ type Agent struct {
 	systemMetrics     *monitor.SystemMetrics
 	serviceMetrics    []monitor.ServiceStatus
 	httpCheckResults  []monitor.HTTPCheckResult
+	probeResults      []monitor.ProbeResult
+	pm2Apps           []monitor.PM2AppStatus
+	supervisorProcs   []monitor.SupervisorProcessStatus
+	logEvents         []monitor.LogEvent
+	collectorMetrics  map[string][]monitor.Metric // Latest sample per custom collector
 	metricsCount      int64
 	activeAlertsCount int
 
@@ -32,17 +53,60 @@ type Agent struct {
 	storageAdapter *storage.StorageAdapter
 
 	// Collectors
-	systemCollector   *collectors.SystemCollector
-	servicesCollector *collectors.ServicesCollector
-	httpCollector     *collectors.HTTPCollector
+	systemCollector     *collectors.SystemCollector
+	servicesCollector   *collectors.ServicesCollector
+	httpCollector       *collectors.HTTPCollector
+	probeCollector      *collectors.ProbeCollector
+	pm2Collector        *collectors.PM2Collector
+	supervisorCollector *collectors.SupervisorCollector
+	journalCollector    *collectors.JournalCollector
+	customCollectors    []customCollector
 
 	// Alert manager
 	alertManager *alerts.AlertManager
 
+	// Seasonal anomaly detection over stored metrics, set while its loop runs
+	anomalyDetector *ai.AnomalyDetector
+
+	// Disk, inode and database capacity forecasts, set while its loop runs
+	forecaster *ai.Forecaster
+
+	// Error budgets of the configured SLOs, set while its loop runs
+	sloTracker *sloTracker
+
+	// Incidents opened for fired alerts
+	incidents *incidentTracker
+
+	// Latest status page, rebuilt by its loop
+	statusPage   *statuspage.Page
+	statusPageMu sync.Mutex // Serializes status page builds
+
+	// Serves the status page on its own listen address, nil when not configured
+	statusServer   *http.Server
+	statusServerMu sync.Mutex
+
 	// Collection timestamps
 	lastSystemCollect     *time.Time
 	lastServicesCollect   *time.Time
 	lastHTTPChecksCollect *time.Time
+	lastProbesCollect     *time.Time
+	lastPM2Collect        *time.Time
+	lastSupervisorCollect *time.Time
+	lastJournalEvent      *time.Time
+	lastCustomCollect     map[string]*time.Time
+	customCollectErrors   map[string]string
+
+	// Durations and errors of the agent's own loops
+	self *selfMonitor
+
+	// Running loops by name, each cancellable on its own so a configuration
+	// reload can restart individual collectors
+	loopsMu  sync.Mutex
+	loops    map[string]context.CancelFunc
+	reloadMu sync.Mutex
+
+	// Serializes configuration writes made through the API
+	configWriteMu sync.Mutex
 
 	// Context for graceful shutdown
 	ctx    context.Context
@@ -54,15 +118,21 @@ func NewAgent(config *monitor.Config, logger *logging.Logger) (*Agent, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	agent := &Agent{
-		config:    config,
-		logger:    logger,
-		startTime: time.Now(),
-		ctx:       ctx,
-		cancel:    cancel,
+		config:              config,
+		logger:              logger,
+		startTime:           time.Now(),
+		collectorMetrics:    make(map[string][]monitor.Metric),
+		lastCustomCollect:   make(map[string]*time.Time),
+		customCollectErrors: make(map[string]string),
+		loops:               make(map[string]context.CancelFunc),
+		self:                newSelfMonitor(),
+		incidents:           newIncidentTracker(),
+		ctx:                 ctx,
+		cancel:              cancel,
 	}
 
 	// Initialize storage adapter if configured
-	if config.Storage.Type == "sqlite" {
+	if config.Storage.Type != "none" {
 		storageAdapter, err := storage.NewStorageAdapter(config, logger)
 		if err != nil {
 			cancel()
@@ -71,20 +141,45 @@ func NewAgent(config *monitor.Config, logger *logging.Logger) (*Agent, error) {
 		agent.storageAdapter = storageAdapter
 	}
 
+	// Add services and sites installed by crucible before collectors are created
+	agent.runDiscovery(config)
+
 	// Initialize collectors
 	agent.systemCollector = collectors.NewSystemCollector()
 	agent.servicesCollector = collectors.NewServicesCollector(config.Collectors.Services.Services)
 	agent.httpCollector = collectors.NewHTTPCollector()
+	agent.probeCollector = collectors.NewProbeCollector()
+	agent.pm2Collector = collectors.NewPM2Collector(config.Collectors.PM2.PM2Home, config.GetPM2CrashLoopWindow())
+	agent.supervisorCollector = collectors.NewSupervisorCollector(config.Collectors.Supervisor.SocketPath)
+
+	if config.Collectors.Journal.Enabled {
+		journalCollector, err := collectors.NewJournalCollector(config.Collectors.Journal.Units, config.Collectors.Journal.Patterns)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create journal collector: %w", err)
+		}
+		agent.journalCollector = journalCollector
+	}
+
+	// Initialize config-driven exec collectors
+	for _, execConfig := range config.Collectors.Exec {
+		custom, err := newExecCustomCollector(execConfig)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		agent.customCollectors = append(agent.customCollectors, custom)
+	}
 
 	// Initialize alert manager if alerts are enabled
 	if config.Alerts.Enabled {
-		alertConfig, err := alerts.LoadConfig("configs/alerts.yaml")
+		alertConfig, err := alerts.LoadConfig(config.Alerts.RulesFile)
 		if err != nil {
 			logger.Warn("Failed to load alert config, using defaults", "error", err)
 			alertConfig = alerts.CreateDefaultConfig()
 		}
 
-		alertRules, err := alerts.LoadRules("configs/alerts.yaml")
+		alertRules, err := alerts.LoadRules(config.Alerts.RulesFile)
 		if err != nil {
 			logger.Warn("Failed to load alert rules", "error", err)
 			alertRules = []*alerts.AlertRule{}
@@ -102,6 +197,35 @@ func NewAgent(config *monitor.Config, logger *logging.Logger) (*Agent, error) {
 	return agent, nil
 }
 
+// runDiscovery adds auto-discovered services and sites to the configuration
+func (a *Agent) runDiscovery(config *monitor.Config) {
+	if !config.Discovery.Enabled {
+		return
+	}
+
+	// Apply whatever was found, one of services or sites may have failed
+	result, err := discovery.Discover(config)
+	if err != nil {
+		a.logger.Warn("Auto-discovery incomplete", "error", err)
+	}
+
+	added := discovery.Apply(config, result)
+	a.logger.Info("Auto-discovery completed", "services", len(result.Services),
+		"sites", len(result.HTTPChecks), "added", added)
+}
+
+// newExecCustomCollector creates the custom collector for an exec collector configuration
+func newExecCustomCollector(execConfig monitor.ExecCollectorConfig) (customCollector, error) {
+	execCollector, err := collectors.NewExecCollector(execConfig)
+	if err != nil {
+		return customCollector{}, fmt.Errorf("failed to create exec collector: %w", err)
+	}
+	return customCollector{
+		collector: execCollector,
+		interval:  execConfig.GetInterval(),
+	}, nil
+}
+
 // Start starts the monitoring agent
 func (a *Agent) Start() error {
 	a.logger.Info("Starting monitoring agent")
@@ -109,6 +233,11 @@ func (a *Agent) Start() error {
 	// Start background collectors
 	a.startCollectors()
 
+	// Start the status page server, apart from the API
+	if err := a.syncStatusPageServer(); err != nil {
+		return err
+	}
+
 	// Start HTTP API server
 	if err := a.server.Start(); err != nil {
 		return err
@@ -135,6 +264,9 @@ func (a *Agent) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := a.stopStatusPageServer(ctx); err != nil {
+		a.logger.Error("Failed to stop status page server", "error", err)
+	}
 	if err := a.server.Stop(ctx); err != nil {
 		return err
 	}
@@ -144,30 +276,147 @@ func (a *Agent) Stop() error {
 
 // startCollectors starts all enabled data collectors
 func (a *Agent) startCollectors() {
+	config := a.GetConfig()
+
 	// Start system metrics collector
-	if a.config.Collectors.System.Enabled {
-		go a.systemCollectorLoop()
+	if config.Collectors.System.Enabled {
+		a.startLoop("system", a.systemCollectorLoop)
 	}
 
 	// Start service metrics collector
-	if a.config.Collectors.Services.Enabled {
-		go a.servicesCollectorLoop()
+	if config.Collectors.Services.Enabled {
+		a.startLoop("services", a.servicesCollectorLoop)
 	}
 
-	// Start HTTP checks collector
-	if a.config.Collectors.HTTPChecks.Enabled && len(a.config.Collectors.HTTPChecks.Checks) > 0 {
-		go a.httpChecksCollectorLoop()
+	// Start each HTTP check in its own loop
+	if config.Collectors.HTTPChecks.Enabled {
+		for _, check := range config.Collectors.HTTPChecks.Checks {
+			a.startLoop(httpCheckLoopName(check.Name), a.httpCheckLoopFunc(check))
+		}
+	}
+
+	// Start TCP, DNS, ICMP and TLS probes
+	if config.Collectors.Probes.Enabled {
+		for _, probe := range config.Collectors.Probes.Checks {
+			a.startLoop(probeLoopName(probe.Name), a.probeLoopFunc(probe))
+		}
+	}
+
+	// Start PM2 process collector
+	if config.Collectors.PM2.Enabled {
+		a.startLoop("pm2", a.pm2CollectorLoop)
+	}
+
+	// Start supervisor process collector
+	if config.Collectors.Supervisor.Enabled {
+		a.startLoop("supervisor", a.supervisorCollectorLoop)
+	}
+
+	// Start journal log collector
+	if a.journalCollector != nil {
+		a.startLoop("journal", a.journalCollectorLoop)
+	}
+
+	// Start custom collectors
+	for _, custom := range a.customCollectors {
+		a.startLoop(customLoopName(custom.collector.Name()), a.customCollectorLoopFunc(custom))
+	}
+
+	// Start anomaly detection, which learns from stored metrics
+	if config.IsAnomalyDetectionEnabled() {
+		if a.storageAdapter != nil {
+			a.startLoop("anomaly", a.anomalyLoop)
+		} else {
+			a.logger.Warn("Anomaly detection needs storage, skipping it")
+		}
+	}
+
+	// Start capacity forecasting, which fits trends to stored metrics
+	if a.storageAdapter != nil {
+		a.startLoop("forecast", a.forecastLoop)
+	}
+
+	// Start tracking SLOs, which reads check results from storage
+	if len(config.SLOs) > 0 {
+		if a.storageAdapter != nil {
+			a.startLoop("slo", a.sloLoop)
+		} else {
+			a.logger.Warn("SLO tracking needs storage, skipping it")
+		}
+	}
+
+	// Start rebuilding the status page
+	if config.StatusPage.Enabled {
+		a.startLoop("statuspage", a.statusPageLoop)
 	}
 
 	// Start alert evaluation loop
-	if a.alertManager != nil {
-		go a.alertEvaluationLoop()
+	if a.getAlertManager() != nil {
+		a.startLoop("alerts", a.alertEvaluationLoop)
+	}
+
+	// Start recording the agent's own stats as metrics
+	a.startLoop("self", a.selfMetricsLoop)
+}
+
+// startLoop runs a named loop in its own goroutine, replacing a running loop with the same name
+func (a *Agent) startLoop(name string, run func(ctx context.Context)) {
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+
+	if cancel, exists := a.loops[name]; exists {
+		cancel()
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.loops[name] = cancel
+	go run(ctx)
+}
+
+// stopLoop stops a named loop, reporting whether it was running
+func (a *Agent) stopLoop(name string) bool {
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+
+	cancel, exists := a.loops[name]
+	if exists {
+		cancel()
+		delete(a.loops, name)
 	}
+	a.self.forget(name)
+	return exists
+}
+
+// isLoopRunning reports whether a named loop is running
+func (a *Agent) isLoopRunning(name string) bool {
+	a.loopsMu.Lock()
+	defer a.loopsMu.Unlock()
+
+	_, exists := a.loops[name]
+	return exists
+}
+
+// httpCheckLoopName returns the loop name of an HTTP check
+func httpCheckLoopName(name string) string {
+	return "http:" + name
+}
+
+// probeLoopName returns the loop name of a probe
+func probeLoopName(name string) string {
+	return "probe:" + name
+}
+
+// customLoopName returns the loop name of a custom collector
+func customLoopName(name string) string {
+	return "exec:" + name
 }
 
 // systemCollectorLoop runs the system metrics collection loop
-func (a *Agent) systemCollectorLoop() {
-	ticker := time.NewTicker(a.config.GetSystemCollectorInterval())
+func (a *Agent) systemCollectorLoop(ctx context.Context) {
+	interval := a.GetConfig().GetSystemCollectorInterval()
+	a.self.start("system", interval)
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Collect immediately on start
@@ -175,7 +424,7 @@ func (a *Agent) systemCollectorLoop() {
 
 	for {
 		select {
-		case <-a.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			a.collectSystemMetrics()
@@ -184,8 +433,11 @@ func (a *Agent) systemCollectorLoop() {
 }
 
 // servicesCollectorLoop runs the service metrics collection loop
-func (a *Agent) servicesCollectorLoop() {
-	ticker := time.NewTicker(a.config.GetServicesCollectorInterval())
+func (a *Agent) servicesCollectorLoop(ctx context.Context) {
+	interval := a.GetConfig().GetServicesCollectorInterval()
+	a.self.start("services", interval)
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Collect immediately on start
@@ -193,7 +445,7 @@ func (a *Agent) servicesCollectorLoop() {
 
 	for {
 		select {
-		case <-a.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			a.collectServiceMetrics()
@@ -201,28 +453,133 @@ func (a *Agent) servicesCollectorLoop() {
 	}
 }
 
-// httpChecksCollectorLoop runs the HTTP checks collection loop
-func (a *Agent) httpChecksCollectorLoop() {
-	// Start each HTTP check in its own goroutine
-	for _, check := range a.config.Collectors.HTTPChecks.Checks {
-		go a.httpCheckLoop(check)
+// pm2CollectorLoop runs the PM2 process collection loop
+func (a *Agent) pm2CollectorLoop(ctx context.Context) {
+	interval := a.GetConfig().GetPM2CollectorInterval()
+	a.self.start("pm2", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Collect immediately on start
+	a.collectPM2Metrics()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.collectPM2Metrics()
+		}
 	}
 }
 
-// httpCheckLoop runs a single HTTP check loop
-func (a *Agent) httpCheckLoop(check monitor.HTTPCheck) {
-	ticker := time.NewTicker(check.GetInterval())
+// supervisorCollectorLoop runs the supervisor process collection loop
+func (a *Agent) supervisorCollectorLoop(ctx context.Context) {
+	interval := a.GetConfig().GetSupervisorCollectorInterval()
+	a.self.start("supervisor", interval)
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	// Check immediately on start
-	a.performHTTPCheck(check)
+	// Collect immediately on start
+	a.collectSupervisorMetrics()
 
 	for {
 		select {
-		case <-a.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			a.performHTTPCheck(check)
+			a.collectSupervisorMetrics()
+		}
+	}
+}
+
+// journalCollectorLoop follows the systemd journal, restarting journalctl if it exits
+func (a *Agent) journalCollectorLoop(ctx context.Context) {
+	a.mu.RLock()
+	journalCollector := a.journalCollector
+	a.mu.RUnlock()
+
+	// Journal entries arrive when patterns match, so there is no interval to miss
+	a.self.start("journal", 0)
+
+	for {
+		if err := journalCollector.Follow(ctx, a.handleLogEvent); err != nil {
+			a.logger.Error("Journal collector stopped", "error", err)
+			a.self.record("journal", 0, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// customCollectorLoopFunc returns the collection loop of a generic collector
+func (a *Agent) customCollectorLoopFunc(custom customCollector) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		a.self.start(customLoopName(custom.collector.Name()), custom.interval)
+
+		ticker := time.NewTicker(custom.interval)
+		defer ticker.Stop()
+
+		// Collect immediately on start
+		a.collectCustomMetrics(custom.collector)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.collectCustomMetrics(custom.collector)
+			}
+		}
+	}
+}
+
+// httpCheckLoopFunc returns the loop of a single HTTP check
+func (a *Agent) httpCheckLoopFunc(check monitor.HTTPCheck) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		a.self.start(httpCheckLoopName(check.Name), check.GetInterval())
+
+		ticker := time.NewTicker(check.GetInterval())
+		defer ticker.Stop()
+
+		// Check immediately on start
+		a.performHTTPCheck(check)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.performHTTPCheck(check)
+			}
+		}
+	}
+}
+
+// probeLoopFunc returns the loop of a single probe
+func (a *Agent) probeLoopFunc(probe monitor.ProbeCheck) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		a.self.start(probeLoopName(probe.Name), probe.GetInterval())
+
+		ticker := time.NewTicker(probe.GetInterval())
+		defer ticker.Stop()
+
+		// Probe immediately on start
+		a.performProbe(probe)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.performProbe(probe)
+			}
 		}
 	}
 }
@@ -231,7 +588,9 @@ func (a *Agent) httpCheckLoop(check monitor.HTTPCheck) {
 func (a *Agent) collectSystemMetrics() {
 	a.logger.Debug("Collecting system metrics")
 
+	start := time.Now()
 	metrics, err := a.systemCollector.Collect()
+	a.self.record("system", time.Since(start), err)
 	if err != nil {
 		a.logger.Error("Failed to collect system metrics", "error", err)
 		return
@@ -244,19 +603,22 @@ func (a *Agent) collectSystemMetrics() {
 	a.metricsCount++
 	a.mu.Unlock()
 
-	// Store in persistent storage if available
-	if a.storageAdapter != nil {
-		if err := a.storageAdapter.StoreSystemMetrics(metrics); err != nil {
-			a.logger.Error("Failed to store system metrics", "error", err)
-		}
-	}
+	a.storeResults("system metrics", func() error {
+		return a.storageAdapter.StoreSystemMetrics(metrics)
+	})
 }
 
 // collectServiceMetrics collects current service metrics
 func (a *Agent) collectServiceMetrics() {
 	a.logger.Debug("Collecting service metrics")
 
-	services, err := a.servicesCollector.Collect()
+	a.mu.RLock()
+	servicesCollector := a.servicesCollector
+	a.mu.RUnlock()
+
+	start := time.Now()
+	services, err := servicesCollector.Collect()
+	a.self.record("services", time.Since(start), err)
 	if err != nil {
 		a.logger.Error("Failed to collect service metrics", "error", err)
 		return
@@ -268,19 +630,130 @@ func (a *Agent) collectServiceMetrics() {
 	a.lastServicesCollect = &now
 	a.mu.Unlock()
 
-	// Store in persistent storage if available
-	if a.storageAdapter != nil {
-		if err := a.storageAdapter.StoreServiceMetrics(services); err != nil {
-			a.logger.Error("Failed to store service metrics", "error", err)
-		}
+	a.storeResults("service metrics", func() error {
+		return a.storageAdapter.StoreServiceMetrics(services)
+	})
+}
+
+// collectPM2Metrics collects current PM2 application status
+func (a *Agent) collectPM2Metrics() {
+	a.logger.Debug("Collecting PM2 metrics")
+
+	a.mu.RLock()
+	pm2Collector := a.pm2Collector
+	a.mu.RUnlock()
+
+	start := time.Now()
+	apps, err := pm2Collector.Collect()
+	a.self.record("pm2", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to collect PM2 metrics", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.pm2Apps = apps
+	now := time.Now()
+	a.lastPM2Collect = &now
+	a.mu.Unlock()
+
+	a.storeResults("PM2 metrics", func() error {
+		return a.storageAdapter.StorePM2Apps(apps)
+	})
+}
+
+// collectSupervisorMetrics collects current supervisord process state
+func (a *Agent) collectSupervisorMetrics() {
+	a.logger.Debug("Collecting supervisor metrics")
+
+	a.mu.RLock()
+	supervisorCollector := a.supervisorCollector
+	a.mu.RUnlock()
+
+	start := time.Now()
+	processes, err := supervisorCollector.Collect()
+	a.self.record("supervisor", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to collect supervisor metrics", "error", err)
+		return
+	}
+
+	a.mu.Lock()
+	a.supervisorProcs = processes
+	now := time.Now()
+	a.lastSupervisorCollect = &now
+	a.mu.Unlock()
+
+	a.storeResults("supervisor metrics", func() error {
+		return a.storageAdapter.StoreSupervisorProcesses(processes)
+	})
+}
+
+// collectCustomMetrics gathers metrics from a generic collector
+func (a *Agent) collectCustomMetrics(collector collectors.Collector) {
+	name := collector.Name()
+	a.logger.Debug("Collecting custom metrics", "collector", name)
+
+	start := time.Now()
+	metrics, err := collector.Gather()
+	a.self.record(customLoopName(name), time.Since(start), err)
+
+	a.mu.Lock()
+	now := time.Now()
+	a.lastCustomCollect[name] = &now
+	if err != nil {
+		a.customCollectErrors[name] = err.Error()
+	} else {
+		delete(a.customCollectErrors, name)
+		a.collectorMetrics[name] = metrics
+	}
+	a.mu.Unlock()
+
+	if err != nil {
+		a.logger.Error("Failed to collect custom metrics", "collector", name, "error", err)
+		return
+	}
+
+	a.storeResults("custom metrics", func() error {
+		return a.storageAdapter.StoreCollectorMetrics(name, metrics)
+	}, "collector", name)
+}
+
+// handleLogEvent records a matched journal entry
+func (a *Agent) handleLogEvent(event monitor.LogEvent) {
+	a.logger.Debug("Journal pattern matched", "pattern", event.Pattern, "unit", event.Unit)
+
+	a.mu.Lock()
+	a.logEvents = append(a.logEvents, event)
+
+	// Keep the last hour, bounded in size, for alert evaluation and the API
+	cutoff := time.Now().Add(-time.Hour)
+	start := 0
+	for start < len(a.logEvents) && a.logEvents[start].Timestamp.Before(cutoff) {
+		start++
 	}
+	if len(a.logEvents)-start > maxLogEvents {
+		start = len(a.logEvents) - maxLogEvents
+	}
+	a.logEvents = append([]monitor.LogEvent(nil), a.logEvents[start:]...)
+
+	now := time.Now()
+	a.lastJournalEvent = &now
+	a.mu.Unlock()
+	a.self.record("journal", 0, nil)
+
+	a.storeResults("log event", func() error {
+		return a.storageAdapter.StoreLogEvent(event)
+	})
 }
 
 // performHTTPCheck performs a single HTTP health check
 func (a *Agent) performHTTPCheck(check monitor.HTTPCheck) {
 	a.logger.Debug("Performing HTTP check", "name", check.Name, "url", check.URL)
 
+	start := time.Now()
 	result := a.httpCollector.PerformCheck(check)
+	a.self.record(httpCheckLoopName(check.Name), time.Since(start), nil)
 
 	a.mu.Lock()
 	// Update or append result
@@ -299,12 +772,53 @@ func (a *Agent) performHTTPCheck(check monitor.HTTPCheck) {
 	a.lastHTTPChecksCollect = &now
 	a.mu.Unlock()
 
-	// Store in persistent storage if available
-	if a.storageAdapter != nil {
-		if err := a.storageAdapter.StoreHTTPCheckResults([]monitor.HTTPCheckResult{result}); err != nil {
-			a.logger.Error("Failed to store HTTP check results", "error", err)
+	a.storeResults("HTTP check results", func() error {
+		return a.storageAdapter.StoreHTTPCheckResults([]monitor.HTTPCheckResult{result})
+	})
+}
+
+// performProbe performs a single TCP, DNS, ICMP or TLS probe
+func (a *Agent) performProbe(probe monitor.ProbeCheck) {
+	a.logger.Debug("Performing probe", "name", probe.Name, "type", probe.Type, "target", probe.Target)
+
+	start := time.Now()
+	result := a.probeCollector.PerformProbe(probe)
+	a.self.record(probeLoopName(probe.Name), time.Since(start), nil)
+
+	a.mu.Lock()
+	found := false
+	for i, existing := range a.probeResults {
+		if existing.Name == probe.Name {
+			a.probeResults[i] = result
+			found = true
+			break
 		}
 	}
+	if !found {
+		a.probeResults = append(a.probeResults, result)
+	}
+	now := time.Now()
+	a.lastProbesCollect = &now
+	a.mu.Unlock()
+
+	a.storeResults("probe results", func() error {
+		return a.storageAdapter.StoreProbeResults([]monitor.ProbeResult{result})
+	})
+}
+
+// storeResults writes collected data to persistent storage if available,
+// timing the write
+func (a *Agent) storeResults(what string, write func() error, keyvals ...interface{}) {
+	if a.storageAdapter == nil {
+		return
+	}
+
+	start := time.Now()
+	err := write()
+	a.self.recordStorageWrite(time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to store "+what, append(keyvals, "error", err)...)
+	}
 }
 
 // Getter methods for server endpoints
@@ -314,6 +828,13 @@ func (a *Agent) GetUptime() time.Duration {
 	return time.Since(a.startTime)
 }
 
+// GetConfig returns the current configuration
+func (a *Agent) GetConfig() *monitor.Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
 // GetStartTime returns the agent start time
 func (a *Agent) GetStartTime() time.Time {
 	return a.startTime
@@ -355,6 +876,70 @@ func (a *Agent) GetHTTPCheckResults() ([]monitor.HTTPCheckResult, error) {
 	return results, nil
 }
 
+// GetProbeResults returns the latest probe results
+func (a *Agent) GetProbeResults() ([]monitor.ProbeResult, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// Return a copy to avoid data races
+	results := make([]monitor.ProbeResult, len(a.probeResults))
+	copy(results, a.probeResults)
+	return results, nil
+}
+
+// GetPM2Apps returns the latest PM2 application status
+func (a *Agent) GetPM2Apps() ([]monitor.PM2AppStatus, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// Return a copy to avoid data races
+	apps := make([]monitor.PM2AppStatus, len(a.pm2Apps))
+	copy(apps, a.pm2Apps)
+	return apps, nil
+}
+
+// GetSupervisorProcesses returns the latest supervisord process state
+func (a *Agent) GetSupervisorProcesses() ([]monitor.SupervisorProcessStatus, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// Return a copy to avoid data races
+	processes := make([]monitor.SupervisorProcessStatus, len(a.supervisorProcs))
+	copy(processes, a.supervisorProcs)
+	return processes, nil
+}
+
+// GetLogEvents returns journal entries matched within the last hour
+func (a *Agent) GetLogEvents() ([]monitor.LogEvent, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// Return a copy to avoid data races
+	events := make([]monitor.LogEvent, len(a.logEvents))
+	copy(events, a.logEvents)
+	return events, nil
+}
+
+// GetCollectorMetrics returns the latest sample of every custom collector
+func (a *Agent) GetCollectorMetrics() (map[string][]monitor.Metric, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	// Return a copy to avoid data races
+	metrics := make(map[string][]monitor.Metric, len(a.collectorMetrics))
+	for name, sample := range a.collectorMetrics {
+		metrics[name] = append([]monitor.Metric(nil), sample...)
+	}
+	return metrics, nil
+}
+
+// GetCustomCollectorStatus returns the last collection time and error of a custom collector
+func (a *Agent) GetCustomCollectorStatus(name string) (*time.Time, string) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastCustomCollect[name], a.customCollectErrors[name]
+}
+
 // GetMetricsCount returns the total number of metrics collected
 func (a *Agent) GetMetricsCount() int64 {
 	a.mu.RLock()
@@ -383,6 +968,13 @@ func (a *Agent) GetLastServicesCollect() *time.Time {
 	return a.lastServicesCollect
 }
 
+// GetLastProbesCollect returns the timestamp of the last probe
+func (a *Agent) GetLastProbesCollect() *time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastProbesCollect
+}
+
 // GetLastHTTPChecksCollect returns the timestamp of the last HTTP checks collection
 func (a *Agent) GetLastHTTPChecksCollect() *time.Time {
 	a.mu.RLock()
@@ -390,14 +982,35 @@ func (a *Agent) GetLastHTTPChecksCollect() *time.Time {
 	return a.lastHTTPChecksCollect
 }
 
+// GetLastPM2Collect returns the timestamp of the last PM2 collection
+func (a *Agent) GetLastPM2Collect() *time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastPM2Collect
+}
+
+// GetLastSupervisorCollect returns the timestamp of the last supervisor collection
+func (a *Agent) GetLastSupervisorCollect() *time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastSupervisorCollect
+}
+
+// GetLastJournalEvent returns the timestamp of the last matched journal entry
+func (a *Agent) GetLastJournalEvent() *time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.lastJournalEvent
+}
+
 // alertEvaluationLoop runs the alert evaluation loop
-func (a *Agent) alertEvaluationLoop() {
-	ticker := time.NewTicker(30 * time.Second) // Evaluate every 30 seconds
+func (a *Agent) alertEvaluationLoop(ctx context.Context) {
+	ticker := time.NewTicker(alertEvaluationInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-a.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			a.evaluateAlerts()
@@ -407,57 +1020,43 @@ func (a *Agent) alertEvaluationLoop() {
 
 // evaluateAlerts evaluates all alert rules against current metrics
 func (a *Agent) evaluateAlerts() {
-	if a.alertManager == nil {
+	alertManager := a.getAlertManager()
+	if alertManager == nil {
 		return
 	}
 
 	a.logger.Debug("Evaluating alert rules")
+	start := time.Now()
 
 	// Get current metrics
 	a.mu.RLock()
 	systemMetrics := a.systemMetrics
 	serviceMetrics := a.serviceMetrics
 	httpCheckResults := a.httpCheckResults
+	probeResults := a.probeResults
+	pm2Apps := a.pm2Apps
+	supervisorProcs := a.supervisorProcs
+	logEvents := a.logEvents
 	a.mu.RUnlock()
 
-	// Skip evaluation if we don't have enough data yet
-	if systemMetrics == nil {
-		return
-	}
-
 	// Create evaluation context
 	ctx := &alerts.EvaluationContext{
-		SystemMetrics: map[string]alerts.MetricData{
-			"cpu_usage": {
-				Timestamp: time.Now(),
-				Value:     systemMetrics.CPU.UsagePercent,
-				Labels:    map[string]string{"type": "cpu"},
-			},
-			"memory_usage": {
-				Timestamp: time.Now(),
-				Value:     systemMetrics.Memory.UsagePercent,
-				Labels:    map[string]string{"type": "memory"},
-			},
-			"load_1": {
-				Timestamp: time.Now(),
-				Value:     systemMetrics.Load.Load1,
-				Labels:    map[string]string{"type": "load"},
-			},
-		},
+		SystemMetrics: make(map[string]alerts.MetricData),
 		ServiceStates: make(map[string]string),
 		HTTPResults:   make(map[string]alerts.HTTPCheckResult),
+		Probes:        make(map[string]alerts.ProbeState),
+		PM2Apps:       make(map[string]alerts.PM2AppState),
+		Supervisor:    make(map[string]alerts.SupervisorProcessState),
+		Collectors:    a.self.collectorStates(),
+		Anomalies:     a.anomalyStates(),
+		Forecasts:     a.forecastStates(),
+		SLOs:          a.sloStates(),
 		CurrentTime:   time.Now(),
 	}
 
-	// Add disk usage metrics
-	for _, disk := range systemMetrics.Disk {
-		if disk.MountPoint == "/" {
-			ctx.SystemMetrics["disk_usage_root"] = alerts.MetricData{
-				Timestamp: time.Now(),
-				Value:     disk.UsagePercent,
-				Labels:    map[string]string{"type": "disk", "mount": "/"},
-			}
-		}
+	// Add system metrics once collected, collector rules still apply before that
+	if systemMetrics != nil {
+		addSystemMetrics(ctx, systemMetrics)
 	}
 
 	// Add service states
@@ -478,54 +1077,190 @@ func (a *Agent) evaluateAlerts() {
 			Success:      check.Success,
 			Error:        check.Error,
 			Timestamp:    check.Timestamp,
+			FailedStep:   check.FailedStep,
+		}
+	}
+
+	// Add probe results
+	for _, probe := range probeResults {
+		ctx.Probes[probe.Name] = alerts.ProbeState{
+			Type:         probe.Type,
+			Target:       probe.Target,
+			Success:      probe.Success,
+			ResponseTime: probe.ResponseTime,
+			Error:        probe.Error,
+			PacketLoss:   probe.PacketLoss,
+			CertExpiry:   probe.CertExpiry,
+			Timestamp:    probe.Timestamp,
 		}
 	}
 
+	// Add PM2 application states
+	for _, app := range pm2Apps {
+		ctx.PM2Apps[app.Name] = alerts.PM2AppState{
+			Status:          app.Status,
+			Instances:       app.Instances,
+			OnlineInstances: app.OnlineInstances,
+			Restarts:        app.Restarts,
+			RecentRestarts:  app.RecentRestarts,
+			Timestamp:       app.Timestamp,
+		}
+	}
+
+	// Add supervisor process states
+	for _, proc := range supervisorProcs {
+		ctx.Supervisor[proc.FullName] = alerts.SupervisorProcessState{
+			Group:      proc.Group,
+			State:      proc.State,
+			ExitStatus: proc.ExitStatus,
+			SpawnError: proc.SpawnError,
+			Restarts:   proc.Restarts,
+			Site:       proc.Site,
+			Timestamp:  proc.Timestamp,
+		}
+	}
+
+	// Add matched journal entries
+	for _, event := range logEvents {
+		ctx.LogEvents = append(ctx.LogEvents, alerts.LogEventState{
+			Unit:      event.Unit,
+			Pattern:   event.Pattern,
+			Severity:  event.Severity,
+			Message:   event.Message,
+			Timestamp: event.Timestamp,
+		})
+	}
+
 	// Evaluate rules
-	err := a.alertManager.EvaluateRules(ctx)
+	err := alertManager.EvaluateRules(ctx)
+	a.self.recordAlertEvaluation(time.Since(start), err)
 	if err != nil {
 		a.logger.Error("Failed to evaluate alert rules", "error", err)
 	}
 
 	// Update active alerts count
 	a.mu.Lock()
-	activeAlerts := a.alertManager.GetActiveAlerts()
+	activeAlerts := alertManager.GetActiveAlerts()
 	a.activeAlertsCount = len(activeAlerts)
 	a.mu.Unlock()
+
+	a.trackIncidents(activeAlerts, ctx.CurrentTime)
+}
+
+// addSystemMetrics adds system, disk I/O and pressure metrics to the evaluation context
+func addSystemMetrics(ctx *alerts.EvaluationContext, systemMetrics *monitor.SystemMetrics) {
+	ctx.SystemMetrics["cpu_usage"] = alerts.MetricData{
+		Timestamp: time.Now(),
+		Value:     systemMetrics.CPU.UsagePercent,
+		Labels:    map[string]string{"type": "cpu"},
+	}
+	ctx.SystemMetrics["memory_usage"] = alerts.MetricData{
+		Timestamp: time.Now(),
+		Value:     systemMetrics.Memory.UsagePercent,
+		Labels:    map[string]string{"type": "memory"},
+	}
+	ctx.SystemMetrics["load_1"] = alerts.MetricData{
+		Timestamp: time.Now(),
+		Value:     systemMetrics.Load.Load1,
+		Labels:    map[string]string{"type": "load"},
+	}
+
+	// Add disk usage metrics
+	for _, disk := range systemMetrics.Disk {
+		if disk.MountPoint == "/" {
+			ctx.SystemMetrics["disk_usage_root"] = alerts.MetricData{
+				Timestamp: time.Now(),
+				Value:     disk.UsagePercent,
+				Labels:    map[string]string{"type": "disk", "mount": "/"},
+			}
+		}
+	}
+
+	// Add disk I/O metrics per device, plus the busiest device overall
+	for _, io := range systemMetrics.DiskIO {
+		labels := map[string]string{"type": "disk_io", "device": io.Device}
+		ctx.SystemMetrics["io_util:"+io.Device] = alerts.MetricData{
+			Timestamp: time.Now(),
+			Value:     io.UtilPercent,
+			Labels:    labels,
+		}
+		ctx.SystemMetrics["io_await:"+io.Device] = alerts.MetricData{
+			Timestamp: time.Now(),
+			Value:     io.AwaitMs,
+			Labels:    labels,
+		}
+
+		if current, exists := ctx.SystemMetrics["io_util"]; !exists || io.UtilPercent > current.Value {
+			ctx.SystemMetrics["io_util"] = ctx.SystemMetrics["io_util:"+io.Device]
+		}
+		if current, exists := ctx.SystemMetrics["io_await"]; !exists || io.AwaitMs > current.Value {
+			ctx.SystemMetrics["io_await"] = ctx.SystemMetrics["io_await:"+io.Device]
+		}
+	}
+
+	// Add pressure stall information
+	if systemMetrics.Pressure != nil {
+		ctx.SystemMetrics["cpu_pressure"] = alerts.MetricData{
+			Timestamp: time.Now(),
+			Value:     systemMetrics.Pressure.CPU.SomeAvg10,
+			Labels:    map[string]string{"type": "pressure", "resource": "cpu"},
+		}
+		ctx.SystemMetrics["memory_pressure"] = alerts.MetricData{
+			Timestamp: time.Now(),
+			Value:     systemMetrics.Pressure.Memory.SomeAvg10,
+			Labels:    map[string]string{"type": "pressure", "resource": "memory"},
+		}
+		ctx.SystemMetrics["io_pressure"] = alerts.MetricData{
+			Timestamp: time.Now(),
+			Value:     systemMetrics.Pressure.IO.SomeAvg10,
+			Labels:    map[string]string{"type": "pressure", "resource": "io"},
+		}
+	}
 }
 
 // Alert management methods for API endpoints
 
+// getAlertManager returns the alert manager, which a reload may create later
+func (a *Agent) getAlertManager() *alerts.AlertManager {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.alertManager
+}
+
 // GetActiveAlerts returns all active alerts
 func (a *Agent) GetActiveAlerts() ([]*alerts.Alert, error) {
-	if a.alertManager == nil {
+	alertManager := a.getAlertManager()
+	if alertManager == nil {
 		return []*alerts.Alert{}, nil
 	}
-	return a.alertManager.GetActiveAlerts(), nil
+	return alertManager.GetActiveAlerts(), nil
 }
 
 // GetAlert returns a specific alert by ID
 func (a *Agent) GetAlert(alertID string) (*alerts.Alert, error) {
-	if a.alertManager == nil {
+	alertManager := a.getAlertManager()
+	if alertManager == nil {
 		return nil, fmt.Errorf("alert manager not initialized")
 	}
-	return a.alertManager.GetAlert(alertID)
+	return alertManager.GetAlert(alertID)
 }
 
 // AcknowledgeAlert acknowledges an alert
 func (a *Agent) AcknowledgeAlert(alertID string) error {
-	if a.alertManager == nil {
+	alertManager := a.getAlertManager()
+	if alertManager == nil {
 		return fmt.Errorf("alert manager not initialized")
 	}
-	return a.alertManager.AcknowledgeAlert(alertID)
+	return alertManager.AcknowledgeAlert(alertID)
 }
 
 // ResolveAlert manually resolves an alert
 func (a *Agent) ResolveAlert(alertID string) error {
-	if a.alertManager == nil {
+	alertManager := a.getAlertManager()
+	if alertManager == nil {
 		return fmt.Errorf("alert manager not initialized")
 	}
-	return a.alertManager.ResolveAlert(alertID)
+	return alertManager.ResolveAlert(alertID)
 }
 
 // GetStorageAdapter returns the storage adapter for accessing historical data
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"crucible/internal/monitor/ai"
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/storage"
+)
+
+// anomalyInterval is how often stored metrics are scored for anomalies
+const anomalyInterval = time.Minute
+
+// anomalyLoop learns seasonal baselines from stored metrics and scores recent values against them
+func (a *Agent) anomalyLoop(ctx context.Context) {
+	config := a.GetConfig()
+	detector := ai.NewAnomalyDetector(a.storageAdapter, ai.AnomalySettings{
+		Sensitivity:    config.AI.AnomalyDetection.Sensitivity,
+		LearningPeriod: config.GetAnomalyLearningPeriod(),
+	})
+
+	a.mu.Lock()
+	a.anomalyDetector = detector
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		if a.anomalyDetector == detector {
+			a.anomalyDetector = nil
+		}
+		a.mu.Unlock()
+	}()
+
+	a.self.start("anomaly", anomalyInterval)
+
+	ticker := time.NewTicker(anomalyInterval)
+	defer ticker.Stop()
+
+	// Learn and score immediately on start
+	a.detectAnomalies(detector)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.detectAnomalies(detector)
+		}
+	}
+}
+
+// detectAnomalies scores the watched metrics and records anomalies that started or ended
+func (a *Agent) detectAnomalies(detector *ai.AnomalyDetector) {
+	a.logger.Debug("Detecting anomalies")
+
+	start := time.Now()
+	transitions, err := detector.Evaluate(a.anomalyMetrics(), start)
+	a.self.record("anomaly", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to detect anomalies", "error", err)
+	}
+
+	for _, transition := range transitions {
+		score := transition.Score
+
+		var event *storage.Event
+		if transition.Started {
+			a.logger.Warn("Anomaly detected", "series", score.Name(), "value", score.Value,
+				"expected", score.Expected, "score", score.Score)
+			event = storage.NewEvent(score.EntityID, storage.EventTypeAnomaly,
+				fmt.Sprintf("%s is %.2f, unusual for %s (expected about %.2f)", score.Name(), score.Value, score.Bucket, score.Expected))
+			event.Severity = storage.SeverityWarning
+		} else {
+			a.logger.Info("Anomaly ended", "series", score.Name(), "duration", transition.Duration)
+			event = storage.NewEvent(score.EntityID, storage.EventTypeAnomaly,
+				fmt.Sprintf("%s is back to normal after %s", score.Name(), transition.Duration.Round(time.Minute)))
+		}
+
+		state := "ended"
+		if transition.Started {
+			state = "started"
+		}
+		event.Details = storage.JSON{
+			"state":     state,
+			"metric":    score.Metric,
+			"value":     score.Value,
+			"expected":  score.Expected,
+			"spread":    score.Spread,
+			"score":     score.Score,
+			"threshold": score.Threshold,
+			"bucket":    score.Bucket,
+			"seasonal":  score.Seasonal,
+		}
+		if !transition.Started {
+			event.Details["duration_seconds"] = int64(transition.Duration.Seconds())
+		}
+
+		a.storeResults("anomaly event", func() error {
+			return a.storageAdapter.StoreEvent(event)
+		})
+	}
+}
+
+// anomalyMetrics returns the configured metrics plus those named by anomaly alert rules
+func (a *Agent) anomalyMetrics() []string {
+	metrics := append([]string(nil), a.GetConfig().AI.AnomalyDetection.Metrics...)
+	if alertManager := a.getAlertManager(); alertManager != nil {
+		for _, rule := range alertManager.GetRules() {
+			if rule.Enabled && rule.Type == alerts.AlertTypeAnomaly && rule.Conditions.AnomalyMetric != "" {
+				metrics = append(metrics, rule.Conditions.AnomalyMetric)
+			}
+		}
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// GetAnomalyScores returns the latest anomaly score of every learned series,
+// nil when anomaly detection is off
+func (a *Agent) GetAnomalyScores() []ai.Score {
+	a.mu.RLock()
+	detector := a.anomalyDetector
+	a.mu.RUnlock()
+
+	if detector == nil {
+		return nil
+	}
+	return detector.Scores()
+}
+
+// anomalyStates converts the latest anomaly scores for alert evaluation
+func (a *Agent) anomalyStates() []alerts.AnomalyState {
+	scores := a.GetAnomalyScores()
+	states := make([]alerts.AnomalyState, 0, len(scores))
+	for _, score := range scores {
+		states = append(states, alerts.AnomalyState{
+			Metric:     score.Metric,
+			EntityType: score.EntityType,
+			Entity:     score.Entity,
+			Value:      score.Value,
+			Expected:   score.Expected,
+			Score:      score.Score,
+			Threshold:  score.Threshold,
+			Bucket:     score.Bucket,
+			Since:      score.Since,
+		})
+	}
+	return states
+}
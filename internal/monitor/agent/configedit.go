@@ -0,0 +1,359 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/collectors"
+	"crucible/internal/monitor/storage"
+)
+
+var (
+	// ErrConfigItemNotFound is returned when an edited check, service or rule does not exist
+	ErrConfigItemNotFound = errors.New("configuration item not found")
+	// ErrConfigItemExists is returned when an added check, service or rule already exists
+	ErrConfigItemExists = errors.New("configuration item already exists")
+	// ErrConfigHistoryUnavailable is returned when version history needs storage that is disabled
+	ErrConfigHistoryUnavailable = errors.New("configuration history requires storage to be enabled")
+)
+
+// YAML locations of the lists edited through the API
+var (
+	httpChecksPath = []string{"collectors", "http_checks", "checks"}
+	servicesPath   = []string{"collectors", "services", "services"}
+	alertRulesPath = []string{"rules"}
+)
+
+// ConfigUpdate describes the outcome of a configuration write
+type ConfigUpdate struct {
+	Version *storage.ConfigVersion `json:"version,omitempty"`
+	Reload  *ReloadResult          `json:"reload"`
+}
+
+// fileEdit rewrites the contents of a configuration file
+type fileEdit func(data []byte) ([]byte, error)
+
+// AddHTTPCheck adds an HTTP check to the configuration file
+func (a *Agent) AddHTTPCheck(check monitor.HTTPCheck) (*ConfigUpdate, error) {
+	if err := collectors.NewHTTPCollector().ValidateCheck(check); err != nil {
+		return nil, err
+	}
+
+	return a.writeConfig(fmt.Sprintf("add http check %s", check.Name), func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, httpChecksPath, func(list *monitor.YAMLList) error {
+			if list.Find("name", check.Name) >= 0 {
+				return fmt.Errorf("http check %s: %w", check.Name, ErrConfigItemExists)
+			}
+			return list.Append(check)
+		})
+	}, nil)
+}
+
+// UpdateHTTPCheck replaces the HTTP check with the given name
+func (a *Agent) UpdateHTTPCheck(name string, check monitor.HTTPCheck) (*ConfigUpdate, error) {
+	if check.Name == "" {
+		check.Name = name
+	}
+	if err := collectors.NewHTTPCollector().ValidateCheck(check); err != nil {
+		return nil, err
+	}
+
+	return a.writeConfig(fmt.Sprintf("update http check %s", name), func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, httpChecksPath, func(list *monitor.YAMLList) error {
+			index := list.Find("name", name)
+			if index < 0 {
+				return fmt.Errorf("http check %s: %w", name, ErrConfigItemNotFound)
+			}
+			if check.Name != name && list.Find("name", check.Name) >= 0 {
+				return fmt.Errorf("http check %s: %w", check.Name, ErrConfigItemExists)
+			}
+			return list.Replace(index, check)
+		})
+	}, nil)
+}
+
+// DeleteHTTPCheck removes the HTTP check with the given name
+func (a *Agent) DeleteHTTPCheck(name string) (*ConfigUpdate, error) {
+	return a.writeConfig(fmt.Sprintf("delete http check %s", name), func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, httpChecksPath, func(list *monitor.YAMLList) error {
+			return removeListItem(list, "name", name, "http check")
+		})
+	}, nil)
+}
+
+// AddService adds a systemd service to the monitored services. Note that an
+// empty service list monitors every service, so adding the first entry
+// narrows monitoring down to it.
+func (a *Agent) AddService(name string) (*ConfigUpdate, error) {
+	if name == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+
+	return a.writeConfig(fmt.Sprintf("add service %s", name), func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, servicesPath, func(list *monitor.YAMLList) error {
+			if list.Find("", name) >= 0 {
+				return fmt.Errorf("service %s: %w", name, ErrConfigItemExists)
+			}
+			return list.Append(name)
+		})
+	}, nil)
+}
+
+// DeleteService removes a systemd service from the monitored services
+func (a *Agent) DeleteService(name string) (*ConfigUpdate, error) {
+	return a.writeConfig(fmt.Sprintf("delete service %s", name), func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, servicesPath, func(list *monitor.YAMLList) error {
+			return removeListItem(list, "", name, "service")
+		})
+	}, nil)
+}
+
+// AddAlertRule adds an alert rule to the alert rules file
+func (a *Agent) AddAlertRule(rule alerts.AlertRuleConfig) (*ConfigUpdate, error) {
+	if _, err := alerts.ValidateRule(&rule); err != nil {
+		return nil, err
+	}
+
+	return a.writeConfig(fmt.Sprintf("add alert rule %s", rule.ID), nil, func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, alertRulesPath, func(list *monitor.YAMLList) error {
+			if list.Find("id", rule.ID) >= 0 {
+				return fmt.Errorf("alert rule %s: %w", rule.ID, ErrConfigItemExists)
+			}
+			return list.Append(rule)
+		})
+	})
+}
+
+// UpdateAlertRule replaces the alert rule with the given ID
+func (a *Agent) UpdateAlertRule(id string, rule alerts.AlertRuleConfig) (*ConfigUpdate, error) {
+	if rule.ID == "" {
+		rule.ID = id
+	}
+	if _, err := alerts.ValidateRule(&rule); err != nil {
+		return nil, err
+	}
+
+	return a.writeConfig(fmt.Sprintf("update alert rule %s", id), nil, func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, alertRulesPath, func(list *monitor.YAMLList) error {
+			index := list.Find("id", id)
+			if index < 0 {
+				return fmt.Errorf("alert rule %s: %w", id, ErrConfigItemNotFound)
+			}
+			if rule.ID != id && list.Find("id", rule.ID) >= 0 {
+				return fmt.Errorf("alert rule %s: %w", rule.ID, ErrConfigItemExists)
+			}
+			return list.Replace(index, rule)
+		})
+	})
+}
+
+// DeleteAlertRule removes the alert rule with the given ID
+func (a *Agent) DeleteAlertRule(id string) (*ConfigUpdate, error) {
+	return a.writeConfig(fmt.Sprintf("delete alert rule %s", id), nil, func(data []byte) ([]byte, error) {
+		return monitor.EditYAMLList(data, alertRulesPath, func(list *monitor.YAMLList) error {
+			return removeListItem(list, "id", id, "alert rule")
+		})
+	})
+}
+
+// ListConfigVersions returns the most recent configuration versions
+func (a *Agent) ListConfigVersions(limit int) ([]*storage.ConfigVersion, error) {
+	if a.storageAdapter == nil {
+		return nil, ErrConfigHistoryUnavailable
+	}
+	return a.storageAdapter.ListConfigVersions(limit)
+}
+
+// GetConfigVersion returns a configuration version including the file contents
+func (a *Agent) GetConfigVersion(id int64) (*storage.ConfigVersion, error) {
+	if a.storageAdapter == nil {
+		return nil, ErrConfigHistoryUnavailable
+	}
+	return a.storageAdapter.GetConfigVersion(id)
+}
+
+// RollbackConfig restores the configuration files of a previous version and
+// records the result as a new version
+func (a *Agent) RollbackConfig(id int64) (*ConfigUpdate, error) {
+	version, err := a.GetConfigVersion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := func(contents string) fileEdit {
+		return func([]byte) ([]byte, error) {
+			return []byte(contents), nil
+		}
+	}
+
+	return a.writeConfig(fmt.Sprintf("rollback to version %d", id),
+		restore(version.MonitorConfig), restore(version.AlertsConfig))
+}
+
+// writeConfig applies edits to the configuration files, validates the result,
+// writes it atomically, records a version and reloads the agent. The previous
+// files are restored if the reload fails.
+func (a *Agent) writeConfig(description string, editMonitor, editAlerts fileEdit) (*ConfigUpdate, error) {
+	a.configWriteMu.Lock()
+	defer a.configWriteMu.Unlock()
+
+	config := a.GetConfig()
+	monitorPath := config.Path()
+	alertsPath := config.Alerts.RulesFile
+
+	oldMonitor, err := os.ReadFile(monitorPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	oldAlerts, err := os.ReadFile(alertsPath)
+	if err != nil && !(os.IsNotExist(err) && editAlerts == nil) {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+
+	newMonitor, newAlerts := oldMonitor, oldAlerts
+	if editMonitor != nil {
+		if newMonitor, err = editMonitor(oldMonitor); err != nil {
+			return nil, err
+		}
+	}
+	if editAlerts != nil {
+		if newAlerts, err = editAlerts(oldAlerts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate staged copies next to the originals so the final rename is atomic
+	var staged []stagedFile
+	defer func() {
+		for _, file := range staged {
+			os.Remove(file.tempPath)
+		}
+	}()
+
+	if editMonitor != nil {
+		file, err := stageFile(monitorPath, newMonitor)
+		if err != nil {
+			return nil, err
+		}
+		staged = append(staged, file)
+		if _, err := monitor.LoadConfig(file.tempPath); err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+	if editAlerts != nil {
+		file, err := stageFile(alertsPath, newAlerts)
+		if err != nil {
+			return nil, err
+		}
+		staged = append(staged, file)
+		if _, err := alerts.LoadConfig(file.tempPath); err != nil {
+			return nil, fmt.Errorf("invalid alert config: %w", err)
+		}
+		if _, err := alerts.LoadRules(file.tempPath); err != nil {
+			return nil, fmt.Errorf("invalid alert rules: %w", err)
+		}
+	}
+
+	// Keep the starting point so the first change can be rolled back
+	if a.storageAdapter != nil {
+		versions, err := a.storageAdapter.ListConfigVersions(1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration history: %w", err)
+		}
+		if len(versions) == 0 {
+			if _, err := a.storageAdapter.SaveConfigVersion("initial configuration", string(oldMonitor), string(oldAlerts)); err != nil {
+				return nil, fmt.Errorf("failed to record configuration version: %w", err)
+			}
+		}
+	}
+
+	for _, file := range staged {
+		if err := os.Rename(file.tempPath, file.path); err != nil {
+			a.restoreConfigFiles(monitorPath, oldMonitor, alertsPath, oldAlerts)
+			return nil, fmt.Errorf("failed to write %s: %w", file.path, err)
+		}
+	}
+
+	reload, err := a.Reload()
+	if err != nil {
+		a.restoreConfigFiles(monitorPath, oldMonitor, alertsPath, oldAlerts)
+		return nil, fmt.Errorf("failed to apply configuration: %w", err)
+	}
+
+	update := &ConfigUpdate{Reload: reload}
+	if a.storageAdapter != nil {
+		version, err := a.storageAdapter.SaveConfigVersion(description, string(newMonitor), string(newAlerts))
+		if err != nil {
+			a.logger.Error("Failed to record configuration version", "error", err)
+		} else {
+			// The contents are available through the version endpoint
+			update.Version = &storage.ConfigVersion{ID: version.ID, CreatedAt: version.CreatedAt, Description: version.Description}
+		}
+	}
+
+	a.logger.Info("Configuration updated", "change", description)
+
+	return update, nil
+}
+
+// restoreConfigFiles writes back the configuration files as they were before a failed write
+func (a *Agent) restoreConfigFiles(monitorPath string, monitorData []byte, alertsPath string, alertsData []byte) {
+	if err := os.WriteFile(monitorPath, monitorData, 0644); err != nil {
+		a.logger.Error("Failed to restore configuration", "path", monitorPath, "error", err)
+	}
+	if alertsData != nil {
+		if err := os.WriteFile(alertsPath, alertsData, 0644); err != nil {
+			a.logger.Error("Failed to restore alert rules", "path", alertsPath, "error", err)
+		}
+	}
+}
+
+// stagedFile is a validated replacement for a configuration file
+type stagedFile struct {
+	path     string
+	tempPath string
+}
+
+// stageFile writes data to a temporary file in the same directory as path,
+// keeping the permissions of the existing file
+func stageFile(path string, data []byte) (stagedFile, error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return stagedFile{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	file := stagedFile{path: path, tempPath: temp.Name()}
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(file.tempPath)
+		return stagedFile{}, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(file.tempPath)
+		return stagedFile{}, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := os.Chmod(file.tempPath, mode); err != nil {
+		os.Remove(file.tempPath)
+		return stagedFile{}, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	return file, nil
+}
+
+// removeListItem removes the list entry identified by key and value
+func removeListItem(list *monitor.YAMLList, key, value, kind string) error {
+	index := list.Find(key, value)
+	if index < 0 {
+		return fmt.Errorf("%s %s: %w", kind, value, ErrConfigItemNotFound)
+	}
+	return list.Remove(index)
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"crucible/internal/monitor/ai"
+	"crucible/internal/monitor/alerts"
+)
+
+// forecastInterval is how often capacity trends are refitted
+const forecastInterval = 5 * time.Minute
+
+// forecastLoop fits trends to stored disk, inode and database history to predict when they fill up
+func (a *Agent) forecastLoop(ctx context.Context) {
+	forecaster := ai.NewForecaster(a.storageAdapter)
+
+	a.mu.Lock()
+	a.forecaster = forecaster
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		if a.forecaster == forecaster {
+			a.forecaster = nil
+		}
+		a.mu.Unlock()
+	}()
+
+	a.self.start("forecast", forecastInterval)
+
+	ticker := time.NewTicker(forecastInterval)
+	defer ticker.Stop()
+
+	// Forecast immediately on start
+	a.forecastCapacity(forecaster)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.forecastCapacity(forecaster)
+		}
+	}
+}
+
+// forecastCapacity refits the capacity trends
+func (a *Agent) forecastCapacity(forecaster *ai.Forecaster) {
+	a.logger.Debug("Forecasting capacity")
+
+	start := time.Now()
+	err := forecaster.Evaluate(a.forecastTargets(), start)
+	a.self.record("forecast", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to forecast capacity", "error", err)
+	}
+}
+
+// forecastTargets returns the stored metrics that fill up towards a capacity
+func (a *Agent) forecastTargets() []ai.ForecastTarget {
+	targets := []ai.ForecastTarget{
+		{Resource: "disk", Metric: "disk_usage_root", Unit: "%", Capacity: 100},
+		{Resource: "disk", Metric: "disk_usage", Unit: "%", Capacity: 100},
+		{Resource: "inodes", Metric: "inode_usage", Unit: "%", Capacity: 100},
+	}
+
+	if capacity := a.databaseCapacity(); capacity > 0 {
+		targets = append(targets, ai.ForecastTarget{
+			Resource: "database",
+			Metric:   databaseSizeMetric,
+			Name:     a.GetConfig().Storage.SQLite.Path,
+			Unit:     "bytes",
+			Capacity: capacity,
+		})
+	}
+
+	return targets
+}
+
+// databaseCapacity returns the size the monitor database can grow to: the
+// configured size cap, otherwise its current size plus the free space of its
+// filesystem. Zero when the database is not stored in a file.
+func (a *Agent) databaseCapacity() float64 {
+	config := a.GetConfig()
+	if config.Storage.Type != "sqlite" {
+		return 0
+	}
+	if maxSize := config.Storage.SQLite.RetentionPolicy.Global.MaxDatabaseSize; maxSize > 0 {
+		return float64(maxSize)
+	}
+
+	size, err := a.storageAdapter.DatabaseSize()
+	if err != nil || size == 0 {
+		return 0
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(filepath.Dir(config.Storage.SQLite.Path), &stat); err != nil {
+		return 0
+	}
+	return float64(size) + float64(stat.Bavail*uint64(stat.Bsize))
+}
+
+// GetForecasts returns the latest capacity forecasts, soonest full first,
+// nil when there is no storage to forecast from
+func (a *Agent) GetForecasts() []ai.Forecast {
+	a.mu.RLock()
+	forecaster := a.forecaster
+	a.mu.RUnlock()
+
+	if forecaster == nil {
+		return nil
+	}
+	return forecaster.Forecasts()
+}
+
+// forecastStates converts the latest capacity forecasts for alert evaluation
+func (a *Agent) forecastStates() []alerts.ForecastState {
+	forecasts := a.GetForecasts()
+	states := make([]alerts.ForecastState, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		states = append(states, alerts.ForecastState{
+			Resource:     forecast.Resource,
+			Target:       forecast.Target,
+			Current:      forecast.Current,
+			Capacity:     forecast.Capacity,
+			Unit:         forecast.Unit,
+			GrowthPerDay: forecast.GrowthPerDay,
+			FullAt:       forecast.FullAt,
+		})
+	}
+	return states
+}
@@ -0,0 +1,253 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/storage"
+)
+
+// incidentTracker opens an incident when an alert fires and resolves it with the alert
+type incidentTracker struct {
+	mu     sync.Mutex
+	open   map[string]*storage.Incident // By rule ID
+	loaded bool
+}
+
+// newIncidentTracker creates an incident tracker
+func newIncidentTracker() *incidentTracker {
+	return &incidentTracker{open: make(map[string]*storage.Incident)}
+}
+
+// IncidentTimeline is an incident with the events recorded around it, oldest first
+type IncidentTimeline struct {
+	Incident *storage.Incident `json:"incident"`
+	Since    time.Time         `json:"since"`
+	Until    time.Time         `json:"until"`
+	Events   []*storage.Event  `json:"events"`
+}
+
+// trackIncidents compares the active alerts after an evaluation pass with the
+// open incidents, opening incidents for new alerts and resolving those whose
+// alert is gone. Incidents left open by a previous run are picked up first.
+func (a *Agent) trackIncidents(activeAlerts []*alerts.Alert, now time.Time) {
+	if a.storageAdapter == nil {
+		return
+	}
+
+	tracker := a.incidents
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if !tracker.loaded {
+		status := storage.IncidentStatusOpen
+		open, err := a.storageAdapter.ListIncidents(&storage.IncidentFilter{Status: &status})
+		if err != nil {
+			a.logger.Error("Failed to load open incidents", "error", err)
+			return
+		}
+		for _, incident := range open {
+			tracker.open[incident.RuleID] = incident
+		}
+		tracker.loaded = true
+	}
+
+	firing := make(map[string]bool, len(activeAlerts))
+	for _, alert := range activeAlerts {
+		firing[alert.RuleID] = true
+
+		incident, exists := tracker.open[alert.RuleID]
+		if !exists {
+			a.openIncident(tracker, alert)
+			continue
+		}
+
+		// Keep the latest message and details of a still firing alert
+		if incident.Message == alert.Message && incident.Severity == string(alert.Severity) {
+			continue
+		}
+		incident.Severity = string(alert.Severity)
+		incident.Message = alert.Message
+		incident.Details = incidentDetails(alert)
+		a.storeResults("incident", func() error {
+			return a.storageAdapter.UpdateIncident(incident)
+		}, "incident", incident.ID)
+	}
+
+	for ruleID, incident := range tracker.open {
+		if !firing[ruleID] {
+			a.resolveIncident(tracker, incident, now)
+		}
+	}
+}
+
+// openIncident records a fired alert as an event and opens an incident for it
+func (a *Agent) openIncident(tracker *incidentTracker, alert *alerts.Alert) {
+	incident := &storage.Incident{
+		RuleID:   alert.RuleID,
+		Title:    alert.Name,
+		Severity: string(alert.Severity),
+		Status:   storage.IncidentStatusOpen,
+		Message:  alert.Message,
+		Details:  incidentDetails(alert),
+		OpenedAt: alert.StartsAt,
+	}
+
+	var created bool
+	a.storeResults("incident", func() error {
+		if err := a.storageAdapter.CreateIncident(incident); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	}, "rule", alert.RuleID)
+	if !created {
+		return
+	}
+	tracker.open[alert.RuleID] = incident
+
+	a.logger.Info("Incident opened", "incident", incident.ID, "rule", alert.RuleID)
+
+	event := storage.NewEvent(nil, storage.EventTypeAlert, fmt.Sprintf("Alert fired: %s - %s", alert.Name, alert.Message))
+	event.Timestamp = alert.StartsAt
+	event.Severity = alertEventSeverity(alert.Severity)
+	event.Details = storage.JSON{
+		"state":       "firing",
+		"rule_id":     alert.RuleID,
+		"alert_type":  string(alert.Type),
+		"incident_id": incident.ID,
+	}
+	a.storeResults("alert event", func() error {
+		return a.storageAdapter.StoreEvent(event)
+	})
+}
+
+// resolveIncident records a resolved alert as an event and closes its incident
+func (a *Agent) resolveIncident(tracker *incidentTracker, incident *storage.Incident, now time.Time) {
+	resolvedAt := now
+	incident.Status = storage.IncidentStatusResolved
+	incident.ResolvedAt = &resolvedAt
+
+	var updated bool
+	a.storeResults("incident", func() error {
+		if err := a.storageAdapter.UpdateIncident(incident); err != nil {
+			return err
+		}
+		updated = true
+		return nil
+	}, "incident", incident.ID)
+	if !updated {
+		// Retried on the next evaluation pass
+		incident.Status = storage.IncidentStatusOpen
+		incident.ResolvedAt = nil
+		return
+	}
+	delete(tracker.open, incident.RuleID)
+
+	duration := resolvedAt.Sub(incident.OpenedAt)
+	a.logger.Info("Incident resolved", "incident", incident.ID, "rule", incident.RuleID, "duration", duration)
+
+	event := storage.NewEvent(nil, storage.EventTypeAlert,
+		fmt.Sprintf("Alert resolved: %s after %s", incident.Title, duration.Round(time.Second)))
+	event.Timestamp = resolvedAt
+	event.Details = storage.JSON{
+		"state":            "resolved",
+		"rule_id":          incident.RuleID,
+		"incident_id":      incident.ID,
+		"duration_seconds": int64(duration.Seconds()),
+	}
+	a.storeResults("alert event", func() error {
+		return a.storageAdapter.StoreEvent(event)
+	})
+}
+
+// incidentDetails returns the alert details and labels stored with an incident
+func incidentDetails(alert *alerts.Alert) storage.JSON {
+	details := storage.JSON{"alert_type": string(alert.Type)}
+	for key, value := range alert.Details {
+		details[key] = value
+	}
+	if len(alert.Labels) > 0 {
+		details["labels"] = alert.Labels
+	}
+	return details
+}
+
+// alertEventSeverity maps an alert severity to an event severity
+func alertEventSeverity(severity alerts.AlertSeverity) string {
+	switch severity {
+	case alerts.SeverityCritical:
+		return storage.SeverityCritical
+	case alerts.SeverityWarning:
+		return storage.SeverityWarning
+	default:
+		return storage.SeverityInfo
+	}
+}
+
+// GetIncidents returns stored incidents, most recently opened first
+func (a *Agent) GetIncidents(filter *storage.IncidentFilter) ([]*storage.Incident, error) {
+	if a.storageAdapter == nil {
+		return nil, fmt.Errorf("storage not configured")
+	}
+	return a.storageAdapter.ListIncidents(filter)
+}
+
+// GetIncidentTimeline returns an incident with the events recorded from the
+// configured lookback before it opened until it resolved
+func (a *Agent) GetIncidentTimeline(id int64) (*IncidentTimeline, error) {
+	if a.storageAdapter == nil {
+		return nil, fmt.Errorf("storage not configured")
+	}
+
+	incident, err := a.storageAdapter.GetIncident(id)
+	if err != nil {
+		return nil, err
+	}
+
+	config := a.GetConfig()
+	timeline := &IncidentTimeline{
+		Incident: incident,
+		Since:    incident.OpenedAt.Add(-config.GetIncidentLookback()),
+		Until:    time.Now(),
+	}
+	if incident.ResolvedAt != nil {
+		timeline.Until = *incident.ResolvedAt
+	}
+
+	// The event filter takes a single type, so restricted timelines query each
+	var eventTypes []*string
+	if len(config.Alerts.Incidents.EventTypes) == 0 {
+		eventTypes = append(eventTypes, nil)
+	}
+	for i := range config.Alerts.Incidents.EventTypes {
+		eventTypes = append(eventTypes, &config.Alerts.Incidents.EventTypes[i])
+	}
+
+	limit := config.Alerts.Incidents.MaxEvents
+	for _, eventType := range eventTypes {
+		events, err := a.storageAdapter.ListEvents(&storage.EventFilter{
+			Type:  eventType,
+			Since: &timeline.Since,
+			Until: &timeline.Until,
+			Limit: &limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list incident events: %w", err)
+		}
+		timeline.Events = append(timeline.Events, events...)
+	}
+
+	// Keep the events closest to the resolution when there are too many
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Timestamp.Before(timeline.Events[j].Timestamp)
+	})
+	if len(timeline.Events) > limit {
+		timeline.Events = timeline.Events[len(timeline.Events)-limit:]
+	}
+
+	return timeline, nil
+}
@@ -0,0 +1,328 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/collectors"
+	"crucible/internal/monitor/storage"
+)
+
+// Settings that are only read at startup
+var restartOnlyPrefixes = []string{"agent.", "storage.", "notifications.", "ai."}
+
+// ReloadResult describes what a configuration reload changed
+type ReloadResult struct {
+	Changes         []monitor.ConfigChange `json:"changes"`
+	Started         []string               `json:"started,omitempty"`
+	Restarted       []string               `json:"restarted,omitempty"`
+	Stopped         []string               `json:"stopped,omitempty"`
+	RequiresRestart []string               `json:"requires_restart,omitempty"` // Changed settings that apply after an agent restart
+}
+
+// alertReload holds the validated alert configuration of a reload
+type alertReload struct {
+	config *alerts.Config
+	rules  []*alerts.AlertRule
+}
+
+// Reload re-reads the configuration file and alert rules, validates them and
+// restarts only the loops whose settings changed. Collected data, restart
+// tracking and active alerts are kept.
+func (a *Agent) Reload() (*ReloadResult, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	oldConfig := a.GetConfig()
+
+	newConfig, err := monitor.LoadConfig(oldConfig.Path())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	// Command line overrides are not part of the file
+	if oldConfig.Agent.Debug {
+		newConfig.Agent.Debug = true
+	}
+	a.runDiscovery(newConfig)
+
+	// Build everything that can fail before touching running state
+	var alertsReload *alertReload
+	if newConfig.Alerts.Enabled {
+		alertConfig, err := alerts.LoadConfig(newConfig.Alerts.RulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load alert config: %w", err)
+		}
+		alertRules, err := alerts.LoadRules(newConfig.Alerts.RulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load alert rules: %w", err)
+		}
+		alertsReload = &alertReload{config: alertConfig, rules: alertRules}
+	}
+
+	oldCollectors, newCollectors := oldConfig.Collectors, newConfig.Collectors
+
+	var journalCollector *collectors.JournalCollector
+	journalChanged := !reflect.DeepEqual(oldCollectors.Journal, newCollectors.Journal)
+	if newCollectors.Journal.Enabled && journalChanged {
+		journalCollector, err = collectors.NewJournalCollector(newCollectors.Journal.Units, newCollectors.Journal.Patterns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create journal collector: %w", err)
+		}
+	}
+
+	oldExec := make(map[string]monitor.ExecCollectorConfig, len(oldCollectors.Exec))
+	for _, execConfig := range oldCollectors.Exec {
+		oldExec[execConfig.Name] = execConfig
+	}
+	customCollectors := make([]customCollector, 0, len(newCollectors.Exec))
+	for _, execConfig := range newCollectors.Exec {
+		custom, err := newExecCustomCollector(execConfig)
+		if err != nil {
+			return nil, err
+		}
+		customCollectors = append(customCollectors, custom)
+	}
+
+	result := &ReloadResult{Changes: monitor.DiffConfig(oldConfig, newConfig)}
+	for _, change := range result.Changes {
+		for _, prefix := range restartOnlyPrefixes {
+			if strings.HasPrefix(change.Path, prefix) {
+				result.RequiresRestart = append(result.RequiresRestart, change.Path)
+				break
+			}
+		}
+	}
+
+	// Swap configuration and collectors, dropping results of removed checks
+	a.mu.Lock()
+	a.config = newConfig
+
+	if !reflect.DeepEqual(oldCollectors.Services, newCollectors.Services) {
+		a.servicesCollector = collectors.NewServicesCollector(newCollectors.Services.Services)
+	}
+
+	// Keep crash loop and restart tracking unless the collector target changed
+	if oldCollectors.PM2.PM2Home != newCollectors.PM2.PM2Home || oldCollectors.PM2.CrashLoopWindow != newCollectors.PM2.CrashLoopWindow {
+		a.pm2Collector = collectors.NewPM2Collector(newCollectors.PM2.PM2Home, newConfig.GetPM2CrashLoopWindow())
+	}
+	if oldCollectors.Supervisor.SocketPath != newCollectors.Supervisor.SocketPath {
+		a.supervisorCollector = collectors.NewSupervisorCollector(newCollectors.Supervisor.SocketPath)
+	}
+
+	if journalChanged {
+		a.journalCollector = journalCollector
+	}
+	a.customCollectors = customCollectors
+
+	httpChecks := make(map[string]bool)
+	for _, check := range newCollectors.HTTPChecks.Checks {
+		httpChecks[check.Name] = true
+	}
+	httpResults := a.httpCheckResults[:0]
+	for _, checkResult := range a.httpCheckResults {
+		if httpChecks[checkResult.Name] {
+			httpResults = append(httpResults, checkResult)
+		}
+	}
+	a.httpCheckResults = httpResults
+
+	probes := make(map[string]bool)
+	for _, probe := range newCollectors.Probes.Checks {
+		probes[probe.Name] = true
+	}
+	probeResults := a.probeResults[:0]
+	for _, probeResult := range a.probeResults {
+		if probes[probeResult.Name] {
+			probeResults = append(probeResults, probeResult)
+		}
+	}
+	a.probeResults = probeResults
+
+	for name := range oldExec {
+		if _, exists := a.findCustomCollector(name); !exists {
+			delete(a.collectorMetrics, name)
+			delete(a.lastCustomCollect, name)
+			delete(a.customCollectErrors, name)
+		}
+	}
+
+	if alertsReload != nil && a.alertManager == nil {
+		a.alertManager = alerts.NewAlertManager(alertsReload.config)
+	}
+	alertManager := a.alertManager
+	a.mu.Unlock()
+
+	// Swap alert rules atomically, the manager keeps its active alerts
+	if alertsReload != nil {
+		result.Changes = append(result.Changes, diffAlertRules(alertManager.GetRules(), alertsReload.rules)...)
+		if !reflect.DeepEqual(alertManager.GetConfig(), alertsReload.config) {
+			alertManager.SetConfig(alertsReload.config)
+			result.Changes = append(result.Changes, monitor.ConfigChange{Path: "alert_config"})
+		}
+		alertManager.ReplaceRules(alertsReload.rules)
+	}
+
+	// Restart loops whose settings changed
+	a.syncLoop(result, "system", newCollectors.System.Enabled,
+		!reflect.DeepEqual(oldCollectors.System, newCollectors.System), a.systemCollectorLoop)
+	a.syncLoop(result, "services", newCollectors.Services.Enabled,
+		!reflect.DeepEqual(oldCollectors.Services, newCollectors.Services), a.servicesCollectorLoop)
+	a.syncLoop(result, "pm2", newCollectors.PM2.Enabled,
+		!reflect.DeepEqual(oldCollectors.PM2, newCollectors.PM2), a.pm2CollectorLoop)
+	a.syncLoop(result, "supervisor", newCollectors.Supervisor.Enabled,
+		!reflect.DeepEqual(oldCollectors.Supervisor, newCollectors.Supervisor), a.supervisorCollectorLoop)
+	a.syncLoop(result, "journal", newCollectors.Journal.Enabled, journalChanged, a.journalCollectorLoop)
+	a.syncLoop(result, "alerts", newConfig.Alerts.Enabled, false, a.alertEvaluationLoop)
+	a.syncLoop(result, "slo", len(newConfig.SLOs) > 0 && a.storageAdapter != nil,
+		!reflect.DeepEqual(oldConfig.SLOs, newConfig.SLOs), a.sloLoop)
+	a.syncLoop(result, "statuspage", newConfig.StatusPage.Enabled,
+		!reflect.DeepEqual(oldConfig.StatusPage, newConfig.StatusPage), a.statusPageLoop)
+	if err := a.syncStatusPageServer(); err != nil {
+		a.logger.Error("Failed to start status page server", "error", err)
+	}
+
+	oldChecks := make(map[string]monitor.HTTPCheck, len(oldCollectors.HTTPChecks.Checks))
+	for _, check := range oldCollectors.HTTPChecks.Checks {
+		oldChecks[check.Name] = check
+	}
+	for _, check := range newCollectors.HTTPChecks.Checks {
+		previous, exists := oldChecks[check.Name]
+		delete(oldChecks, check.Name)
+		a.syncLoop(result, httpCheckLoopName(check.Name), newCollectors.HTTPChecks.Enabled,
+			!exists || !reflect.DeepEqual(previous, check), a.httpCheckLoopFunc(check))
+	}
+	for name := range oldChecks {
+		a.syncLoop(result, httpCheckLoopName(name), false, true, nil)
+	}
+
+	oldProbes := make(map[string]monitor.ProbeCheck, len(oldCollectors.Probes.Checks))
+	for _, probe := range oldCollectors.Probes.Checks {
+		oldProbes[probe.Name] = probe
+	}
+	for _, probe := range newCollectors.Probes.Checks {
+		previous, exists := oldProbes[probe.Name]
+		delete(oldProbes, probe.Name)
+		a.syncLoop(result, probeLoopName(probe.Name), newCollectors.Probes.Enabled,
+			!exists || !reflect.DeepEqual(previous, probe), a.probeLoopFunc(probe))
+	}
+	for name := range oldProbes {
+		a.syncLoop(result, probeLoopName(name), false, true, nil)
+	}
+
+	for i, execConfig := range newCollectors.Exec {
+		previous, exists := oldExec[execConfig.Name]
+		delete(oldExec, execConfig.Name)
+		a.syncLoop(result, customLoopName(execConfig.Name), true,
+			!exists || !reflect.DeepEqual(previous, execConfig), a.customCollectorLoopFunc(customCollectors[i]))
+	}
+	for name := range oldExec {
+		a.syncLoop(result, customLoopName(name), false, true, nil)
+	}
+
+	sort.Strings(result.Started)
+	sort.Strings(result.Restarted)
+	sort.Strings(result.Stopped)
+
+	a.logger.Info("Configuration reloaded", "changes", len(result.Changes),
+		"started", len(result.Started), "restarted", len(result.Restarted), "stopped", len(result.Stopped))
+	if len(result.RequiresRestart) > 0 {
+		a.logger.Warn("Some changed settings only apply after a restart", "settings", result.RequiresRestart)
+	}
+	a.recordConfigChange(result)
+
+	return result, nil
+}
+
+// recordConfigChange stores a configuration event so incident timelines show
+// what was changed. Only setting paths are recorded since values can be secrets.
+func (a *Agent) recordConfigChange(result *ReloadResult) {
+	if len(result.Changes) == 0 {
+		return
+	}
+
+	paths := make([]string, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		paths = append(paths, change.Path)
+	}
+
+	event := storage.NewEvent(nil, storage.EventTypeConfig,
+		fmt.Sprintf("Configuration reloaded with %d changed settings", len(paths)))
+	event.Details["changes"] = paths
+	for key, loops := range map[string][]string{
+		"started":          result.Started,
+		"restarted":        result.Restarted,
+		"stopped":          result.Stopped,
+		"requires_restart": result.RequiresRestart,
+	} {
+		if len(loops) > 0 {
+			event.Details[key] = loops
+		}
+	}
+	a.storeResults("config event", func() error {
+		return a.storageAdapter.StoreEvent(event)
+	})
+}
+
+// syncLoop brings a named loop in line with the new configuration, recording what it did
+func (a *Agent) syncLoop(result *ReloadResult, name string, enabled, changed bool, run func(ctx context.Context)) {
+	running := a.isLoopRunning(name)
+
+	switch {
+	case !enabled:
+		if a.stopLoop(name) {
+			result.Stopped = append(result.Stopped, name)
+		}
+	case !running:
+		a.startLoop(name, run)
+		result.Started = append(result.Started, name)
+	case changed:
+		a.startLoop(name, run)
+		result.Restarted = append(result.Restarted, name)
+	}
+}
+
+// findCustomCollector returns the custom collector with the given name
+func (a *Agent) findCustomCollector(name string) (customCollector, bool) {
+	for _, custom := range a.customCollectors {
+		if custom.collector.Name() == name {
+			return custom, true
+		}
+	}
+	return customCollector{}, false
+}
+
+// diffAlertRules returns the alert rules that were added, removed or changed
+func diffAlertRules(oldRules map[string]*alerts.AlertRule, newRules []*alerts.AlertRule) []monitor.ConfigChange {
+	var changes []monitor.ConfigChange
+
+	seen := make(map[string]bool, len(newRules))
+	for _, rule := range newRules {
+		seen[rule.ID] = true
+		path := fmt.Sprintf("alert_rules[%s]", rule.ID)
+
+		previous, exists := oldRules[rule.ID]
+		switch {
+		case !exists:
+			changes = append(changes, monitor.ConfigChange{Path: path, New: rule})
+		case !reflect.DeepEqual(previous, rule):
+			changes = append(changes, monitor.ConfigChange{Path: path, Old: previous, New: rule})
+		}
+	}
+
+	for id, rule := range oldRules {
+		if !seen[id] {
+			changes = append(changes, monitor.ConfigChange{Path: fmt.Sprintf("alert_rules[%s]", id), Old: rule})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
@@ -0,0 +1,304 @@
+package agent
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/alerts"
+)
+
+// selfCollectorName is the collector the agent stores its own metrics under
+const selfCollectorName = "agent"
+
+// databaseSizeMetric records the growth of the monitor database
+const databaseSizeMetric = "agent_database_size_bytes"
+
+// SelfStats describes how the agent itself is performing
+type SelfStats struct {
+	StartedAt       time.Time                       `json:"started_at"`
+	Goroutines      int                             `json:"goroutines"`
+	HeapBytes       uint64                          `json:"heap_bytes"`
+	Collectors      map[string]CollectorStats       `json:"collectors"`
+	StorageWrites   TimingStats                     `json:"storage_writes"`
+	WriteQueue      WriteQueueStats                 `json:"write_queue"`
+	DatabaseBytes   int64                           `json:"database_bytes"` // Size of the database file, zero for in-memory storage
+	AlertEvaluation TimingStats                     `json:"alert_evaluation"`
+	Notifications   map[string]alerts.NotifierStats `json:"notifications"`
+}
+
+// CollectorStats describes the runs of a collector loop
+type CollectorStats struct {
+	Interval          string     `json:"interval"`
+	Runs              int64      `json:"runs"`
+	Errors            int64      `json:"errors"`
+	ConsecutiveErrors int64      `json:"consecutive_errors"`
+	LastDurationMs    float64    `json:"last_duration_ms"`
+	MaxDurationMs     float64    `json:"max_duration_ms"`
+	StartedAt         time.Time  `json:"started_at"`
+	LastRun           *time.Time `json:"last_run,omitempty"`
+	LastSuccess       *time.Time `json:"last_success,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	LastErrorTime     *time.Time `json:"last_error_time,omitempty"`
+
+	interval time.Duration
+}
+
+// TimingStats describes the latency of a repeated operation
+type TimingStats struct {
+	Count  int64   `json:"count"`
+	Errors int64   `json:"errors"`
+	LastMs float64 `json:"last_ms"`
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  float64 `json:"max_ms"`
+
+	totalMs float64
+}
+
+// WriteQueueStats describes the samples waiting to be forwarded to remote write endpoints
+type WriteQueueStats struct {
+	PendingSamples int `json:"pending_samples"`
+	QueuedBatches  int `json:"queued_batches"`
+}
+
+// record adds a timed operation
+func (t *TimingStats) record(d time.Duration, err error) {
+	ms := float64(d.Microseconds()) / 1000
+	t.Count++
+	t.LastMs = ms
+	t.totalMs += ms
+	t.AvgMs = t.totalMs / float64(t.Count)
+	if ms > t.MaxMs {
+		t.MaxMs = ms
+	}
+	if err != nil {
+		t.Errors++
+	}
+}
+
+// selfMonitor records the activity of collector loops, storage writes and alert evaluation
+type selfMonitor struct {
+	mu              sync.Mutex
+	collectors      map[string]*CollectorStats
+	storageWrites   TimingStats
+	alertEvaluation TimingStats
+}
+
+// newSelfMonitor creates an empty self monitor
+func newSelfMonitor() *selfMonitor {
+	return &selfMonitor{collectors: make(map[string]*CollectorStats)}
+}
+
+// start registers a collector loop, resetting its stats. Loops without an
+// interval produce data on demand.
+func (m *selfMonitor) start(name string, interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.collectors[name] = &CollectorStats{
+		Interval:  interval.String(),
+		StartedAt: time.Now(),
+		interval:  interval,
+	}
+}
+
+// forget removes a stopped collector loop
+func (m *selfMonitor) forget(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.collectors, name)
+}
+
+// record adds a run of a collector loop, ignoring loops that were stopped meanwhile
+func (m *selfMonitor) record(name string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, exists := m.collectors[name]
+	if !exists {
+		return
+	}
+
+	now := time.Now()
+	ms := float64(d.Microseconds()) / 1000
+	stats.Runs++
+	stats.LastRun = &now
+	stats.LastDurationMs = ms
+	if ms > stats.MaxDurationMs {
+		stats.MaxDurationMs = ms
+	}
+
+	if err != nil {
+		stats.Errors++
+		stats.ConsecutiveErrors++
+		stats.LastError = err.Error()
+		stats.LastErrorTime = &now
+		return
+	}
+	stats.ConsecutiveErrors = 0
+	stats.LastSuccess = &now
+}
+
+// recordStorageWrite adds a write to persistent storage
+func (m *selfMonitor) recordStorageWrite(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.storageWrites.record(d, err)
+}
+
+// recordAlertEvaluation adds an evaluation of the alert rules
+func (m *selfMonitor) recordAlertEvaluation(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alertEvaluation.record(d, err)
+}
+
+// snapshot copies the recorded stats
+func (m *selfMonitor) snapshot() (map[string]CollectorStats, TimingStats, TimingStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collectors := make(map[string]CollectorStats, len(m.collectors))
+	for name, stats := range m.collectors {
+		collectors[name] = *stats
+	}
+	return collectors, m.storageWrites, m.alertEvaluation
+}
+
+// collectorStates returns the progress of every collector loop for alert evaluation
+func (m *selfMonitor) collectorStates() map[string]alerts.CollectorState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states := make(map[string]alerts.CollectorState, len(m.collectors))
+	for name, stats := range m.collectors {
+		states[name] = alerts.CollectorState{
+			Interval:    stats.interval,
+			StartedAt:   stats.StartedAt,
+			LastSuccess: stats.LastSuccess,
+			LastError:   stats.LastError,
+		}
+	}
+	return states
+}
+
+// GetSelfStats returns how the agent itself is performing
+func (a *Agent) GetSelfStats() *SelfStats {
+	collectors, storageWrites, alertEvaluation := a.self.snapshot()
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	stats := &SelfStats{
+		StartedAt:       a.startTime,
+		Goroutines:      runtime.NumGoroutine(),
+		HeapBytes:       memStats.HeapAlloc,
+		Collectors:      collectors,
+		StorageWrites:   storageWrites,
+		AlertEvaluation: alertEvaluation,
+		Notifications:   make(map[string]alerts.NotifierStats),
+	}
+
+	if a.storageAdapter != nil {
+		for _, endpoint := range a.storageAdapter.RemoteWriteStatus() {
+			stats.WriteQueue.PendingSamples += endpoint.PendingSamples
+			stats.WriteQueue.QueuedBatches += endpoint.QueuedBatches
+		}
+		if size, err := a.storageAdapter.DatabaseSize(); err == nil {
+			stats.DatabaseBytes = size
+		}
+	}
+
+	if alertManager := a.getAlertManager(); alertManager != nil {
+		stats.Notifications = alertManager.GetNotifierStats()
+	}
+
+	return stats
+}
+
+// selfMetricsLoop periodically records the agent's own stats as metrics
+func (a *Agent) selfMetricsLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.GetConfig().GetCollectInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.collectSelfMetrics()
+		}
+	}
+}
+
+// collectSelfMetrics flattens the agent's own stats into metrics
+func (a *Agent) collectSelfMetrics() {
+	stats := a.GetSelfStats()
+	now := time.Now()
+
+	metric := func(name string, value float64, unit string, labels map[string]string) monitor.Metric {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		return monitor.Metric{
+			Name:      name,
+			Type:      monitor.MetricTypeAgent,
+			Value:     value,
+			Unit:      unit,
+			Labels:    labels,
+			Timestamp: now,
+		}
+	}
+
+	metrics := []monitor.Metric{
+		metric("agent_uptime_seconds", now.Sub(stats.StartedAt).Seconds(), "seconds", nil),
+		metric("agent_goroutines", float64(stats.Goroutines), "", nil),
+		metric("agent_heap_bytes", float64(stats.HeapBytes), "bytes", nil),
+		metric("agent_storage_write_ms", stats.StorageWrites.AvgMs, "ms", nil),
+		metric("agent_storage_write_errors_total", float64(stats.StorageWrites.Errors), "", nil),
+		metric("agent_write_queue_samples", float64(stats.WriteQueue.PendingSamples), "", nil),
+		metric("agent_write_queue_batches", float64(stats.WriteQueue.QueuedBatches), "", nil),
+		metric("agent_alert_evaluation_ms", stats.AlertEvaluation.LastMs, "ms", nil),
+	}
+	if stats.DatabaseBytes > 0 {
+		metrics = append(metrics, metric(databaseSizeMetric, float64(stats.DatabaseBytes), "bytes", nil))
+	}
+
+	names := make([]string, 0, len(stats.Collectors))
+	for name := range stats.Collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		collector := stats.Collectors[name]
+		labels := map[string]string{"collector": name}
+		metrics = append(metrics,
+			metric("agent_collector_duration_ms", collector.LastDurationMs, "ms", labels),
+			metric("agent_collector_runs_total", float64(collector.Runs), "", labels),
+			metric("agent_collector_errors_total", float64(collector.Errors), "", labels),
+		)
+		if collector.LastSuccess != nil {
+			metrics = append(metrics, metric("agent_collector_last_success_age_seconds",
+				now.Sub(*collector.LastSuccess).Seconds(), "seconds", labels))
+		}
+	}
+
+	for name, notifier := range stats.Notifications {
+		labels := map[string]string{"notifier": name}
+		metrics = append(metrics,
+			metric("agent_notifications_sent_total", float64(notifier.Sent), "", labels),
+			metric("agent_notifications_failed_total", float64(notifier.Failed), "", labels),
+		)
+	}
+
+	a.mu.Lock()
+	a.collectorMetrics[selfCollectorName] = metrics
+	a.mu.Unlock()
+
+	a.storeResults("agent metrics", func() error {
+		return a.storageAdapter.StoreCollectorMetrics(selfCollectorName, metrics)
+	})
+}
@@ -2,18 +2,33 @@ package agent
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"crucible/internal/logging"
 	"crucible/internal/monitor"
+	"crucible/internal/monitor/ai"
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/archive"
+	"crucible/internal/monitor/remotewrite"
 	"crucible/internal/monitor/storage"
 )
 
+// archiveTimeout bounds an export or import, which streams whole datasets
+const archiveTimeout = time.Hour
+
 // Server represents the monitoring agent HTTP API server
 type Server struct {
 	config *monitor.Config
@@ -43,10 +58,22 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/v1/metrics/system", s.handleSystemMetrics)
 	mux.HandleFunc("/api/v1/metrics/services", s.handleServiceMetrics)
 	mux.HandleFunc("/api/v1/metrics/http", s.handleHTTPMetrics)
+	mux.HandleFunc("/api/v1/metrics/probes", s.handleProbeMetrics)
+	mux.HandleFunc("/api/v1/metrics/pm2", s.handlePM2Metrics)
+	mux.HandleFunc("/api/v1/metrics/supervisor", s.handleSupervisorMetrics)
+	mux.HandleFunc("/api/v1/metrics/custom", s.handleCustomMetrics)
+
+	// Log endpoints
+	mux.HandleFunc("/api/v1/logs", s.handleLogEvents)
 
 	// Alert endpoints
 	mux.HandleFunc("/api/v1/alerts", s.handleAlerts)
 	mux.HandleFunc("/api/v1/alerts/", s.handleAlertActions)
+	mux.HandleFunc("/api/v1/anomalies", s.handleAnomalies)
+	mux.HandleFunc("/api/v1/forecasts", s.handleForecasts)
+	mux.HandleFunc("/api/v1/slos", s.handleSLOs)
+	mux.HandleFunc("/api/v1/incidents", s.handleIncidents)
+	mux.HandleFunc("/api/v1/incidents/", s.handleIncidentTimeline)
 
 	// Storage endpoints (for historical data)
 	mux.HandleFunc("/api/v1/entities", s.handleEntities)
@@ -54,14 +81,27 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/v1/events", s.handleEvents)
 	mux.HandleFunc("/api/v1/metrics", s.handleMetrics)
 	mux.HandleFunc("/api/v1/metrics/summary", s.handleMetricSummary)
+	mux.HandleFunc("/api/v1/metrics/query", s.handleMetricQuery)
 	mux.HandleFunc("/api/v1/storage/health", s.handleStorageHealth)
 	mux.HandleFunc("/api/v1/storage/stats", s.handleStorageStats)
+	mux.HandleFunc("/api/v1/storage/remote-write", s.handleRemoteWrite)
+	mux.HandleFunc("/api/v1/export", s.handleExport)
+	mux.HandleFunc("/api/v1/import", s.handleImport)
 
 	// Configuration endpoints
 	mux.HandleFunc("/api/v1/config", s.handleConfig)
+	mux.HandleFunc("/api/v1/config/reload", s.handleConfigReload)
+	mux.HandleFunc("/api/v1/config/http-checks", s.handleConfigHTTPChecks)
+	mux.HandleFunc("/api/v1/config/http-checks/", s.handleConfigHTTPChecks)
+	mux.HandleFunc("/api/v1/config/services", s.handleConfigServices)
+	mux.HandleFunc("/api/v1/config/services/", s.handleConfigServices)
+	mux.HandleFunc("/api/v1/config/alert-rules", s.handleConfigAlertRules)
+	mux.HandleFunc("/api/v1/config/alert-rules/", s.handleConfigAlertRules)
+	mux.HandleFunc("/api/v1/config/versions", s.handleConfigVersions)
+	mux.HandleFunc("/api/v1/config/versions/", s.handleConfigVersionActions)
 
 	// CORS middleware for development
-	handler := s.corsMiddleware(mux)
+	handler := s.corsMiddleware(s.authMiddleware(mux))
 
 	s.server = &http.Server{
 		Addr:         s.config.Agent.ListenAddr,
@@ -86,12 +126,18 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
-// corsMiddleware adds CORS headers for development
+// corsMiddleware adds CORS headers for development. Cross-origin requests may
+// only read, and never the configuration.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isConfigPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -102,6 +148,65 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// authMiddleware requires the API token as a bearer token on every request
+// that is not a GET, and on every configuration request since stored versions
+// hold the configuration file. Without a configured token, only local
+// processes such as the TUI may write, and the configuration API is disabled.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !isConfigPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		config := s.agent.GetConfig()
+		token := config.Agent.GetAPIToken()
+		if token == "" {
+			switch {
+			case isConfigPath(r.URL.Path):
+				http.Error(w, "API token not configured, set agent.api_token to use the configuration API", http.StatusForbidden)
+			case !isLocalRequest(r):
+				http.Error(w, "API token not configured, set agent.api_token to allow changes from other hosts or browsers", http.StatusForbidden)
+			default:
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Invalid or missing API token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLocalRequest reports whether a request comes from a process on this host.
+// Browsers send an Origin with cross-site requests, which never count as local
+// so that web pages cannot write through the browser of a local user.
+func isLocalRequest(r *http.Request) bool {
+	if r.Header.Get("Origin") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isConfigPath reports whether a request path is one of the configuration endpoints
+func isConfigPath(path string) bool {
+	return path == "/api/v1/config" || strings.HasPrefix(path, "/api/v1/config/")
+}
+
 // handleHealth returns the health status of the monitoring agent
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -145,9 +250,10 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 			"metrics_count": s.agent.GetMetricsCount(),
 		},
 		"alerts": map[string]interface{}{
-			"enabled":      s.config.Alerts.Enabled,
+			"enabled":      s.agent.GetConfig().Alerts.Enabled,
 			"active_count": s.agent.GetActiveAlertsCount(),
 		},
+		"self": s.agent.GetSelfStats(),
 	}
 
 	s.writeJSONResponse(w, status)
@@ -204,6 +310,97 @@ func (s *Server) handleHTTPMetrics(w http.ResponseWriter, r *http.Request) {
 	s.writeJSONResponse(w, httpChecks)
 }
 
+// handleProbeMetrics returns TCP, DNS, ICMP and TLS probe results
+func (s *Server) handleProbeMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	probes, err := s.agent.GetProbeResults()
+	if err != nil {
+		s.logger.Error("Failed to get probe results", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, probes)
+}
+
+// handlePM2Metrics returns PM2 application status
+func (s *Server) handlePM2Metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	apps, err := s.agent.GetPM2Apps()
+	if err != nil {
+		s.logger.Error("Failed to get PM2 status", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, apps)
+}
+
+// handleSupervisorMetrics returns supervisord process state
+func (s *Server) handleSupervisorMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	processes, err := s.agent.GetSupervisorProcesses()
+	if err != nil {
+		s.logger.Error("Failed to get supervisor status", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, processes)
+}
+
+// handleCustomMetrics returns the latest sample of every custom collector
+func (s *Server) handleCustomMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	metrics, err := s.agent.GetCollectorMetrics()
+	if err != nil {
+		s.logger.Error("Failed to get custom metrics", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Optional filter by collector name
+	if name := r.URL.Query().Get("collector"); name != "" {
+		s.writeJSONResponse(w, map[string][]monitor.Metric{name: metrics[name]})
+		return
+	}
+
+	s.writeJSONResponse(w, metrics)
+}
+
+// handleLogEvents returns journal entries matched within the last hour
+func (s *Server) handleLogEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	events, err := s.agent.GetLogEvents()
+	if err != nil {
+		s.logger.Error("Failed to get log events", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, events)
+}
+
 // handleAlerts returns active alerts
 func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -221,6 +418,184 @@ func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	s.writeJSONResponse(w, alerts)
 }
 
+// handleAnomalies returns the latest anomaly scores, optionally only the anomalous series
+func (s *Server) handleAnomalies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.agent.GetConfig().IsAnomalyDetectionEnabled() {
+		http.Error(w, "Anomaly detection not enabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	scores := s.agent.GetAnomalyScores()
+	if r.URL.Query().Get("anomalous") == "true" {
+		anomalous := scores[:0]
+		for _, score := range scores {
+			if score.Anomalous {
+				anomalous = append(anomalous, score)
+			}
+		}
+		scores = anomalous
+	}
+	if scores == nil {
+		scores = []ai.Score{}
+	}
+
+	s.writeJSONResponse(w, scores)
+}
+
+// handleForecasts returns when disks, inodes and the monitor database are forecast to fill up
+func (s *Server) handleForecasts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.agent.GetStorageAdapter() == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	forecasts := s.agent.GetForecasts()
+	if resource := r.URL.Query().Get("resource"); resource != "" {
+		filtered := forecasts[:0]
+		for _, forecast := range forecasts {
+			if forecast.Resource == resource {
+				filtered = append(filtered, forecast)
+			}
+		}
+		forecasts = filtered
+	}
+	if forecasts == nil {
+		forecasts = []ai.Forecast{}
+	}
+
+	s.writeJSONResponse(w, forecasts)
+}
+
+// handleSLOs returns the error budget and burn rates of every SLO
+func (s *Server) handleSLOs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.agent.GetStorageAdapter() == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	slos := s.agent.GetSLOs()
+	if name := r.URL.Query().Get("name"); name != "" {
+		filtered := slos[:0]
+		for _, slo := range slos {
+			if slo.Name == name {
+				filtered = append(filtered, slo)
+			}
+		}
+		slos = filtered
+	}
+	if slos == nil {
+		slos = []SLOStatus{}
+	}
+
+	s.writeJSONResponse(w, slos)
+}
+
+// handleIncidents returns incidents opened by fired alerts, most recent first
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.agent.GetStorageAdapter() == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter := &storage.IncidentFilter{}
+	query := r.URL.Query()
+
+	if status := query.Get("status"); status != "" {
+		filter.Status = &status
+	}
+	if ruleID := query.Get("rule_id"); ruleID != "" {
+		filter.RuleID = &ruleID
+	}
+	if since := query.Get("since"); since != "" {
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filter.Since = &t
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		if t, err := time.Parse(time.RFC3339, until); err == nil {
+			filter.Until = &t
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil && l > 0 {
+			filter.Limit = &l
+		}
+	}
+	if offset := query.Get("offset"); offset != "" {
+		if o, err := strconv.Atoi(offset); err == nil && o >= 0 {
+			filter.Offset = &o
+		}
+	}
+
+	incidents, err := s.agent.GetIncidents(filter)
+	if err != nil {
+		s.logger.Error("Failed to list incidents", "error", err)
+		http.Error(w, "Failed to retrieve incidents", http.StatusInternalServerError)
+		return
+	}
+	if incidents == nil {
+		incidents = []*storage.Incident{}
+	}
+
+	response := map[string]interface{}{
+		"incidents": incidents,
+		"count":     len(incidents),
+		"filter":    filter,
+	}
+
+	s.writeJSONResponse(w, response)
+}
+
+// handleIncidentTimeline returns an incident with the events recorded around it
+func (s *Server) handleIncidentTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.agent.GetStorageAdapter() == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/v1/incidents/"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid incident ID", http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := s.agent.GetIncidentTimeline(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if timeline.Events == nil {
+		timeline.Events = []*storage.Event{}
+	}
+
+	s.writeJSONResponse(w, timeline)
+}
+
 // handleAlertActions handles alert management actions (acknowledge, etc.)
 func (s *Server) handleAlertActions(w http.ResponseWriter, r *http.Request) {
 	// Extract alert ID from URL path
@@ -282,32 +657,293 @@ func (s *Server) handleAlertActions(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		s.writeJSONResponse(w, s.config)
+		s.writeJSONResponse(w, s.agent.GetConfig())
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
+// handleConfigReload reloads the configuration from disk and reports what changed
+func (s *Server) handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := s.agent.Reload()
+	if err != nil {
+		s.logger.Error("Failed to reload configuration", "error", err)
+		http.Error(w, fmt.Sprintf("Failed to reload configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.writeJSONResponse(w, result)
+}
+
+// handleConfigHTTPChecks adds, updates and deletes HTTP checks
+func (s *Server) handleConfigHTTPChecks(w http.ResponseWriter, r *http.Request) {
+	name := configItemName(r, "/api/v1/config/http-checks")
+
+	var update *ConfigUpdate
+	var err error
+	switch {
+	case r.Method == http.MethodPost && name == "":
+		var check monitor.HTTPCheck
+		if err := decodeConfigBody(r, &check); err != nil {
+			writeConfigBodyError(w, err)
+			return
+		}
+		update, err = s.agent.AddHTTPCheck(check)
+	case r.Method == http.MethodPut && name != "":
+		var check monitor.HTTPCheck
+		if err := decodeConfigBody(r, &check); err != nil {
+			writeConfigBodyError(w, err)
+			return
+		}
+		update, err = s.agent.UpdateHTTPCheck(name, check)
+	case r.Method == http.MethodDelete && name != "":
+		update, err = s.agent.DeleteHTTPCheck(name)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.writeConfigUpdate(w, update, err)
+}
+
+// handleConfigServices adds and deletes monitored services
+func (s *Server) handleConfigServices(w http.ResponseWriter, r *http.Request) {
+	name := configItemName(r, "/api/v1/config/services")
+
+	var update *ConfigUpdate
+	var err error
+	switch {
+	case r.Method == http.MethodPost && name == "":
+		var service struct {
+			Name string `yaml:"name"`
+		}
+		if err := decodeConfigBody(r, &service); err != nil {
+			writeConfigBodyError(w, err)
+			return
+		}
+		update, err = s.agent.AddService(service.Name)
+	case r.Method == http.MethodDelete && name != "":
+		update, err = s.agent.DeleteService(name)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.writeConfigUpdate(w, update, err)
+}
+
+// handleConfigAlertRules adds, updates and deletes alert rules
+func (s *Server) handleConfigAlertRules(w http.ResponseWriter, r *http.Request) {
+	id := configItemName(r, "/api/v1/config/alert-rules")
+
+	var update *ConfigUpdate
+	var err error
+	switch {
+	case r.Method == http.MethodPost && id == "":
+		var rule alerts.AlertRuleConfig
+		if err := decodeConfigBody(r, &rule); err != nil {
+			writeConfigBodyError(w, err)
+			return
+		}
+		update, err = s.agent.AddAlertRule(rule)
+	case r.Method == http.MethodPut && id != "":
+		var rule alerts.AlertRuleConfig
+		if err := decodeConfigBody(r, &rule); err != nil {
+			writeConfigBodyError(w, err)
+			return
+		}
+		update, err = s.agent.UpdateAlertRule(id, rule)
+	case r.Method == http.MethodDelete && id != "":
+		update, err = s.agent.DeleteAlertRule(id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.writeConfigUpdate(w, update, err)
+}
+
+// handleConfigVersions lists the recorded configuration versions
+func (s *Server) handleConfigVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	versions, err := s.agent.ListConfigVersions(limit)
+	if err != nil {
+		s.writeConfigUpdate(w, nil, err)
+		return
+	}
+
+	s.writeJSONResponse(w, map[string]interface{}{
+		"versions": versions,
+		"count":    len(versions),
+	})
+}
+
+// handleConfigVersionActions returns a configuration version or rolls back to it
+func (s *Server) handleConfigVersionActions(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/config/versions/")
+	idStr, action, _ := strings.Cut(path, "/")
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid version ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		version, err := s.agent.GetConfigVersion(id)
+		if err != nil {
+			if errors.Is(err, ErrConfigHistoryUnavailable) {
+				s.writeConfigUpdate(w, nil, err)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSONResponse(w, version)
+	case r.Method == http.MethodPost && action == "rollback":
+		update, err := s.agent.RollbackConfig(id)
+		s.writeConfigUpdate(w, update, err)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeConfigUpdate writes the result of a configuration write, mapping errors to status codes
+func (s *Server) writeConfigUpdate(w http.ResponseWriter, update *ConfigUpdate, err error) {
+	if err == nil {
+		s.writeJSONResponse(w, update)
+		return
+	}
+
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, ErrConfigItemNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrConfigItemExists):
+		status = http.StatusConflict
+	case errors.Is(err, ErrConfigHistoryUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+
+	s.logger.Error("Failed to update configuration", "error", err)
+	http.Error(w, fmt.Sprintf("Failed to update configuration: %v", err), status)
+}
+
+// configItemName returns the path segment following prefix, if any
+func configItemName(r *http.Request, prefix string) string {
+	return strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/")
+}
+
+// errUnsupportedMediaType is returned for configuration bodies that are neither JSON nor YAML
+var errUnsupportedMediaType = errors.New("unsupported content type, expected application/json or application/yaml")
+
+// decodeConfigBody decodes a JSON or YAML request body using the configuration file keys
+func decodeConfigBody(r *http.Request, out interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return errUnsupportedMediaType
+	}
+	switch mediaType {
+	case "application/json", "application/yaml", "application/x-yaml", "text/yaml":
+	default:
+		return errUnsupportedMediaType
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	// JSON is valid YAML, so both formats share the YAML field names
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// writeConfigBodyError answers a configuration request whose body could not be decoded
+func writeConfigBodyError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, errUnsupportedMediaType) {
+		status = http.StatusUnsupportedMediaType
+	}
+	http.Error(w, err.Error(), status)
+}
+
 // getCollectorStatus returns the status of all collectors
 func (s *Server) getCollectorStatus() map[string]interface{} {
-	return map[string]interface{}{
+	config := s.agent.GetConfig()
+	collectors := map[string]interface{}{
 		"system": map[string]interface{}{
-			"enabled":      s.config.Collectors.System.Enabled,
-			"interval":     s.config.Collectors.System.Interval,
+			"enabled":      config.Collectors.System.Enabled,
+			"interval":     config.Collectors.System.Interval,
 			"last_collect": s.agent.GetLastSystemCollect(),
 		},
 		"services": map[string]interface{}{
-			"enabled":        s.config.Collectors.Services.Enabled,
-			"interval":       s.config.Collectors.Services.Interval,
-			"services_count": len(s.config.Collectors.Services.Services),
+			"enabled":        config.Collectors.Services.Enabled,
+			"interval":       config.Collectors.Services.Interval,
+			"services_count": len(config.Collectors.Services.Services),
 			"last_collect":   s.agent.GetLastServicesCollect(),
 		},
 		"http_checks": map[string]interface{}{
-			"enabled":      s.config.Collectors.HTTPChecks.Enabled,
-			"checks_count": len(s.config.Collectors.HTTPChecks.Checks),
+			"enabled":      config.Collectors.HTTPChecks.Enabled,
+			"checks_count": len(config.Collectors.HTTPChecks.Checks),
 			"last_collect": s.agent.GetLastHTTPChecksCollect(),
 		},
+		"probes": map[string]interface{}{
+			"enabled":      config.Collectors.Probes.Enabled,
+			"probes_count": len(config.Collectors.Probes.Checks),
+			"last_collect": s.agent.GetLastProbesCollect(),
+		},
+		"pm2": map[string]interface{}{
+			"enabled":      config.Collectors.PM2.Enabled,
+			"interval":     config.Collectors.PM2.Interval,
+			"last_collect": s.agent.GetLastPM2Collect(),
+		},
+		"supervisor": map[string]interface{}{
+			"enabled":      config.Collectors.Supervisor.Enabled,
+			"interval":     config.Collectors.Supervisor.Interval,
+			"last_collect": s.agent.GetLastSupervisorCollect(),
+		},
+		"journal": map[string]interface{}{
+			"enabled":    config.Collectors.Journal.Enabled,
+			"units":      config.Collectors.Journal.Units,
+			"patterns":   len(config.Collectors.Journal.Patterns),
+			"last_event": s.agent.GetLastJournalEvent(),
+		},
 	}
+
+	// Add custom exec collectors
+	for _, execConfig := range config.Collectors.Exec {
+		lastCollect, lastError := s.agent.GetCustomCollectorStatus(execConfig.Name)
+		status := map[string]interface{}{
+			"enabled":      true,
+			"type":         "exec",
+			"format":       execConfig.Format,
+			"interval":     execConfig.Interval,
+			"last_collect": lastCollect,
+		}
+		if lastError != "" {
+			status["last_error"] = lastError
+		}
+		collectors["exec:"+execConfig.Name] = status
+	}
+
+	return collectors
 }
 
 // handleEntities returns a list of entities from storage
@@ -482,8 +1118,31 @@ func (s *Server) handleStorageStats(w http.ResponseWriter, r *http.Request) {
 	s.writeJSONResponse(w, stats)
 }
 
-// handleEvents returns events from storage
-func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+// handleRemoteWrite returns the state of the remote write endpoints
+func (s *Server) handleRemoteWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.agent.storageAdapter == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	statuses := s.agent.storageAdapter.RemoteWriteStatus()
+	if statuses == nil {
+		statuses = []remotewrite.Status{}
+	}
+
+	s.writeJSONResponse(w, map[string]interface{}{
+		"remote_write": statuses,
+		"count":        len(statuses),
+	})
+}
+
+// handleExport streams a dataset as CSV, JSON Lines or Parquet
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -495,6 +1154,113 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
+	dataset := query.Get("data")
+	if !archive.IsDataset(dataset) {
+		http.Error(w, "Invalid data, expected entities, events or metrics", http.StatusBadRequest)
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = archive.FormatCSV
+	}
+	contentType, err := archive.ContentType(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	options := archive.ExportOptions{Format: format, MetricName: query.Get("metric_name")}
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %s", since), http.StatusBadRequest)
+			return
+		}
+		options.Since = &t
+	}
+	if until := query.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid until: %s", until), http.StatusBadRequest)
+			return
+		}
+		options.Until = &t
+	}
+
+	s.extendArchiveDeadlines(w)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", archive.FileName(dataset, format)))
+
+	// The status is sent with the first row, a failure can only cut the response short
+	if _, err := archive.Export(storageAdapter.GetStorage(), w, dataset, options); err != nil {
+		s.logger.Error("Failed to export monitoring data", "dataset", dataset, "error", err)
+	}
+}
+
+// extendArchiveDeadlines lets an export or import outlast the server timeouts,
+// which are meant for short API requests
+func (s *Server) extendArchiveDeadlines(w http.ResponseWriter) {
+	controller := http.NewResponseController(w)
+	deadline := time.Now().Add(archiveTimeout)
+	if err := controller.SetReadDeadline(deadline); err != nil {
+		s.logger.Warn("Failed to extend read deadline", "error", err)
+	}
+	if err := controller.SetWriteDeadline(deadline); err != nil {
+		s.logger.Warn("Failed to extend write deadline", "error", err)
+	}
+}
+
+// handleImport imports an exported file sent as request body
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storageAdapter := s.agent.GetStorageAdapter()
+	if storageAdapter == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	s.extendArchiveDeadlines(w)
+	reader, err := archive.NewReader(r.Body, r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := archive.Import(storageAdapter.GetStorage(), reader)
+	if err != nil {
+		s.logger.Error("Failed to import monitoring data", "dataset", result.Dataset, "imported", result.Imported, "error", err)
+		http.Error(w, fmt.Sprintf("Import failed after %d rows: %v", result.Imported, err), http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Info("Imported monitoring data", "dataset", result.Dataset, "imported", result.Imported,
+		"updated", result.Updated, "skipped", result.Skipped)
+	s.writeJSONResponse(w, result)
+}
+
+// handleEvents returns events from storage, or records one posted by a client such as the TUI
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storageAdapter := s.agent.GetStorageAdapter()
+	if storageAdapter == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		s.createEvent(w, r, storageAdapter)
+		return
+	}
+
 	// Parse query parameters for filtering
 	filter := &storage.EventFilter{}
 	query := r.URL.Query()
@@ -547,6 +1313,44 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	s.writeJSONResponse(w, response)
 }
 
+// createEvent stores an event posted as JSON, defaulting its time and severity
+func (s *Server) createEvent(w http.ResponseWriter, r *http.Request, storageAdapter *storage.StorageAdapter) {
+	var event storage.Event
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&event); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if event.Type == "" || event.Message == "" {
+		http.Error(w, "event_type and message are required", http.StatusBadRequest)
+		return
+	}
+
+	switch event.Severity {
+	case "":
+		event.Severity = storage.SeverityInfo
+	case storage.SeverityInfo, storage.SeverityWarning, storage.SeverityError, storage.SeverityCritical:
+	default:
+		http.Error(w, fmt.Sprintf("invalid severity: %s", event.Severity), http.StatusBadRequest)
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Details == nil {
+		event.Details = make(storage.JSON)
+	}
+	event.ID = 0
+	event.ExpiresAt = nil
+
+	if err := storageAdapter.StoreEvent(&event); err != nil {
+		s.logger.Error("Failed to store posted event", "type", event.Type, "error", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, event)
+}
+
 // handleMetrics returns metrics from storage
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -575,6 +1379,7 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	if aggregationLevel := query.Get("aggregation_level"); aggregationLevel != "" {
 		filter.AggregationLevel = &aggregationLevel
 	}
+	filter.Tags = parseTagFilter(query)
 	if since := query.Get("since"); since != "" {
 		if t, err := time.Parse(time.RFC3339, since); err == nil {
 			filter.Since = &t
@@ -612,6 +1417,125 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	s.writeJSONResponse(w, response)
 }
 
+// handleMetricQuery returns a metric downsampled to aligned time series
+func (s *Server) handleMetricQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	storageAdapter := s.agent.GetStorageAdapter()
+	if storageAdapter == nil {
+		http.Error(w, "Storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	metricQuery, err := parseMetricQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := metricQuery.Normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := storageAdapter.QueryMetrics(metricQuery)
+	if err != nil {
+		s.logger.Error("Failed to query metrics", "error", err)
+		http.Error(w, "Failed to query metrics", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSONResponse(w, result)
+}
+
+// parseMetricQuery builds a metric query from query parameters. The step is
+// either given directly or derived from the number of points wanted.
+func parseMetricQuery(query url.Values) (*storage.MetricQuery, error) {
+	metricQuery := &storage.MetricQuery{
+		MetricName:  query.Get("metric_name"),
+		Aggregation: query.Get("aggregation"),
+	}
+
+	if entityID := query.Get("entity_id"); entityID != "" {
+		id, err := strconv.ParseInt(entityID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entity_id: %s", entityID)
+		}
+		metricQuery.EntityID = &id
+	}
+	if aggregationLevel := query.Get("aggregation_level"); aggregationLevel != "" {
+		metricQuery.AggregationLevel = &aggregationLevel
+	}
+	metricQuery.Tags = parseTagFilter(query)
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %s", since)
+		}
+		metricQuery.Since = t
+	}
+	if until := query.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %s", until)
+		}
+		metricQuery.Until = t
+	}
+	if percentile := query.Get("percentile"); percentile != "" {
+		p, err := strconv.ParseFloat(percentile, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percentile: %s", percentile)
+		}
+		metricQuery.Percentile = p
+	}
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		for _, key := range strings.Split(groupBy, ",") {
+			metricQuery.GroupBy = append(metricQuery.GroupBy, strings.TrimSpace(key))
+		}
+	}
+
+	if step := query.Get("step"); step != "" {
+		d, err := time.ParseDuration(step)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid step: %s", step)
+		}
+		metricQuery.Step = d
+	} else if points := query.Get("points"); points != "" {
+		n, err := strconv.Atoi(points)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid points: %s", points)
+		}
+		if metricQuery.Until.IsZero() {
+			metricQuery.Until = time.Now()
+		}
+		if metricQuery.Since.IsZero() {
+			metricQuery.Since = metricQuery.Until.Add(-time.Hour)
+		}
+		metricQuery.Step = metricQuery.Until.Sub(metricQuery.Since) / time.Duration(n)
+	}
+
+	return metricQuery, nil
+}
+
+// parseTagFilter returns the tag filters given as tag=key:value parameters
+func parseTagFilter(query url.Values) map[string]string {
+	var tags map[string]string
+	for _, tag := range query["tag"] {
+		key, value, found := strings.Cut(tag, ":")
+		if !found || key == "" {
+			continue
+		}
+		if tags == nil {
+			tags = make(map[string]string)
+		}
+		tags[key] = value
+	}
+	return tags
+}
+
 // handleMetricSummary returns aggregated metric summaries
 func (s *Server) handleMetricSummary(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -640,6 +1564,7 @@ func (s *Server) handleMetricSummary(w http.ResponseWriter, r *http.Request) {
 	if aggregationLevel := query.Get("aggregation_level"); aggregationLevel != "" {
 		filter.AggregationLevel = &aggregationLevel
 	}
+	filter.Tags = parseTagFilter(query)
 	if since := query.Get("since"); since != "" {
 		if t, err := time.Parse(time.RFC3339, since); err == nil {
 			filter.Since = &t
@@ -679,6 +1604,7 @@ func (s *Server) handleEntityMetrics(w http.ResponseWriter, r *http.Request, ent
 	if aggregationLevel := query.Get("aggregation_level"); aggregationLevel != "" {
 		filter.AggregationLevel = &aggregationLevel
 	}
+	filter.Tags = parseTagFilter(query)
 	if since := query.Get("since"); since != "" {
 		if t, err := time.Parse(time.RFC3339, since); err == nil {
 			filter.Since = &t
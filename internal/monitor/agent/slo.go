@@ -0,0 +1,393 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/alerts"
+	"crucible/internal/monitor/storage"
+)
+
+// sloInterval is how often SLOs are brought up to date with stored check results
+const sloInterval = time.Minute
+
+// sloSettleTime keeps the newest check results out of a pass until they are surely stored
+const sloSettleTime = 5 * time.Second
+
+// sloBurnWindows are the burn rate windows always reported, those of the
+// common fast and slow burn alerts
+var sloBurnWindows = []time.Duration{
+	5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour,
+}
+
+// SLO statuses
+const (
+	SLOStatusMet      = "met"
+	SLOStatusAtRisk   = "at_risk"  // Less than a quarter of the error budget left
+	SLOStatusBreached = "breached" // Error budget spent
+	SLOStatusNoData   = "no_data"
+)
+
+// SLOStatus is the error budget of a service level objective over its window
+type SLOStatus struct {
+	Name            string             `json:"name"`
+	Check           string             `json:"check"`
+	Type            string             `json:"type"`
+	Objective       float64            `json:"objective"`
+	Window          string             `json:"window"`
+	ThresholdMs     *int64             `json:"threshold_ms,omitempty"`
+	Status          string             `json:"status"`
+	Total           int                `json:"total"`                      // Checks counted towards the SLO
+	Good            int                `json:"good"`                       // Checks that met the objective
+	SLI             *float64           `json:"sli,omitempty"`              // Percent of good checks
+	P95Ms           *float64           `json:"p95_ms,omitempty"`           // Of successful checks
+	BudgetRemaining *float64           `json:"budget_remaining,omitempty"` // Percent, negative once overspent
+	BurnRates       map[string]float64 `json:"burn_rates"`                 // By window, only windows with checks
+	DataSince       *time.Time         `json:"data_since,omitempty"`       // Oldest check in the window
+	UpdatedAt       time.Time          `json:"updated_at"`
+
+	burnRates map[time.Duration]float64
+}
+
+// sloTracker keeps the recent results of HTTP checks with SLOs, reading only
+// the results stored since its previous pass
+type sloTracker struct {
+	mu       sync.RWMutex
+	checks   map[string]*sloSeries // By check name
+	statuses []SLOStatus
+}
+
+// sloSeries holds the results of an HTTP check, oldest first
+type sloSeries struct {
+	entityID  int64
+	watermark time.Time // Results before it have been read
+	samples   []sloSample
+}
+
+// sloSample is a single HTTP check result
+type sloSample struct {
+	timestamp time.Time
+	success   bool
+	latency   float64 // Milliseconds
+}
+
+// newSLOTracker creates an SLO tracker
+func newSLOTracker() *sloTracker {
+	return &sloTracker{checks: make(map[string]*sloSeries)}
+}
+
+// sloLoop tracks the error budgets of the configured SLOs
+func (a *Agent) sloLoop(ctx context.Context) {
+	tracker := newSLOTracker()
+
+	a.mu.Lock()
+	a.sloTracker = tracker
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		if a.sloTracker == tracker {
+			a.sloTracker = nil
+		}
+		a.mu.Unlock()
+	}()
+
+	a.self.start("slo", sloInterval)
+
+	ticker := time.NewTicker(sloInterval)
+	defer ticker.Stop()
+
+	// Evaluate immediately on start
+	a.updateSLOs(tracker)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.updateSLOs(tracker)
+		}
+	}
+}
+
+// updateSLOs reads new check results and recomputes the SLO statuses
+func (a *Agent) updateSLOs(tracker *sloTracker) {
+	a.logger.Debug("Updating SLOs")
+
+	start := time.Now()
+	err := a.evaluateSLOs(tracker, start)
+	a.self.record("slo", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to update SLOs", "error", err)
+	}
+}
+
+// evaluateSLOs brings the tracked check results up to now and computes the
+// status of every SLO. Checks without results yet are reported without data.
+func (a *Agent) evaluateSLOs(tracker *sloTracker, now time.Time) error {
+	slos := a.GetConfig().SLOs
+	until := now.Truncate(time.Second).Add(-sloSettleTime)
+
+	retention := make(map[string]time.Duration)
+	for _, slo := range slos {
+		if window := slo.GetWindow(); window > retention[slo.Check] {
+			retention[slo.Check] = window
+		}
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	for check := range tracker.checks {
+		if _, exists := retention[check]; !exists {
+			delete(tracker.checks, check)
+		}
+	}
+
+	var firstErr error
+	for check, window := range retention {
+		series, exists := tracker.checks[check]
+		if !exists {
+			series = &sloSeries{watermark: until.Add(-window)}
+			tracker.checks[check] = series
+		}
+		if err := a.readSLOSamples(check, series, until); err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		oldest := until.Add(-window)
+		keep := sort.Search(len(series.samples), func(i int) bool {
+			return !series.samples[i].timestamp.Before(oldest)
+		})
+		series.samples = series.samples[keep:]
+	}
+
+	burnWindows := a.sloBurnWindows()
+	statuses := make([]SLOStatus, 0, len(slos))
+	for _, slo := range slos {
+		var samples []sloSample
+		if series, exists := tracker.checks[slo.Check]; exists {
+			samples = series.samples
+		}
+		statuses = append(statuses, sloStatus(slo, samples, burnWindows, until))
+	}
+	tracker.statuses = statuses
+
+	return firstErr
+}
+
+// readSLOSamples appends the results of a check stored since the series watermark
+func (a *Agent) readSLOSamples(check string, series *sloSeries, until time.Time) error {
+	if series.entityID == 0 {
+		entityID, err := a.siteEntityID(check)
+		if err != nil || entityID == 0 {
+			return err
+		}
+		series.entityID = entityID
+	}
+
+	// Until is inclusive, the next pass starts at it
+	metricName := "response_time_ms"
+	level := storage.AggregationLevelRaw
+	last := until.Add(-time.Nanosecond)
+	metrics, err := a.storageAdapter.ListMetrics(&storage.MetricFilter{
+		EntityID:         &series.entityID,
+		MetricName:       &metricName,
+		AggregationLevel: &level,
+		Since:            &series.watermark,
+		Until:            &last,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list results of %s: %w", check, err)
+	}
+
+	// Metrics are listed newest first
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Timestamp.Before(metrics[j].Timestamp)
+	})
+	for _, metric := range metrics {
+		success, _ := metric.Tags["success"].(bool)
+		series.samples = append(series.samples, sloSample{
+			timestamp: metric.Timestamp,
+			success:   success,
+			latency:   metric.Value,
+		})
+	}
+	series.watermark = until
+	return nil
+}
+
+// sloStatus computes the error budget of an SLO from the results of its check
+// over its window. Latency SLOs only count successful checks, failures are
+// left to availability SLOs.
+func sloStatus(slo monitor.SLOConfig, samples []sloSample, burnWindows []time.Duration, until time.Time) SLOStatus {
+	window := slo.GetWindow()
+	status := SLOStatus{
+		Name:      slo.Name,
+		Check:     slo.Check,
+		Type:      slo.Type,
+		Objective: slo.Objective,
+		Window:    windowName(window),
+		Status:    SLOStatusNoData,
+		BurnRates: make(map[string]float64),
+		UpdatedAt: until,
+		burnRates: make(map[time.Duration]float64),
+	}
+
+	latencySLO := slo.Type == "latency"
+	var threshold float64
+	if latencySLO {
+		thresholdMs := slo.GetThreshold().Milliseconds()
+		status.ThresholdMs = &thresholdMs
+		threshold = float64(thresholdMs)
+	}
+
+	totals := make([]int, len(burnWindows))
+	bad := make([]int, len(burnWindows))
+	var latencies []float64
+	since := until.Add(-window)
+	for _, sample := range samples {
+		if sample.timestamp.Before(since) {
+			continue
+		}
+		if sample.success {
+			latencies = append(latencies, sample.latency)
+		}
+		if latencySLO && !sample.success {
+			continue
+		}
+
+		good := sample.success && (!latencySLO || sample.latency <= threshold)
+		status.Total++
+		if good {
+			status.Good++
+		}
+		if status.DataSince == nil {
+			timestamp := sample.timestamp
+			status.DataSince = &timestamp
+		}
+
+		age := until.Sub(sample.timestamp)
+		for i, burnWindow := range burnWindows {
+			if burnWindow <= window && age <= burnWindow {
+				totals[i]++
+				if !good {
+					bad[i]++
+				}
+			}
+		}
+	}
+
+	if len(latencies) > 0 {
+		sort.Float64s(latencies)
+		p95 := latencies[int(math.Ceil(0.95*float64(len(latencies))))-1]
+		status.P95Ms = &p95
+	}
+	if status.Total == 0 {
+		return status
+	}
+
+	// The error budget is the share of bad checks the objective allows
+	allowed := (100 - slo.Objective) / 100
+	badShare := float64(status.Total-status.Good) / float64(status.Total)
+	sli := roundTo((1-badShare)*100, 4)
+	remaining := roundTo((1-badShare/allowed)*100, 2)
+	status.SLI = &sli
+	status.BudgetRemaining = &remaining
+
+	for i, burnWindow := range burnWindows {
+		if totals[i] == 0 {
+			continue
+		}
+		burnRate := roundTo(float64(bad[i])/float64(totals[i])/allowed, 2)
+		status.burnRates[burnWindow] = burnRate
+		status.BurnRates[windowName(burnWindow)] = burnRate
+	}
+
+	switch {
+	case remaining <= 0:
+		status.Status = SLOStatusBreached
+	case remaining < 25:
+		status.Status = SLOStatusAtRisk
+	default:
+		status.Status = SLOStatusMet
+	}
+	return status
+}
+
+// sloBurnWindows returns the standard burn rate windows plus those of SLO alert rules
+func (a *Agent) sloBurnWindows() []time.Duration {
+	windows := append([]time.Duration(nil), sloBurnWindows...)
+	if alertManager := a.getAlertManager(); alertManager != nil {
+		for _, rule := range alertManager.GetRules() {
+			if rule.Enabled && rule.Type == alerts.AlertTypeSLO {
+				long, short := rule.Conditions.BurnRateWindows()
+				windows = append(windows, long, short)
+			}
+		}
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	unique := windows[:0]
+	for _, window := range windows {
+		if len(unique) == 0 || unique[len(unique)-1] != window {
+			unique = append(unique, window)
+		}
+	}
+	return unique
+}
+
+// roundTo rounds a value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// windowName formats a window in the largest whole unit, such as 30d or 5m
+func windowName(window time.Duration) string {
+	switch {
+	case window%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", window/(24*time.Hour))
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%dh", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%dm", window/time.Minute)
+	default:
+		return window.String()
+	}
+}
+
+// GetSLOs returns the latest status of every SLO, nil when SLOs are not tracked
+func (a *Agent) GetSLOs() []SLOStatus {
+	a.mu.RLock()
+	tracker := a.sloTracker
+	a.mu.RUnlock()
+
+	if tracker == nil {
+		return nil
+	}
+
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	return append([]SLOStatus(nil), tracker.statuses...)
+}
+
+// sloStates converts the latest SLO statuses for alert evaluation
+func (a *Agent) sloStates() []alerts.SLOState {
+	statuses := a.GetSLOs()
+	states := make([]alerts.SLOState, 0, len(statuses))
+	for _, status := range statuses {
+		states = append(states, alerts.SLOState{
+			Name:            status.Name,
+			Check:           status.Check,
+			Objective:       status.Objective,
+			BudgetRemaining: status.BudgetRemaining,
+			BurnRates:       status.burnRates,
+		})
+	}
+	return states
+}
@@ -0,0 +1,441 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+	"crucible/internal/monitor/statuspage"
+	"crucible/internal/monitor/storage"
+)
+
+// uptimeMetric is the daily uptime percentage of a site, rolled up from its
+// checks so the history outlives the raw samples
+const uptimeMetric = "uptime_percent"
+
+// statusPageLoop rolls up daily uptime and rebuilds the status page
+func (a *Agent) statusPageLoop(ctx context.Context) {
+	interval := a.GetConfig().GetStatusPageInterval()
+	a.self.start("statuspage", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Build immediately on start
+	a.refreshStatusPage()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.refreshStatusPage()
+		}
+	}
+}
+
+// refreshStatusPage rebuilds the status page and writes it to the output directory
+func (a *Agent) refreshStatusPage() {
+	a.logger.Debug("Building status page")
+
+	start := time.Now()
+	page := a.buildStatusPage(start)
+
+	a.mu.Lock()
+	a.statusPage = page
+	a.mu.Unlock()
+
+	var err error
+	if dir := a.GetConfig().StatusPage.OutputDir; dir != "" {
+		err = statuspage.WriteDir(dir, page)
+	}
+	a.self.record("statuspage", time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Failed to write status page", "error", err)
+	}
+}
+
+// syncStatusPageServer starts, moves or stops the status page server to match
+// the configuration. The page has its own server so that exposing it never
+// exposes the API.
+func (a *Agent) syncStatusPageServer() error {
+	config := a.GetConfig()
+	addr := ""
+	if config.StatusPage.Enabled {
+		addr = config.StatusPage.ListenAddr
+	}
+
+	a.statusServerMu.Lock()
+	defer a.statusServerMu.Unlock()
+
+	if a.statusServer != nil && a.statusServer.Addr == addr {
+		return nil
+	}
+	if a.statusServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.statusServer.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to stop status page server: %w", err)
+		}
+		a.statusServer = nil
+	}
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start status page server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", a.handleStatusPage)
+	mux.HandleFunc("/status/status.json", a.handleStatusPage)
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  30 * time.Second,
+	}
+	a.statusServer = server
+
+	a.logger.Info("Starting status page server", "addr", addr)
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.Error("Status page server failed", "addr", addr, "error", err)
+		}
+	}()
+	return nil
+}
+
+// stopStatusPageServer gracefully stops the status page server, if running
+func (a *Agent) stopStatusPageServer(ctx context.Context) error {
+	a.statusServerMu.Lock()
+	defer a.statusServerMu.Unlock()
+
+	if a.statusServer == nil {
+		return nil
+	}
+	err := a.statusServer.Shutdown(ctx)
+	a.statusServer = nil
+	return err
+}
+
+// handleStatusPage serves the public status page as HTML or JSON
+func (a *Agent) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page := a.GetStatusPage()
+	if strings.HasSuffix(r.URL.Path, ".json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			a.logger.Error("Failed to encode status page", "error", err)
+		}
+		return
+	}
+
+	html, err := statuspage.Render(page)
+	if err != nil {
+		a.logger.Error("Failed to render status page", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(html)
+}
+
+// GetStatusPage returns the latest status page, building it if its loop has not yet
+func (a *Agent) GetStatusPage() *statuspage.Page {
+	a.mu.RLock()
+	page := a.statusPage
+	a.mu.RUnlock()
+
+	if page == nil {
+		page = a.buildStatusPage(time.Now())
+	}
+	return page
+}
+
+// buildStatusPage gathers the status, daily uptime, open incidents and
+// maintenance of the components configured for the status page
+func (a *Agent) buildStatusPage(now time.Time) *statuspage.Page {
+	a.statusPageMu.Lock()
+	defer a.statusPageMu.Unlock()
+
+	config := a.GetConfig()
+	page := &statuspage.Page{
+		Title:       config.StatusPage.Title,
+		Days:        config.StatusPage.Days,
+		Groups:      []statuspage.Group{},
+		Incidents:   []statuspage.Incident{},
+		Maintenance: []statuspage.Maintenance{},
+		GeneratedAt: now,
+	}
+
+	groups := config.StatusPage.Groups
+	if len(groups) == 0 {
+		group := monitor.StatusPageGroup{Name: "Services"}
+		for _, check := range config.Collectors.HTTPChecks.Checks {
+			group.Components = append(group.Components, monitor.StatusPageComponent{Check: check.Name})
+		}
+		groups = []monitor.StatusPageGroup{group}
+	}
+
+	// Display names by check, for incidents and maintenance
+	names := make(map[string]string)
+	for _, group := range groups {
+		for _, component := range group.Components {
+			names[component.Check] = componentName(component)
+		}
+	}
+
+	// Checks under active maintenance show as such instead of their result
+	underMaintenance := make(map[string]bool)
+	allUnderMaintenance := false
+	for _, window := range config.StatusPage.Maintenance {
+		start, _ := time.Parse(time.RFC3339, window.Start)
+		end, _ := time.Parse(time.RFC3339, window.End)
+		if !end.After(now) {
+			continue
+		}
+
+		maintenance := statuspage.Maintenance{
+			Title:       window.Title,
+			Description: window.Description,
+			Start:       start,
+			End:         end,
+			Components:  []string{},
+			Active:      !start.After(now),
+		}
+		for _, check := range window.Checks {
+			if name, exists := names[check]; exists {
+				maintenance.Components = append(maintenance.Components, name)
+			}
+			if maintenance.Active {
+				underMaintenance[check] = true
+			}
+		}
+		if maintenance.Active && len(window.Checks) == 0 {
+			allUnderMaintenance = true
+		}
+		page.Maintenance = append(page.Maintenance, maintenance)
+	}
+	sort.SliceStable(page.Maintenance, func(i, j int) bool {
+		return page.Maintenance[i].Start.Before(page.Maintenance[j].Start)
+	})
+
+	results := make(map[string]monitor.HTTPCheckResult)
+	a.mu.RLock()
+	for _, result := range a.httpCheckResults {
+		results[result.Name] = result
+	}
+	a.mu.RUnlock()
+
+	for _, group := range groups {
+		pageGroup := statuspage.Group{Name: group.Name, Components: []statuspage.Component{}}
+		for _, component := range group.Components {
+			pageComponent := statuspage.Component{
+				Name:        componentName(component),
+				Description: component.Description,
+				Status:      statuspage.StatusUnknown,
+			}
+			if result, exists := results[component.Check]; exists {
+				pageComponent.Status = statuspage.StatusOutage
+				if result.Success {
+					pageComponent.Status = statuspage.StatusOperational
+				}
+			}
+			if allUnderMaintenance || underMaintenance[component.Check] {
+				pageComponent.Status = statuspage.StatusMaintenance
+			}
+
+			pageComponent.History = a.dailyUptime(component.Check, page.Days, now)
+			var checks, successful float64
+			for _, day := range pageComponent.History {
+				if day.Uptime != nil {
+					checks += float64(day.Checks)
+					successful += *day.Uptime / 100 * float64(day.Checks)
+				}
+			}
+			if checks > 0 {
+				uptime := successful / checks * 100
+				pageComponent.Uptime = &uptime
+			}
+
+			pageGroup.Components = append(pageGroup.Components, pageComponent)
+		}
+		page.Groups = append(page.Groups, pageGroup)
+	}
+
+	page.Incidents = a.statusPageIncidents(names)
+	page.UpdateStatus()
+	return page
+}
+
+// componentName returns the display name of a status page component
+func componentName(component monitor.StatusPageComponent) string {
+	if component.Name != "" {
+		return component.Name
+	}
+	return component.Check
+}
+
+// statusPageIncidents returns the open incidents of HTTP alert rules for
+// checks on the status page, leaving out alerts about the server itself
+func (a *Agent) statusPageIncidents(names map[string]string) []statuspage.Incident {
+	incidents := []statuspage.Incident{}
+	if a.storageAdapter == nil {
+		return incidents
+	}
+
+	status := storage.IncidentStatusOpen
+	open, err := a.storageAdapter.ListIncidents(&storage.IncidentFilter{Status: &status})
+	if err != nil {
+		a.logger.Error("Failed to list incidents for the status page", "error", err)
+		return incidents
+	}
+
+	for _, incident := range open {
+		endpoint, _ := incident.Details["endpoint"].(string)
+		name, exists := names[endpoint]
+		if !exists {
+			continue
+		}
+		incidents = append(incidents, statuspage.Incident{
+			Title:      incident.Title,
+			Severity:   incident.Severity,
+			Components: []string{name},
+			Since:      incident.OpenedAt,
+		})
+	}
+	return incidents
+}
+
+// dailyUptime returns the uptime of an HTTP check for each of the given
+// number of UTC days up to today, oldest first. Completed days are computed
+// once from the raw check results and stored as daily aggregates; today is
+// always computed.
+func (a *Agent) dailyUptime(check string, days int, now time.Time) []statuspage.Day {
+	const day = 24 * time.Hour
+	today := now.UTC().Truncate(day)
+	since := today.Add(-time.Duration(days-1) * day)
+
+	history := make([]statuspage.Day, days)
+	for i := range history {
+		history[i].Date = since.Add(time.Duration(i) * day)
+	}
+	if a.storageAdapter == nil {
+		return history
+	}
+
+	entityID, err := a.siteEntityID(check)
+	if err != nil {
+		a.logger.Error("Failed to find site for uptime", "check", check, "error", err)
+		return history
+	}
+	if entityID == 0 {
+		return history
+	}
+
+	// Days already rolled up
+	metricName := uptimeMetric
+	level := storage.AggregationLevelDaily
+	rollups, err := a.storageAdapter.ListMetrics(&storage.MetricFilter{
+		EntityID:         &entityID,
+		MetricName:       &metricName,
+		AggregationLevel: &level,
+		Since:            &since,
+	})
+	if err != nil {
+		a.logger.Error("Failed to list uptime rollups", "check", check, "error", err)
+		return history
+	}
+	rolledUp := make(map[int64]*storage.Metric, len(rollups))
+	for _, rollup := range rollups {
+		rolledUp[rollup.Timestamp.Unix()] = rollup
+	}
+
+	// Check counts per day, split by outcome
+	raw := storage.AggregationLevelRaw
+	counts, err := a.storageAdapter.QueryMetrics(&storage.MetricQuery{
+		MetricName:       "response_time_ms",
+		EntityID:         &entityID,
+		AggregationLevel: &raw,
+		Since:            since,
+		Until:            today.Add(day),
+		Step:             day,
+		Aggregation:      storage.QueryAggregationCount,
+		GroupBy:          []string{"success"},
+	})
+	if err != nil {
+		a.logger.Error("Failed to query check results for uptime", "check", check, "error", err)
+		return history
+	}
+	total := make(map[int64]float64)
+	successful := make(map[int64]float64)
+	for _, series := range counts.Series {
+		for i, value := range series.Values {
+			if value == nil {
+				continue
+			}
+			timestamp := counts.Timestamps[i].Unix()
+			total[timestamp] += *value
+			if series.Labels["success"] == "true" {
+				successful[timestamp] += *value
+			}
+		}
+	}
+
+	for i := range history {
+		timestamp := history[i].Date.Unix()
+		if rollup, exists := rolledUp[timestamp]; exists {
+			uptime := rollup.Value
+			history[i].Uptime = &uptime
+			history[i].Checks = rollup.SampleCount
+			continue
+		}
+		if total[timestamp] == 0 {
+			continue
+		}
+
+		uptime := successful[timestamp] / total[timestamp] * 100
+		history[i].Uptime = &uptime
+		history[i].Checks = int(total[timestamp])
+
+		if history[i].Date.Before(today) {
+			a.storeResults("uptime rollup", func() error {
+				return a.storageAdapter.StoreDailyMetric(entityID, uptimeMetric, uptime, history[i].Date, history[i].Checks)
+			}, "check", check)
+		}
+	}
+
+	return history
+}
+
+// siteEntityID returns the ID of the site entity of an HTTP check, zero until
+// its first result is stored
+func (a *Agent) siteEntityID(check string) (int64, error) {
+	entityType := storage.EntityTypeSite
+	entities, err := a.storageAdapter.ListEntities(&storage.EntityFilter{Type: &entityType, Name: &check})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sites: %w", err)
+	}
+	// The name filter matches substrings
+	for _, entity := range entities {
+		if entity.Name == check {
+			return entity.ID, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,417 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor/storage"
+)
+
+const (
+	// Resolution of baselines and recent values
+	baselineStep = 5 * time.Minute
+	// How often baselines are rebuilt from history
+	baselineRefresh = time.Hour
+	// Recent values smoothed into the scored value
+	recentWindow = 30 * time.Minute
+	// Weight of the newest value in the smoothed value
+	ewmaAlpha = 0.5
+
+	// An hour of the week needs this many steps of history, otherwise the
+	// baseline over all hours is used
+	minBucketSamples = 6
+	// A series needs this many steps of history before it is scored
+	minBaselineSamples = 36
+
+	// Scales the median absolute deviation to a standard deviation
+	madScale = 1.4826
+	// Floor on the spread as a fraction of the expected value, so flat
+	// metrics don't flag tiny changes
+	minRelativeSpread = 0.05
+	minSpread         = 1e-6
+
+	// An anomaly ends once the score falls below this fraction of the threshold
+	recoveryFraction = 0.75
+
+	hoursPerWeek = 7 * 24
+)
+
+// sensitivityThresholds maps sensitivity settings to robust z-score thresholds
+var sensitivityThresholds = map[string]float64{
+	"low":    5,
+	"medium": 3.5,
+	"high":   2.5,
+}
+
+// MetricSource provides the stored metrics that baselines are learned from
+type MetricSource interface {
+	QueryMetrics(query *storage.MetricQuery) (*storage.MetricQueryResult, error)
+	GetEntity(id int64) (*storage.Entity, error)
+}
+
+// AnomalySettings configures an anomaly detector
+type AnomalySettings struct {
+	Sensitivity    string
+	LearningPeriod time.Duration
+}
+
+// Score is the latest anomaly score of a series
+type Score struct {
+	Metric     string     `json:"metric"`
+	EntityID   *int64     `json:"entity_id,omitempty"`
+	EntityType string     `json:"entity_type,omitempty"`
+	Entity     string     `json:"entity,omitempty"`
+	Value      float64    `json:"value"`     // Smoothed recent value
+	Expected   float64    `json:"expected"`  // Median of the baseline
+	Spread     float64    `json:"spread"`    // Scaled median absolute deviation of the baseline
+	Score      float64    `json:"score"`     // Signed robust z-score
+	Threshold  float64    `json:"threshold"` // Score at which the series is anomalous
+	Anomalous  bool       `json:"anomalous"`
+	Seasonal   bool       `json:"seasonal"` // False when the hour of the week lacked history
+	Bucket     string     `json:"bucket"`   // Hour of the week the value was compared with
+	Since      *time.Time `json:"since,omitempty"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// Name returns the metric and entity of the score
+func (s *Score) Name() string {
+	if s.Entity == "" {
+		return s.Metric
+	}
+	return fmt.Sprintf("%s on %s %s", s.Metric, s.EntityType, s.Entity)
+}
+
+// Transition reports a series that became anomalous or returned to normal
+type Transition struct {
+	Score    Score
+	Started  bool
+	Duration time.Duration // How long the anomaly lasted, set when it ended
+}
+
+// distribution summarises the values of a baseline bucket
+type distribution struct {
+	median float64
+	spread float64
+	count  int
+}
+
+// baseline holds the distribution of a series for every hour of the week
+type baseline struct {
+	hours   [hoursPerWeek]distribution
+	overall distribution
+}
+
+// AnomalyDetector scores stored metrics against seasonal baselines learned
+// from their own history, entirely from local data
+type AnomalyDetector struct {
+	source    MetricSource
+	settings  AnomalySettings
+	threshold float64
+
+	mu        sync.RWMutex
+	baselines map[string]map[string]*baseline // Series baselines by metric
+	builtAt   map[string]time.Time
+	scores    map[string]*Score
+	entities  map[int64]*storage.Entity
+}
+
+// NewAnomalyDetector creates an anomaly detector
+func NewAnomalyDetector(source MetricSource, settings AnomalySettings) *AnomalyDetector {
+	threshold, exists := sensitivityThresholds[settings.Sensitivity]
+	if !exists {
+		threshold = sensitivityThresholds["medium"]
+	}
+
+	return &AnomalyDetector{
+		source:    source,
+		settings:  settings,
+		threshold: threshold,
+		baselines: make(map[string]map[string]*baseline),
+		builtAt:   make(map[string]time.Time),
+		scores:    make(map[string]*Score),
+		entities:  make(map[int64]*storage.Entity),
+	}
+}
+
+// Evaluate rebuilds outdated baselines, scores the recent values of every
+// metric and returns the series that became anomalous or returned to normal.
+// A metric that fails to load doesn't stop the others, the first error is returned.
+func (d *AnomalyDetector) Evaluate(metrics []string, now time.Time) ([]Transition, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var transitions []Transition
+	var firstErr error
+	seen := make(map[string]bool)
+	for _, metric := range metrics {
+		if seen[metric] {
+			continue
+		}
+		seen[metric] = true
+
+		if now.Sub(d.builtAt[metric]) >= baselineRefresh {
+			baselines, err := d.learn(metric, now)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			d.baselines[metric] = baselines
+			d.builtAt[metric] = now
+		}
+
+		metricTransitions, err := d.score(metric, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		transitions = append(transitions, metricTransitions...)
+	}
+
+	// Forget metrics that are no longer watched
+	for metric := range d.baselines {
+		if !seen[metric] {
+			delete(d.baselines, metric)
+			delete(d.builtAt, metric)
+		}
+	}
+	for key, score := range d.scores {
+		if !seen[score.Metric] {
+			delete(d.scores, key)
+		}
+	}
+
+	return transitions, firstErr
+}
+
+// Scores returns the latest score of every series, most unusual first
+func (d *AnomalyDetector) Scores() []Score {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	scores := make([]Score, 0, len(d.scores))
+	for _, score := range d.scores {
+		scores = append(scores, *score)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if math.Abs(scores[i].Score) != math.Abs(scores[j].Score) {
+			return math.Abs(scores[i].Score) > math.Abs(scores[j].Score)
+		}
+		return scores[i].Name() < scores[j].Name()
+	})
+	return scores
+}
+
+// Threshold returns the score at which series are anomalous
+func (d *AnomalyDetector) Threshold() float64 {
+	return d.threshold
+}
+
+// learn builds the baselines of every series of a metric from the learning period
+func (d *AnomalyDetector) learn(metric string, now time.Time) (map[string]*baseline, error) {
+	step := baselineStep
+	if points := d.settings.LearningPeriod / step; points > 8000 {
+		step = (d.settings.LearningPeriod / 8000).Truncate(time.Minute) + time.Minute
+	}
+
+	result, err := d.source.QueryMetrics(&storage.MetricQuery{
+		MetricName:  metric,
+		Since:       now.Add(-d.settings.LearningPeriod),
+		Until:       now,
+		Step:        step,
+		Aggregation: storage.QueryAggregationAvg,
+		GroupBy:     []string{storage.GroupByEntityID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history of %s: %w", metric, err)
+	}
+
+	baselines := make(map[string]*baseline, len(result.Series))
+	for _, series := range result.Series {
+		var hours [hoursPerWeek][]float64
+		var all []float64
+		for i, value := range series.Values {
+			if value == nil {
+				continue
+			}
+			hour := hourOfWeek(result.Timestamps[i])
+			hours[hour] = append(hours[hour], *value)
+			all = append(all, *value)
+		}
+
+		b := &baseline{overall: newDistribution(all)}
+		for hour, values := range hours {
+			b.hours[hour] = newDistribution(values)
+		}
+		baselines[series.Labels[storage.GroupByEntityID]] = b
+	}
+
+	return baselines, nil
+}
+
+// score compares the smoothed recent values of every series of a metric with its baseline
+func (d *AnomalyDetector) score(metric string, now time.Time) ([]Transition, error) {
+	result, err := d.source.QueryMetrics(&storage.MetricQuery{
+		MetricName:  metric,
+		Since:       now.Add(-recentWindow),
+		Until:       now,
+		Step:        baselineStep,
+		Aggregation: storage.QueryAggregationAvg,
+		GroupBy:     []string{storage.GroupByEntityID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent values of %s: %w", metric, err)
+	}
+
+	hour := hourOfWeek(now)
+	bucket := now.Truncate(time.Hour).Format("Mon 15:04")
+
+	var transitions []Transition
+	seen := make(map[string]bool)
+	for _, series := range result.Series {
+		entityKey := series.Labels[storage.GroupByEntityID]
+		b, exists := d.baselines[metric][entityKey]
+		if !exists {
+			continue
+		}
+
+		// Series that stopped reporting are not scored
+		value, last, ok := smoothed(series.Values)
+		if !ok || last < len(series.Values)-2 {
+			continue
+		}
+
+		expected, seasonal := b.hours[hour], true
+		if expected.count < minBucketSamples {
+			expected, seasonal = b.overall, false
+		}
+		if b.overall.count < minBaselineSamples {
+			continue
+		}
+
+		spread := math.Max(expected.spread, math.Max(minRelativeSpread*math.Abs(expected.median), minSpread))
+		key := metric + "\xff" + entityKey
+		seen[key] = true
+
+		score := &Score{
+			Metric:    metric,
+			Value:     value,
+			Expected:  expected.median,
+			Spread:    spread,
+			Score:     (value - expected.median) / spread,
+			Threshold: d.threshold,
+			Seasonal:  seasonal,
+			Bucket:    bucket,
+			Timestamp: now,
+		}
+		d.describeEntity(score, entityKey)
+
+		previous := d.scores[key]
+		wasAnomalous := previous != nil && previous.Anomalous
+		if wasAnomalous {
+			score.Anomalous = math.Abs(score.Score) >= d.threshold*recoveryFraction
+			score.Since = previous.Since
+		} else {
+			score.Anomalous = math.Abs(score.Score) >= d.threshold
+			if score.Anomalous {
+				since := now
+				score.Since = &since
+			}
+		}
+
+		switch {
+		case score.Anomalous && !wasAnomalous:
+			transitions = append(transitions, Transition{Score: *score, Started: true})
+		case !score.Anomalous && wasAnomalous:
+			transitions = append(transitions, Transition{Score: *score, Duration: now.Sub(*previous.Since)})
+		}
+		d.scores[key] = score
+	}
+
+	for key, score := range d.scores {
+		if score.Metric == metric && !seen[key] {
+			delete(d.scores, key)
+		}
+	}
+
+	return transitions, nil
+}
+
+// describeEntity fills in the entity of a score from its entity ID label
+func (d *AnomalyDetector) describeEntity(score *Score, entityKey string) {
+	id, err := strconv.ParseInt(entityKey, 10, 64)
+	if err != nil {
+		return
+	}
+	score.EntityID = &id
+
+	entity, exists := d.entities[id]
+	if !exists {
+		if entity, err = d.source.GetEntity(id); err != nil {
+			return
+		}
+		d.entities[id] = entity
+	}
+	score.EntityType = entity.Type
+	score.Entity = entity.Name
+}
+
+// newDistribution returns the median and scaled median absolute deviation of values
+func newDistribution(values []float64) distribution {
+	if len(values) == 0 {
+		return distribution{}
+	}
+
+	med := median(values)
+	deviations := make([]float64, len(values))
+	for i, value := range values {
+		deviations[i] = math.Abs(value - med)
+	}
+	return distribution{
+		median: med,
+		spread: madScale * median(deviations),
+		count:  len(values),
+	}
+}
+
+// median returns the median of values, reordering them
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// smoothed returns the exponentially weighted moving average of the values
+// and the index of the last one
+func smoothed(values []*float64) (float64, int, bool) {
+	var average float64
+	last := -1
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if last < 0 {
+			average = *value
+		} else {
+			average = ewmaAlpha**value + (1-ewmaAlpha)*average
+		}
+		last = i
+	}
+	return average, last, last >= 0
+}
+
+// hourOfWeek returns the local hour of the week, starting on Sunday
+func hourOfWeek(t time.Time) int {
+	t = t.Local()
+	return int(t.Weekday())*24 + t.Hour()
+}
@@ -0,0 +1,264 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor/storage"
+)
+
+const (
+	// History the trend is fitted to
+	forecastHistory = 7 * 24 * time.Hour
+	// Resolution of the fitted values
+	forecastStep = 15 * time.Minute
+	// A series needs this many steps of history before it is forecast
+	minForecastPoints = 8
+
+	// Smoothing of the level and trend, low values favour the long term trend
+	holtAlpha = 0.3
+	holtBeta  = 0.1
+
+	// Resources filling up further out than this are reported as not filling up
+	maxForecastHorizon = 365 * 24 * time.Hour
+)
+
+// ForecastTarget is a stored metric that fills up towards a capacity
+type ForecastTarget struct {
+	Resource string  // disk, inodes or database
+	Metric   string  // Stored metric name
+	Name     string  // Overrides the entity name in forecasts
+	Unit     string  // "%" or "bytes"
+	Capacity float64 // Value at which the resource is full
+}
+
+// Forecast predicts when a resource will be full
+type Forecast struct {
+	Resource     string     `json:"resource"`
+	Metric       string     `json:"metric"`
+	EntityID     *int64     `json:"entity_id,omitempty"`
+	Target       string     `json:"target"` // Mount point, or the monitor database
+	Current      float64    `json:"current"`
+	Capacity     float64    `json:"capacity"`
+	Unit         string     `json:"unit"`
+	GrowthPerDay float64    `json:"growth_per_day"`    // Fitted trend, negative when shrinking
+	FullAt       *time.Time `json:"full_at,omitempty"` // Nil when not filling up within a year
+	HoursToFull  *float64   `json:"hours_to_full,omitempty"`
+	Samples      int        `json:"samples"`
+	Timestamp    time.Time  `json:"timestamp"`
+}
+
+// Name returns the resource and target of the forecast
+func (f *Forecast) Name() string {
+	return fmt.Sprintf("%s %s", f.Resource, f.Target)
+}
+
+// TimeToFull returns how long until the resource is full, false when it isn't filling up
+func (f *Forecast) TimeToFull() (time.Duration, bool) {
+	if f.FullAt == nil {
+		return 0, false
+	}
+	return f.FullAt.Sub(f.Timestamp), true
+}
+
+// Forecaster predicts when disks, inodes and the monitor database fill up
+// by fitting a trend to their stored history
+type Forecaster struct {
+	source MetricSource
+
+	mu        sync.RWMutex
+	forecasts []Forecast
+	entities  map[int64]*storage.Entity
+}
+
+// NewForecaster creates a forecaster
+func NewForecaster(source MetricSource) *Forecaster {
+	return &Forecaster{
+		source:   source,
+		entities: make(map[int64]*storage.Entity),
+	}
+}
+
+// Evaluate refits the trend of every series of the targets. A target that
+// fails to load doesn't stop the others, the first error is returned.
+func (f *Forecaster) Evaluate(targets []ForecastTarget, now time.Time) error {
+	var forecasts []Forecast
+	var firstErr error
+	for _, target := range targets {
+		targetForecasts, err := f.forecast(target, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		forecasts = append(forecasts, targetForecasts...)
+	}
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		a, b := forecasts[i], forecasts[j]
+		if (a.FullAt == nil) != (b.FullAt == nil) {
+			return a.FullAt != nil
+		}
+		if a.FullAt != nil && !a.FullAt.Equal(*b.FullAt) {
+			return a.FullAt.Before(*b.FullAt)
+		}
+		return a.Name() < b.Name()
+	})
+
+	f.mu.Lock()
+	f.forecasts = forecasts
+	f.mu.Unlock()
+
+	return firstErr
+}
+
+// Forecasts returns the latest forecasts, soonest full first
+func (f *Forecaster) Forecasts() []Forecast {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]Forecast(nil), f.forecasts...)
+}
+
+// forecast fits the trend of every series of a target
+func (f *Forecaster) forecast(target ForecastTarget, now time.Time) ([]Forecast, error) {
+	if target.Capacity <= 0 {
+		return nil, nil
+	}
+
+	result, err := f.source.QueryMetrics(&storage.MetricQuery{
+		MetricName:  target.Metric,
+		Since:       now.Add(-forecastHistory),
+		Until:       now,
+		Step:        forecastStep,
+		Aggregation: storage.QueryAggregationAvg,
+		GroupBy:     []string{storage.GroupByEntityID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history of %s: %w", target.Metric, err)
+	}
+
+	var forecasts []Forecast
+	for _, series := range result.Series {
+		// Series that stopped reporting are not forecast
+		level, trend, current, count, last := fitTrend(series.Values)
+		if count < minForecastPoints || last < len(series.Values)-2 {
+			continue
+		}
+
+		forecast := Forecast{
+			Resource:     target.Resource,
+			Metric:       target.Metric,
+			Target:       target.Name,
+			Current:      current,
+			Capacity:     target.Capacity,
+			Unit:         target.Unit,
+			GrowthPerDay: trend * float64(24*time.Hour/forecastStep),
+			Samples:      count,
+			Timestamp:    now,
+		}
+		f.describeEntity(&forecast, series.Labels[storage.GroupByEntityID])
+
+		var timeToFull time.Duration
+		switch {
+		case level >= target.Capacity:
+			timeToFull = 0
+		case trend > 0:
+			timeToFull = time.Duration((target.Capacity - level) / trend * float64(forecastStep))
+			if timeToFull > maxForecastHorizon {
+				timeToFull = -1
+			}
+		default:
+			timeToFull = -1
+		}
+		if timeToFull >= 0 {
+			fullAt := now.Add(timeToFull)
+			hours := timeToFull.Hours()
+			forecast.FullAt = &fullAt
+			forecast.HoursToFull = &hours
+		}
+
+		forecasts = append(forecasts, forecast)
+	}
+
+	return forecasts, nil
+}
+
+// describeEntity fills in the entity of a forecast from its entity ID label
+func (f *Forecaster) describeEntity(forecast *Forecast, entityKey string) {
+	id, err := strconv.ParseInt(entityKey, 10, 64)
+	if err != nil {
+		return
+	}
+	forecast.EntityID = &id
+
+	if forecast.Target != "" {
+		return
+	}
+	entity, exists := f.entities[id]
+	if !exists {
+		if entity, err = f.source.GetEntity(id); err != nil {
+			return
+		}
+		f.entities[id] = entity
+	}
+	forecast.Target = entity.Name
+}
+
+// fitTrend smooths the values with Holt's linear trend method, seeded with
+// the least squares slope. It returns the final level and trend per step,
+// the last value, the number of values and the index of the last one.
+func fitTrend(values []*float64) (level, trend, current float64, count, last int) {
+	// Least squares slope over the steps that have values
+	var sumX, sumY, sumXY, sumXX float64
+	last = -1
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		x := float64(i)
+		sumX += x
+		sumY += *value
+		sumXY += x * *value
+		sumXX += x * x
+		count++
+		last = i
+		current = *value
+	}
+	if count < 2 {
+		return current, 0, current, count, last
+	}
+	n := float64(count)
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		trend = (n*sumXY - sumX*sumY) / denominator
+	}
+
+	started := false
+	for _, value := range values[:last+1] {
+		if !started {
+			if value != nil {
+				level, started = *value, true
+			}
+			continue
+		}
+
+		// Steps without values follow the trend
+		predicted := level + trend
+		if value == nil {
+			level = predicted
+			continue
+		}
+		newLevel := holtAlpha**value + (1-holtAlpha)*predicted
+		trend = holtBeta*(newLevel-level) + (1-holtBeta)*trend
+		level = newLevel
+	}
+
+	if math.IsNaN(level) || math.IsNaN(trend) {
+		return current, 0, current, count, last
+	}
+	return level, trend, current, count, last
+}
@@ -46,16 +46,47 @@ type AlertRuleConfig struct {
 
 // AlertConditionsConfig represents condition configuration from YAML
 type AlertConditionsConfig struct {
-	CPUThreshold    *float64 `yaml:"cpu_threshold,omitempty"`
-	MemoryThreshold *float64 `yaml:"memory_threshold,omitempty"`
-	DiskThreshold   *float64 `yaml:"disk_threshold,omitempty"`
-	LoadThreshold   *float64 `yaml:"load_threshold,omitempty"`
-	ServiceName     string   `yaml:"service_name,omitempty"`
-	ServiceStatus   string   `yaml:"service_status,omitempty"`
-	HTTPEndpoint    string   `yaml:"http_endpoint,omitempty"`
-	ResponseTimeout string   `yaml:"response_timeout,omitempty"`
-	ExpectedStatus  int      `yaml:"expected_status,omitempty"`
-	Duration        string   `yaml:"duration,omitempty"`
+	CPUThreshold            *float64 `yaml:"cpu_threshold,omitempty"`
+	MemoryThreshold         *float64 `yaml:"memory_threshold,omitempty"`
+	DiskThreshold           *float64 `yaml:"disk_threshold,omitempty"`
+	LoadThreshold           *float64 `yaml:"load_threshold,omitempty"`
+	IODevice                string   `yaml:"io_device,omitempty"`
+	IOUtilThreshold         *float64 `yaml:"io_util_threshold,omitempty"`
+	IOAwaitThreshold        *float64 `yaml:"io_await_threshold,omitempty"`
+	CPUPressureThreshold    *float64 `yaml:"cpu_pressure_threshold,omitempty"`
+	MemoryPressureThreshold *float64 `yaml:"memory_pressure_threshold,omitempty"`
+	IOPressureThreshold     *float64 `yaml:"io_pressure_threshold,omitempty"`
+	PredictFullWithin       string   `yaml:"predict_full_within,omitempty"`
+	PredictResource         string   `yaml:"predict_resource,omitempty"`
+	PredictTarget           string   `yaml:"predict_target,omitempty"`
+	ServiceName             string   `yaml:"service_name,omitempty"`
+	ServiceStatus           string   `yaml:"service_status,omitempty"`
+	HTTPEndpoint            string   `yaml:"http_endpoint,omitempty"`
+	ResponseTimeout         string   `yaml:"response_timeout,omitempty"`
+	ExpectedStatus          int      `yaml:"expected_status,omitempty"`
+	ProbeName               string   `yaml:"probe_name,omitempty"`
+	PacketLossThreshold     *float64 `yaml:"packet_loss_threshold,omitempty"`
+	CertExpiryDays          *int     `yaml:"cert_expiry_days,omitempty"`
+	PM2App                  string   `yaml:"pm2_app,omitempty"`
+	RestartThreshold        *int     `yaml:"restart_threshold,omitempty"`
+	SupervisorProgram       string   `yaml:"supervisor_program,omitempty"`
+	SupervisorStates        []string `yaml:"supervisor_states,omitempty"`
+	LogPattern              string   `yaml:"log_pattern,omitempty"`
+	LogUnit                 string   `yaml:"log_unit,omitempty"`
+	MatchThreshold          *int     `yaml:"match_threshold,omitempty"`
+	MatchWindow             string   `yaml:"match_window,omitempty"`
+	Collector               string   `yaml:"collector,omitempty"`
+	StaleAfter              string   `yaml:"stale_after,omitempty"`
+	AnomalyMetric           string   `yaml:"anomaly_metric,omitempty"`
+	AnomalyEntity           string   `yaml:"anomaly_entity,omitempty"`
+	AnomalyScore            *float64 `yaml:"anomaly_score,omitempty"`
+	AnomalyDirection        string   `yaml:"anomaly_direction,omitempty"`
+	SLOName                 string   `yaml:"slo_name,omitempty"`
+	BurnRate                *float64 `yaml:"burn_rate,omitempty"`
+	LongWindow              string   `yaml:"long_window,omitempty"`
+	ShortWindow             string   `yaml:"short_window,omitempty"`
+	BudgetRemaining         *float64 `yaml:"budget_remaining,omitempty"`
+	Duration                string   `yaml:"duration,omitempty"`
 }
 
 // LoadConfig loads alert configuration from a YAML file
@@ -164,6 +195,20 @@ func LoadRules(configPath string) ([]*AlertRule, error) {
 	return rules, nil
 }
 
+// ValidateRule checks a single rule configuration and returns the rule it produces
+func ValidateRule(ruleConfig *AlertRuleConfig) (*AlertRule, error) {
+	if ruleConfig.ID == "" {
+		return nil, fmt.Errorf("rule ID is required")
+	}
+	if ruleConfig.Name == "" {
+		return nil, fmt.Errorf("rule name is required")
+	}
+	if ruleConfig.Type == "" {
+		return nil, fmt.Errorf("rule type is required")
+	}
+	return convertRule(ruleConfig)
+}
+
 // convertRule converts a rule configuration to an AlertRule
 func convertRule(ruleConfig *AlertRuleConfig) (*AlertRule, error) {
 	rule := &AlertRule{
@@ -217,14 +262,40 @@ func convertRule(ruleConfig *AlertRuleConfig) (*AlertRule, error) {
 // convertConditions converts condition configuration to AlertConditions
 func convertConditions(condConfig *AlertConditionsConfig) (*AlertConditions, error) {
 	conditions := &AlertConditions{
-		CPUThreshold:    condConfig.CPUThreshold,
-		MemoryThreshold: condConfig.MemoryThreshold,
-		DiskThreshold:   condConfig.DiskThreshold,
-		LoadThreshold:   condConfig.LoadThreshold,
-		ServiceName:     condConfig.ServiceName,
-		ServiceStatus:   condConfig.ServiceStatus,
-		HTTPEndpoint:    condConfig.HTTPEndpoint,
-		ExpectedStatus:  condConfig.ExpectedStatus,
+		CPUThreshold:            condConfig.CPUThreshold,
+		MemoryThreshold:         condConfig.MemoryThreshold,
+		DiskThreshold:           condConfig.DiskThreshold,
+		LoadThreshold:           condConfig.LoadThreshold,
+		IODevice:                condConfig.IODevice,
+		IOUtilThreshold:         condConfig.IOUtilThreshold,
+		IOAwaitThreshold:        condConfig.IOAwaitThreshold,
+		CPUPressureThreshold:    condConfig.CPUPressureThreshold,
+		MemoryPressureThreshold: condConfig.MemoryPressureThreshold,
+		IOPressureThreshold:     condConfig.IOPressureThreshold,
+		PredictResource:         condConfig.PredictResource,
+		PredictTarget:           condConfig.PredictTarget,
+		ServiceName:             condConfig.ServiceName,
+		ServiceStatus:           condConfig.ServiceStatus,
+		HTTPEndpoint:            condConfig.HTTPEndpoint,
+		ExpectedStatus:          condConfig.ExpectedStatus,
+		ProbeName:               condConfig.ProbeName,
+		PacketLossThreshold:     condConfig.PacketLossThreshold,
+		CertExpiryDays:          condConfig.CertExpiryDays,
+		PM2App:                  condConfig.PM2App,
+		RestartThreshold:        condConfig.RestartThreshold,
+		SupervisorProgram:       condConfig.SupervisorProgram,
+		SupervisorStates:        condConfig.SupervisorStates,
+		LogPattern:              condConfig.LogPattern,
+		LogUnit:                 condConfig.LogUnit,
+		MatchThreshold:          condConfig.MatchThreshold,
+		Collector:               condConfig.Collector,
+		AnomalyMetric:           condConfig.AnomalyMetric,
+		AnomalyEntity:           condConfig.AnomalyEntity,
+		AnomalyScore:            condConfig.AnomalyScore,
+		AnomalyDirection:        condConfig.AnomalyDirection,
+		SLOName:                 condConfig.SLOName,
+		BurnRate:                condConfig.BurnRate,
+		BudgetRemaining:         condConfig.BudgetRemaining,
 	}
 
 	// Parse duration
@@ -245,6 +316,63 @@ func convertConditions(condConfig *AlertConditionsConfig) (*AlertConditions, err
 		conditions.ResponseTimeout = timeout
 	}
 
+	// Parse log match window
+	if condConfig.MatchWindow != "" {
+		window, err := time.ParseDuration(condConfig.MatchWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match_window: %v", err)
+		}
+		conditions.MatchWindow = window
+	}
+
+	// Parse collector stale threshold
+	if condConfig.StaleAfter != "" {
+		staleAfter, err := time.ParseDuration(condConfig.StaleAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stale_after: %v", err)
+		}
+		conditions.StaleAfter = staleAfter
+	}
+
+	// Parse capacity forecast horizon
+	if condConfig.PredictFullWithin != "" {
+		within, err := time.ParseDuration(condConfig.PredictFullWithin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid predict_full_within: %v", err)
+		}
+		conditions.PredictFullWithin = within
+	}
+
+	// Parse SLO burn rate windows
+	if condConfig.LongWindow != "" {
+		window, err := time.ParseDuration(condConfig.LongWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid long_window: %v", err)
+		}
+		conditions.LongWindow = window
+	}
+	if condConfig.ShortWindow != "" {
+		window, err := time.ParseDuration(condConfig.ShortWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid short_window: %v", err)
+		}
+		conditions.ShortWindow = window
+	}
+
+	// Check forecast resource
+	switch conditions.PredictResource {
+	case "", "disk", "inodes", "database":
+	default:
+		return nil, fmt.Errorf("invalid predict_resource: %s", conditions.PredictResource)
+	}
+
+	// Check anomaly direction
+	switch conditions.AnomalyDirection {
+	case "", "up", "down", "both":
+	default:
+		return nil, fmt.Errorf("invalid anomaly_direction: %s", conditions.AnomalyDirection)
+	}
+
 	return conditions, nil
 }
 
@@ -3,7 +3,10 @@ package alerts
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,11 +48,12 @@ func (w *EmailNotifierWrapper) Send(alert *Alert) error {
 // NewAlertManager creates a new alert manager instance
 func NewAlertManager(config *Config) *AlertManager {
 	am := &AlertManager{
-		rules:        make(map[string]*AlertRule),
-		activeAlerts: make(map[string]*Alert),
-		alertHistory: make([]*Alert, 0),
-		notifiers:    make([]Notifier, 0),
-		config:       config,
+		rules:         make(map[string]*AlertRule),
+		activeAlerts:  make(map[string]*Alert),
+		alertHistory:  make([]*Alert, 0),
+		notifiers:     make([]Notifier, 0),
+		config:        config,
+		notifierStats: make(map[string]*NotifierStats),
 	}
 
 	// Initialize notifiers based on configuration
@@ -106,31 +110,104 @@ func (am *AlertManager) initializeNotifiers() {
 
 // AddRule adds a new alert rule
 func (am *AlertManager) AddRule(rule *AlertRule) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
 	am.rules[rule.ID] = rule
 }
 
 // RemoveRule removes an alert rule
 func (am *AlertManager) RemoveRule(ruleID string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
 	delete(am.rules, ruleID)
 }
 
-// GetActiveAlerts returns all currently active alerts
+// ReplaceRules atomically swaps the rule set, resolving active alerts whose
+// rule was removed or disabled
+func (am *AlertManager) ReplaceRules(rules []*AlertRule) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.rules = make(map[string]*AlertRule, len(rules))
+	for _, rule := range rules {
+		am.rules[rule.ID] = rule
+	}
+
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	now := time.Now()
+	for alertID, alert := range am.activeAlerts {
+		if rule, exists := am.rules[alert.RuleID]; exists && rule.Enabled {
+			continue
+		}
+
+		alert.Status = StatusResolved
+		alert.EndsAt = &now
+		am.addToHistory(alert)
+		delete(am.activeAlerts, alertID)
+
+		log.Printf("Alert resolved after rule removal: %s", alert.Name)
+	}
+}
+
+// GetConfig returns the alert system configuration
+func (am *AlertManager) GetConfig() *Config {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.config
+}
+
+// SetConfig replaces the alert system configuration and rebuilds the notifiers
+func (am *AlertManager) SetConfig(config *Config) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.config = config
+	am.notifiers = make([]Notifier, 0)
+	am.initializeNotifiers()
+}
+
+// GetActiveAlerts returns copies of all currently active alerts
 func (am *AlertManager) GetActiveAlerts() []*Alert {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
 	alerts := make([]*Alert, 0, len(am.activeAlerts))
 	for _, alert := range am.activeAlerts {
-		alerts = append(alerts, alert)
+		alerts = append(alerts, copyAlert(alert))
 	}
 	return alerts
 }
 
-// GetAlertHistory returns recent alert history
+// GetAlertHistory returns copies of the recent alert history
 func (am *AlertManager) GetAlertHistory() []*Alert {
-	return am.alertHistory
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	history := make([]*Alert, 0, len(am.alertHistory))
+	for _, alert := range am.alertHistory {
+		history = append(history, copyAlert(alert))
+	}
+	return history
+}
+
+// copyAlert copies an alert so callers can read it while evaluations update the original
+func copyAlert(alert *Alert) *Alert {
+	copied := *alert
+	copied.SentTo = append([]string(nil), alert.SentTo...)
+	return &copied
 }
 
 // EvaluateRules evaluates all rules against current metrics
 func (am *AlertManager) EvaluateRules(ctx *EvaluationContext) error {
+	// Hold the read lock for the whole pass so a reload never mixes rule sets
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	am.alertsMu.Lock()
 	am.lastEvaluation = ctx.CurrentTime
+	am.alertsMu.Unlock()
 
 	var wg sync.WaitGroup
 	for _, rule := range am.rules {
@@ -149,15 +226,23 @@ func (am *AlertManager) EvaluateRules(ctx *EvaluationContext) error {
 	return nil
 }
 
-// evaluateRule evaluates a single rule against the current context
+// evaluateRule evaluates a single rule against the current context. Conditions
+// are checked concurrently, alert state changes under alertsMu and
+// notifications are sent outside it so slow notifiers do not block the API.
 func (am *AlertManager) evaluateRule(rule *AlertRule, ctx *EvaluationContext) {
 	alertID := fmt.Sprintf("%s-%s", rule.ID, "current")
 
 	// Check if condition is met
 	conditionMet, details := am.checkCondition(rule, ctx)
+	var message string
+	if conditionMet {
+		message = am.generateAlertMessage(rule, details)
+	}
 
+	am.alertsMu.Lock()
 	existingAlert, exists := am.activeAlerts[alertID]
 
+	var notify *Alert
 	if conditionMet {
 		if !exists {
 			// Create new alert
@@ -167,7 +252,7 @@ func (am *AlertManager) evaluateRule(rule *AlertRule, ctx *EvaluationContext) {
 				Type:              rule.Type,
 				Severity:          rule.Severity,
 				Status:            StatusFiring,
-				Message:           am.generateAlertMessage(rule, details),
+				Message:           message,
 				Details:           details,
 				Labels:            rule.Labels,
 				Annotations:       rule.Annotations,
@@ -178,17 +263,17 @@ func (am *AlertManager) evaluateRule(rule *AlertRule, ctx *EvaluationContext) {
 			}
 
 			am.activeAlerts[alertID] = alert
-			am.sendNotifications(alert, rule)
+			notify = alert
 
 			log.Printf("Alert fired: %s - %s", alert.Name, alert.Message)
 		} else {
 			// Update existing alert
 			existingAlert.Details = details
-			existingAlert.Message = am.generateAlertMessage(rule, details)
+			existingAlert.Message = message
 
 			// Check if we should send another notification
 			if am.shouldSendNotification(existingAlert, rule) {
-				am.sendNotifications(existingAlert, rule)
+				notify = existingAlert
 			}
 		}
 	} else {
@@ -204,6 +289,25 @@ func (am *AlertManager) evaluateRule(rule *AlertRule, ctx *EvaluationContext) {
 			log.Printf("Alert resolved: %s", existingAlert.Name)
 		}
 	}
+
+	// Count the notification before sending it so the next pass sees it
+	var sending *Alert
+	if notify != nil {
+		now := time.Now()
+		notify.NotificationsSent++
+		notify.LastSent = &now
+		sending = copyAlert(notify)
+	}
+	am.alertsMu.Unlock()
+
+	if sending == nil {
+		return
+	}
+	sentTo := am.sendNotifications(sending)
+
+	am.alertsMu.Lock()
+	notify.SentTo = append(notify.SentTo, sentTo...)
+	am.alertsMu.Unlock()
 }
 
 // checkCondition evaluates whether an alert condition is met
@@ -217,6 +321,20 @@ func (am *AlertManager) checkCondition(rule *AlertRule, ctx *EvaluationContext)
 		return am.checkServiceCondition(rule, ctx, details)
 	case AlertTypeHTTP:
 		return am.checkHTTPCondition(rule, ctx, details)
+	case AlertTypeProbe:
+		return am.checkProbeCondition(rule, ctx, details)
+	case AlertTypePM2:
+		return am.checkPM2Condition(rule, ctx, details)
+	case AlertTypeSupervisor:
+		return am.checkSupervisorCondition(rule, ctx, details)
+	case AlertTypeLog:
+		return am.checkLogCondition(rule, ctx, details)
+	case AlertTypeCollector:
+		return am.checkCollectorCondition(rule, ctx, details)
+	case AlertTypeAnomaly:
+		return am.checkAnomalyCondition(rule, ctx, details)
+	case AlertTypeSLO:
+		return am.checkSLOCondition(rule, ctx, details)
 	default:
 		return false, details
 	}
@@ -274,9 +392,115 @@ func (am *AlertManager) checkSystemCondition(rule *AlertRule, ctx *EvaluationCon
 		}
 	}
 
+	// Disk I/O checks use per-device metrics when a device is given
+	ioSuffix := ""
+	if conditions.IODevice != "" {
+		ioSuffix = ":" + conditions.IODevice
+		details["device"] = conditions.IODevice
+	}
+
+	if conditions.IOUtilThreshold != nil {
+		if ioMetric, exists := ctx.SystemMetrics["io_util"+ioSuffix]; exists {
+			details["io_util"] = ioMetric.Value
+			if ioMetric.Value > *conditions.IOUtilThreshold {
+				details["threshold"] = *conditions.IOUtilThreshold
+				details["metric"] = "IO utilisation"
+				details["device"] = ioMetric.Labels["device"]
+				return true, details
+			}
+		}
+	}
+
+	if conditions.IOAwaitThreshold != nil {
+		if ioMetric, exists := ctx.SystemMetrics["io_await"+ioSuffix]; exists {
+			details["io_await"] = ioMetric.Value
+			if ioMetric.Value > *conditions.IOAwaitThreshold {
+				details["threshold"] = *conditions.IOAwaitThreshold
+				details["metric"] = "IO await"
+				details["device"] = ioMetric.Labels["device"]
+				return true, details
+			}
+		}
+	}
+
+	// Pressure stall checks
+	pressureChecks := []struct {
+		threshold *float64
+		key       string
+		metric    string
+	}{
+		{conditions.CPUPressureThreshold, "cpu_pressure", "CPU pressure"},
+		{conditions.MemoryPressureThreshold, "memory_pressure", "Memory pressure"},
+		{conditions.IOPressureThreshold, "io_pressure", "IO pressure"},
+	}
+	for _, check := range pressureChecks {
+		if check.threshold == nil {
+			continue
+		}
+		if pressureMetric, exists := ctx.SystemMetrics[check.key]; exists {
+			details[check.key] = pressureMetric.Value
+			if pressureMetric.Value > *check.threshold {
+				details["threshold"] = *check.threshold
+				details["metric"] = check.metric
+				return true, details
+			}
+		}
+	}
+
+	// Capacity forecast check
+	if conditions.PredictFullWithin > 0 && am.checkForecastCondition(&conditions, ctx, details) {
+		return true, details
+	}
+
 	return false, details
 }
 
+// checkForecastCondition checks whether a resource is forecast to fill up within the horizon
+func (am *AlertManager) checkForecastCondition(conditions *AlertConditions, ctx *EvaluationContext, details map[string]interface{}) bool {
+	var filling []string
+	var soonest *ForecastState
+	for i := range ctx.Forecasts {
+		forecast := &ctx.Forecasts[i]
+		if conditions.PredictResource != "" && forecast.Resource != conditions.PredictResource {
+			continue
+		}
+		if conditions.PredictTarget != "" && forecast.Target != conditions.PredictTarget {
+			continue
+		}
+		if forecast.FullAt == nil || forecast.FullAt.Sub(ctx.CurrentTime) > conditions.PredictFullWithin {
+			continue
+		}
+
+		filling = append(filling, fmt.Sprintf("%s %s", forecast.Resource, forecast.Target))
+		if soonest == nil || forecast.FullAt.Before(*soonest.FullAt) {
+			soonest = forecast
+		}
+	}
+
+	if soonest == nil {
+		return false
+	}
+
+	fullIn := soonest.FullAt.Sub(ctx.CurrentTime)
+	if fullIn < 0 {
+		fullIn = 0
+	}
+
+	sort.Strings(filling)
+	details["metric"] = "Capacity forecast"
+	details["filling_up"] = filling
+	details["resource"] = soonest.Resource
+	details["target"] = soonest.Target
+	details["current"] = soonest.Current
+	details["capacity"] = soonest.Capacity
+	details["unit"] = soonest.Unit
+	details["growth_per_day"] = soonest.GrowthPerDay
+	details["full_at"] = soonest.FullAt.Format(time.RFC3339)
+	details["full_in"] = fullIn.Round(time.Minute).String()
+	details["predict_full_within"] = conditions.PredictFullWithin.String()
+	return true
+}
+
 // checkServiceCondition checks service status
 func (am *AlertManager) checkServiceCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
 	conditions := rule.Conditions
@@ -314,6 +538,9 @@ func (am *AlertManager) checkHTTPCondition(rule *AlertRule, ctx *EvaluationConte
 			// Check if endpoint is failing
 			if !result.Success {
 				details["error"] = result.Error
+				if result.FailedStep != "" {
+					details["failed_step"] = result.FailedStep
+				}
 				return true, details
 			}
 
@@ -334,13 +561,455 @@ func (am *AlertManager) checkHTTPCondition(rule *AlertRule, ctx *EvaluationConte
 	return false, details
 }
 
+// checkProbeCondition checks TCP, DNS, ICMP and TLS probes for failures and thresholds
+func (am *AlertManager) checkProbeCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	// Visit probes by name so the details describe the same probe on every evaluation
+	names := make([]string, 0, len(ctx.Probes))
+	for name := range ctx.Probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failing []string
+	for _, name := range names {
+		probe := ctx.Probes[name]
+		if conditions.ProbeName != "" && name != conditions.ProbeName {
+			continue
+		}
+
+		var reason string
+		thresholds := make(map[string]interface{})
+		switch {
+		case !probe.Success:
+			reason = probe.Error
+		case conditions.PacketLossThreshold != nil && probe.Type == "icmp" && probe.PacketLoss > *conditions.PacketLossThreshold:
+			reason = fmt.Sprintf("packet loss %.0f%% exceeds threshold of %.0f%%", probe.PacketLoss, *conditions.PacketLossThreshold)
+			thresholds["packet_loss_threshold"] = *conditions.PacketLossThreshold
+		case conditions.ResponseTimeout > 0 && probe.ResponseTime > conditions.ResponseTimeout:
+			reason = fmt.Sprintf("response time %dms exceeds threshold of %dms", probe.ResponseTime.Milliseconds(), conditions.ResponseTimeout.Milliseconds())
+			thresholds["timeout_threshold"] = conditions.ResponseTimeout.Milliseconds()
+		case conditions.CertExpiryDays != nil && probe.CertExpiry != nil &&
+			probe.CertExpiry.Sub(ctx.CurrentTime) < time.Duration(*conditions.CertExpiryDays)*24*time.Hour:
+			days := int(probe.CertExpiry.Sub(ctx.CurrentTime).Hours() / 24)
+			reason = fmt.Sprintf("certificate expires in %d days", days)
+			thresholds["cert_expiry_days"] = days
+		default:
+			continue
+		}
+
+		// Details describe the first failing probe, failing_probes names the rest
+		if len(failing) == 0 {
+			for key, value := range thresholds {
+				details[key] = value
+			}
+			details["probe"] = name
+			details["probe_type"] = probe.Type
+			details["target"] = probe.Target
+			details["success"] = probe.Success
+			details["response_time"] = probe.ResponseTime.Milliseconds()
+			details["reason"] = reason
+			if probe.Type == "icmp" {
+				details["packet_loss"] = probe.PacketLoss
+			}
+		}
+		failing = append(failing, name)
+	}
+
+	if len(failing) > 0 {
+		details["failing_probes"] = failing
+		return true, details
+	}
+
+	return false, details
+}
+
+// checkPM2Condition checks PM2 applications for crash loops and failed instances
+func (am *AlertManager) checkPM2Condition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	threshold := 5
+	if conditions.RestartThreshold != nil {
+		threshold = *conditions.RestartThreshold
+	}
+
+	// Visit apps by name so the details describe the same app on every evaluation
+	names := make([]string, 0, len(ctx.PM2Apps))
+	for name := range ctx.PM2Apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failing []string
+	for _, name := range names {
+		app := ctx.PM2Apps[name]
+		if conditions.PM2App != "" && name != conditions.PM2App {
+			continue
+		}
+
+		crashLooping := threshold > 0 && app.RecentRestarts >= threshold
+		errored := app.Status == "errored"
+		if !crashLooping && !errored {
+			continue
+		}
+
+		// Details describe the first failing app, the message names the rest
+		if len(failing) == 0 {
+			details["pm2_app"] = name
+			details["status"] = app.Status
+			details["recent_restarts"] = app.RecentRestarts
+			details["restarts"] = app.Restarts
+			details["restart_threshold"] = threshold
+			details["crash_loop"] = crashLooping
+		}
+		failing = append(failing, name)
+	}
+
+	if len(failing) > 0 {
+		details["failing_apps"] = failing
+		return true, details
+	}
+
+	return false, details
+}
+
+// checkSupervisorCondition checks supervisord processes for failed states
+func (am *AlertManager) checkSupervisorCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	states := conditions.SupervisorStates
+	if len(states) == 0 {
+		states = []string{"FATAL", "BACKOFF"}
+	}
+
+	// Visit programs by name so the details describe the same program on every evaluation
+	names := make([]string, 0, len(ctx.Supervisor))
+	for name := range ctx.Supervisor {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failing []string
+	var sites []string
+	for _, name := range names {
+		proc := ctx.Supervisor[name]
+		if conditions.SupervisorProgram != "" && name != conditions.SupervisorProgram && proc.Group != conditions.SupervisorProgram {
+			continue
+		}
+
+		matched := false
+		for _, state := range states {
+			if strings.EqualFold(proc.State, state) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		// Details describe the first failing program, the message names the rest
+		if len(failing) == 0 {
+			details["program"] = name
+			details["group"] = proc.Group
+			details["state"] = proc.State
+			details["exit_status"] = proc.ExitStatus
+			details["restarts"] = proc.Restarts
+			if proc.SpawnError != "" {
+				details["spawn_error"] = proc.SpawnError
+			}
+
+			// Tie queue worker alerts to the owning site entity
+			if proc.Site != "" {
+				details["site"] = proc.Site
+				details["entity_type"] = "site"
+				details["entity_name"] = proc.Site
+			}
+		}
+		failing = append(failing, name)
+		if proc.Site != "" {
+			sites = append(sites, proc.Site)
+		}
+	}
+
+	if len(failing) > 0 {
+		details["failing_programs"] = failing
+		if len(sites) > 0 {
+			sort.Strings(sites)
+			details["sites"] = sites
+		}
+		return true, details
+	}
+
+	return false, details
+}
+
+// checkLogCondition checks how often journal patterns matched within the window
+func (am *AlertManager) checkLogCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	threshold := 1
+	if conditions.MatchThreshold != nil {
+		threshold = *conditions.MatchThreshold
+	}
+	window := conditions.MatchWindow
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	since := ctx.CurrentTime.Add(-window)
+
+	matches := 0
+	var last *LogEventState
+	for i := range ctx.LogEvents {
+		event := &ctx.LogEvents[i]
+		if event.Timestamp.Before(since) {
+			continue
+		}
+		if conditions.LogPattern != "" && event.Pattern != conditions.LogPattern {
+			continue
+		}
+		if conditions.LogUnit != "" && event.Unit != conditions.LogUnit {
+			continue
+		}
+
+		matches++
+		if last == nil || event.Timestamp.After(last.Timestamp) {
+			last = event
+		}
+	}
+
+	details["matches"] = matches
+	details["match_threshold"] = threshold
+	details["match_window"] = window.String()
+
+	if last != nil && matches >= threshold {
+		details["pattern"] = last.Pattern
+		details["unit"] = last.Unit
+		details["severity"] = last.Severity
+		details["last_message"] = last.Message
+		return true, details
+	}
+
+	return false, details
+}
+
+// checkCollectorCondition checks collector loops for missing data
+func (am *AlertManager) checkCollectorCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	// Visit collectors by name so the details describe the same collector on every evaluation
+	names := make([]string, 0, len(ctx.Collectors))
+	for name := range ctx.Collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var stale []string
+	for _, name := range names {
+		collector := ctx.Collectors[name]
+		if conditions.Collector != "" && name != conditions.Collector {
+			continue
+		}
+
+		threshold := conditions.StaleAfter
+		if threshold <= 0 {
+			// Loops without an interval only produce data when something happens
+			if collector.Interval <= 0 {
+				continue
+			}
+			threshold = 3 * collector.Interval
+		}
+
+		since := collector.StartedAt
+		if collector.LastSuccess != nil {
+			since = *collector.LastSuccess
+		}
+		age := ctx.CurrentTime.Sub(since)
+		if age <= threshold {
+			continue
+		}
+
+		// Details describe the first stale collector, only with its own error
+		if len(stale) == 0 {
+			details["collector"] = name
+			details["stale_for"] = age.Round(time.Second).String()
+			details["stale_after"] = threshold.String()
+			if collector.LastError != "" {
+				details["last_error"] = collector.LastError
+			}
+		}
+		stale = append(stale, name)
+	}
+
+	if len(stale) > 0 {
+		details["stale_collectors"] = stale
+		return true, details
+	}
+
+	return false, details
+}
+
+// checkAnomalyCondition checks stored metrics for values that are unusual for the hour of the week
+func (am *AlertManager) checkAnomalyCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+
+	var anomalous []string
+	var worst *AnomalyState
+	var worstName string
+	for i := range ctx.Anomalies {
+		anomaly := &ctx.Anomalies[i]
+		if conditions.AnomalyMetric != "" && anomaly.Metric != conditions.AnomalyMetric {
+			continue
+		}
+		if conditions.AnomalyEntity != "" && anomaly.Entity != conditions.AnomalyEntity {
+			continue
+		}
+
+		threshold := anomaly.Threshold
+		if conditions.AnomalyScore != nil {
+			threshold = *conditions.AnomalyScore
+		}
+
+		var matched bool
+		switch conditions.AnomalyDirection {
+		case "up":
+			matched = anomaly.Score >= threshold
+		case "down":
+			matched = anomaly.Score <= -threshold
+		default:
+			matched = math.Abs(anomaly.Score) >= threshold
+		}
+		if !matched {
+			continue
+		}
+
+		name := anomaly.Metric
+		if anomaly.Entity != "" {
+			name = fmt.Sprintf("%s on %s", anomaly.Metric, anomaly.Entity)
+		}
+		anomalous = append(anomalous, name)
+		if worst == nil || math.Abs(anomaly.Score) > math.Abs(worst.Score) {
+			worst, worstName = anomaly, name
+			details["anomaly_threshold"] = threshold
+		}
+	}
+
+	if worst == nil {
+		return false, details
+	}
+
+	sort.Strings(anomalous)
+	details["anomalies"] = anomalous
+	details["series"] = worstName
+	details["metric"] = worst.Metric
+	details["value"] = worst.Value
+	details["expected"] = worst.Expected
+	details["score"] = math.Round(worst.Score*10) / 10
+	details["bucket"] = worst.Bucket
+	if worst.Entity != "" {
+		details["entity_type"] = worst.EntityType
+		details["entity_name"] = worst.Entity
+	}
+	if worst.Since != nil {
+		details["since"] = *worst.Since
+	}
+	return true, details
+}
+
+// checkSLOCondition checks SLOs for error budgets burning too fast over both
+// windows, so the alert fires quickly and resolves once the short window recovers
+func (am *AlertManager) checkSLOCondition(rule *AlertRule, ctx *EvaluationContext, details map[string]interface{}) (bool, map[string]interface{}) {
+	conditions := rule.Conditions
+	long, short := conditions.BurnRateWindows()
+
+	var failing []string
+	var worst *SLOState
+	var worstBurn float64
+	for i := range ctx.SLOs {
+		slo := &ctx.SLOs[i]
+		if conditions.SLOName != "" && slo.Name != conditions.SLOName {
+			continue
+		}
+
+		var burning, exhausted bool
+		longBurn, hasLong := slo.BurnRates[long]
+		shortBurn, hasShort := slo.BurnRates[short]
+		if conditions.BurnRate != nil && hasLong && hasShort {
+			burning = longBurn >= *conditions.BurnRate && shortBurn >= *conditions.BurnRate
+		}
+		if conditions.BudgetRemaining != nil && slo.BudgetRemaining != nil {
+			exhausted = *slo.BudgetRemaining < *conditions.BudgetRemaining
+		}
+		if !burning && !exhausted {
+			continue
+		}
+
+		failing = append(failing, slo.Name)
+		if worst == nil || longBurn > worstBurn {
+			worst, worstBurn = slo, longBurn
+			details["burning"] = burning
+			if hasLong && hasShort {
+				details["burn_rate_long"] = math.Round(longBurn*10) / 10
+				details["burn_rate_short"] = math.Round(shortBurn*10) / 10
+			} else {
+				delete(details, "burn_rate_long")
+				delete(details, "burn_rate_short")
+			}
+		}
+	}
+
+	if worst == nil {
+		return false, details
+	}
+
+	sort.Strings(failing)
+	details["failing_slos"] = failing
+	details["slo"] = worst.Name
+	details["entity_type"] = "site"
+	details["entity_name"] = worst.Check
+	details["objective"] = worst.Objective
+	details["long_window"] = long.String()
+	details["short_window"] = short.String()
+	if conditions.BurnRate != nil {
+		details["burn_rate_threshold"] = *conditions.BurnRate
+	}
+	if worst.BudgetRemaining != nil {
+		details["budget_remaining"] = math.Round(*worst.BudgetRemaining*10) / 10
+	}
+	return true, details
+}
+
 // generateAlertMessage creates a human-readable alert message
 func (am *AlertManager) generateAlertMessage(rule *AlertRule, details map[string]interface{}) string {
 	switch rule.Type {
 	case AlertTypeSystem:
+		if filling, ok := details["filling_up"].([]string); ok && len(filling) > 0 {
+			unit, _ := details["unit"].(string)
+			current, _ := details["current"].(float64)
+			growth, _ := details["growth_per_day"].(float64)
+			message := fmt.Sprintf("%s is forecast to be full in %s (%s used, growing %s per day)",
+				forecastName(details["resource"], details["target"]), details["full_in"],
+				formatCapacity(current, unit), formatCapacity(growth, unit))
+			if len(filling) > 1 {
+				message = fmt.Sprintf("%d resources filling up, the soonest: %s", len(filling), message)
+			}
+			return message
+		}
 		if metric, ok := details["metric"].(string); ok {
 			if threshold, ok := details["threshold"].(float64); ok {
 				if value, ok := details[getMetricKey(metric)].(float64); ok {
+					unit := "%"
+					if getMetricKey(metric) == "io_await" {
+						unit = "ms"
+					}
+					if device, ok := details["device"].(string); ok && device != "" {
+						metric = fmt.Sprintf("%s on %s", metric, device)
+					}
+					if unit != "%" {
+						return fmt.Sprintf("%s is %.1f%s, exceeding threshold of %.1f%s",
+							metric, value, unit, threshold, unit)
+					}
 					return fmt.Sprintf("%s is %.1f%%, exceeding threshold of %.1f%%",
 						metric, value, threshold)
 				}
@@ -367,11 +1036,115 @@ func (am *AlertManager) generateAlertMessage(rule *AlertRule, details map[string
 				}
 			}
 		}
+	case AlertTypeProbe:
+		if probes, ok := details["failing_probes"].([]string); ok && len(probes) > 0 {
+			if len(probes) > 1 {
+				return fmt.Sprintf("Probes failing: %s", strings.Join(probes, ", "))
+			}
+			return fmt.Sprintf("%s probe %s (%s): %s", strings.ToUpper(fmt.Sprint(details["probe_type"])),
+				probes[0], details["target"], details["reason"])
+		}
+	case AlertTypePM2:
+		if apps, ok := details["failing_apps"].([]string); ok && len(apps) > 0 {
+			if len(apps) > 1 {
+				return fmt.Sprintf("PM2 applications unhealthy: %s", strings.Join(apps, ", "))
+			}
+			if crashLoop, ok := details["crash_loop"].(bool); ok && crashLoop {
+				return fmt.Sprintf("PM2 application %s is crash looping (%d restarts recently)",
+					apps[0], details["recent_restarts"])
+			}
+			return fmt.Sprintf("PM2 application %s is %s", apps[0], details["status"])
+		}
+	case AlertTypeLog:
+		if pattern, ok := details["pattern"].(string); ok {
+			if details["matches"] == 1 {
+				return fmt.Sprintf("%s log match for %s: %s", pattern, details["unit"], details["last_message"])
+			}
+			return fmt.Sprintf("%s matched %d times in %s for %s, last: %s",
+				pattern, details["matches"], details["match_window"], details["unit"], details["last_message"])
+		}
+	case AlertTypeSupervisor:
+		if programs, ok := details["failing_programs"].([]string); ok && len(programs) > 0 {
+			if len(programs) > 1 {
+				return fmt.Sprintf("Supervisor programs failing: %s", strings.Join(programs, ", "))
+			}
+			if site, ok := details["site"].(string); ok {
+				return fmt.Sprintf("Queue worker %s for site %s is %s", programs[0], site, details["state"])
+			}
+			return fmt.Sprintf("Supervisor program %s is %s", programs[0], details["state"])
+		}
+	case AlertTypeCollector:
+		if collectors, ok := details["stale_collectors"].([]string); ok && len(collectors) > 0 {
+			if len(collectors) > 1 {
+				return fmt.Sprintf("Collectors stopped producing data: %s", strings.Join(collectors, ", "))
+			}
+			if lastError, ok := details["last_error"].(string); ok {
+				return fmt.Sprintf("Collector %s has produced no data for %s, last error: %s",
+					collectors[0], details["stale_for"], lastError)
+			}
+			return fmt.Sprintf("Collector %s has produced no data for %s", collectors[0], details["stale_for"])
+		}
+	case AlertTypeAnomaly:
+		if anomalies, ok := details["anomalies"].([]string); ok && len(anomalies) > 0 {
+			message := fmt.Sprintf("%s is %.2f, unusual for %s (expected about %.2f, score %.1f)",
+				details["series"], details["value"], details["bucket"], details["expected"], details["score"])
+			if len(anomalies) > 1 {
+				message = fmt.Sprintf("%d anomalies, the largest: %s", len(anomalies), message)
+			}
+			return message
+		}
+	case AlertTypeSLO:
+		if slos, ok := details["failing_slos"].([]string); ok && len(slos) > 0 {
+			message := fmt.Sprintf("SLO %s (%.2f%%)", details["slo"], details["objective"])
+			if burning, _ := details["burning"].(bool); burning {
+				message = fmt.Sprintf("%s is burning its error budget %.1fx too fast over %s (%.1fx over %s)",
+					message, details["burn_rate_long"], details["long_window"], details["burn_rate_short"], details["short_window"])
+				if remaining, ok := details["budget_remaining"].(float64); ok && remaining > 0 {
+					message = fmt.Sprintf("%s, %.1f%% left", message, remaining)
+				} else if ok {
+					message += ", budget exhausted"
+				}
+			} else if remaining, _ := details["budget_remaining"].(float64); remaining > 0 {
+				message = fmt.Sprintf("%s has %.1f%% of its error budget left", message, remaining)
+			} else {
+				message = fmt.Sprintf("%s has exhausted its error budget (%.1f%%)", message, remaining)
+			}
+			if len(slos) > 1 {
+				message = fmt.Sprintf("%d SLOs at risk (%s), the worst: %s", len(slos), strings.Join(slos, ", "), message)
+			}
+			return message
+		}
 	}
 
 	return fmt.Sprintf("Alert condition met for rule: %s", rule.Name)
 }
 
+// forecastName describes the resource of a capacity forecast
+func forecastName(resource, target interface{}) string {
+	switch resource {
+	case "inodes":
+		return fmt.Sprintf("Inodes on %s", target)
+	case "database":
+		return "Monitor database"
+	default:
+		return fmt.Sprintf("Disk %s", target)
+	}
+}
+
+// formatCapacity formats a forecast amount in its unit
+func formatCapacity(value float64, unit string) string {
+	if unit != "bytes" {
+		return fmt.Sprintf("%.1f%s", value, unit)
+	}
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for math.Abs(value) >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
 // getMetricKey returns the detail key for a metric name
 func getMetricKey(metric string) string {
 	switch metric {
@@ -383,6 +1156,16 @@ func getMetricKey(metric string) string {
 		return "disk_usage"
 	case "Load average":
 		return "load_average"
+	case "IO utilisation":
+		return "io_util"
+	case "IO await":
+		return "io_await"
+	case "CPU pressure":
+		return "cpu_pressure"
+	case "Memory pressure":
+		return "memory_pressure"
+	case "IO pressure":
+		return "io_pressure"
 	default:
 		return "value"
 	}
@@ -405,9 +1188,10 @@ func (am *AlertManager) shouldSendNotification(alert *Alert, rule *AlertRule) bo
 	return true
 }
 
-// sendNotifications sends the alert through all configured notifiers
-func (am *AlertManager) sendNotifications(alert *Alert, rule *AlertRule) {
-	now := time.Now()
+// sendNotifications sends the alert through all configured notifiers,
+// returning the notifiers that delivered it
+func (am *AlertManager) sendNotifications(alert *Alert) []string {
+	var sentTo []string
 
 	log.Printf("DEBUG: Attempting to send notifications for alert: %s", alert.Name)
 	log.Printf("DEBUG: Number of configured notifiers: %d", len(am.notifiers))
@@ -422,22 +1206,55 @@ func (am *AlertManager) sendNotifications(alert *Alert, rule *AlertRule) {
 
 		log.Printf("DEBUG: Sending alert via %s...", notifier.Name())
 		err := notifier.Send(alert)
+		am.recordNotification(notifier.Name(), err)
 		if err != nil {
 			log.Printf("Failed to send alert via %s: %v", notifier.Name(), err)
 			continue
 		}
 
 		log.Printf("DEBUG: Successfully sent alert via %s", notifier.Name())
-		alert.SentTo = append(alert.SentTo, notifier.Name())
+		sentTo = append(sentTo, notifier.Name())
 	}
 
-	alert.NotificationsSent++
-	alert.LastSent = &now
+	log.Printf("DEBUG: Notification attempt completed. Sent to: %v", sentTo)
+	return sentTo
+}
 
-	log.Printf("DEBUG: Notification attempt completed. Sent to: %v", alert.SentTo)
+// recordNotification counts a delivery attempt of a notifier
+func (am *AlertManager) recordNotification(name string, err error) {
+	am.statsMu.Lock()
+	defer am.statsMu.Unlock()
+
+	stats, exists := am.notifierStats[name]
+	if !exists {
+		stats = &NotifierStats{}
+		am.notifierStats[name] = stats
+	}
+
+	now := time.Now()
+	if err != nil {
+		stats.Failed++
+		stats.LastFailure = &now
+		stats.LastError = err.Error()
+		return
+	}
+	stats.Sent++
+	stats.LastSuccess = &now
 }
 
-// addToHistory adds an alert to the history buffer
+// GetNotifierStats returns the delivery counts of every notifier that was used
+func (am *AlertManager) GetNotifierStats() map[string]NotifierStats {
+	am.statsMu.Lock()
+	defer am.statsMu.Unlock()
+
+	stats := make(map[string]NotifierStats, len(am.notifierStats))
+	for name, s := range am.notifierStats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// addToHistory adds an alert to the history buffer, the caller holds mu and alertsMu
 func (am *AlertManager) addToHistory(alert *Alert) {
 	am.alertHistory = append(am.alertHistory, alert)
 
@@ -449,6 +1266,9 @@ func (am *AlertManager) addToHistory(alert *Alert) {
 
 // AcknowledgeAlert acknowledges an active alert
 func (am *AlertManager) AcknowledgeAlert(alertID string) error {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
 	if alert, exists := am.activeAlerts[alertID]; exists {
 		alert.Status = StatusAcknowledged
 		return nil
@@ -456,16 +1276,19 @@ func (am *AlertManager) AcknowledgeAlert(alertID string) error {
 	return fmt.Errorf("alert not found: %s", alertID)
 }
 
-// GetAlert returns a specific alert by ID
+// GetAlert returns a copy of a specific alert by ID
 func (am *AlertManager) GetAlert(alertID string) (*Alert, error) {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
 	if alert, exists := am.activeAlerts[alertID]; exists {
-		return alert, nil
+		return copyAlert(alert), nil
 	}
 
 	// Check alert history as well
 	for _, alert := range am.alertHistory {
 		if alert.ID == alertID {
-			return alert, nil
+			return copyAlert(alert), nil
 		}
 	}
 
@@ -474,6 +1297,11 @@ func (am *AlertManager) GetAlert(alertID string) (*Alert, error) {
 
 // ResolveAlert manually resolves an active alert
 func (am *AlertManager) ResolveAlert(alertID string) error {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
 	if alert, exists := am.activeAlerts[alertID]; exists {
 		alert.Status = StatusResolved
 		now := time.Now()
@@ -490,7 +1318,14 @@ func (am *AlertManager) ResolveAlert(alertID string) error {
 
 // GetRules returns all configured alert rules
 func (am *AlertManager) GetRules() map[string]*AlertRule {
-	return am.rules
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	rules := make(map[string]*AlertRule, len(am.rules))
+	for id, rule := range am.rules {
+		rules[id] = rule
+	}
+	return rules
 }
 
 // GenerateID generates a unique ID for alerts and rules
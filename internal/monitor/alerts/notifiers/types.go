@@ -30,10 +30,17 @@ const (
 type AlertType string
 
 const (
-	AlertTypeSystem  AlertType = "system"
-	AlertTypeService AlertType = "service"
-	AlertTypeHTTP    AlertType = "http"
-	AlertTypeCustom  AlertType = "custom"
+	AlertTypeSystem     AlertType = "system"
+	AlertTypeService    AlertType = "service"
+	AlertTypeHTTP       AlertType = "http"
+	AlertTypeProbe      AlertType = "probe"
+	AlertTypePM2        AlertType = "pm2"
+	AlertTypeSupervisor AlertType = "supervisor"
+	AlertTypeLog        AlertType = "log"
+	AlertTypeCollector  AlertType = "collector"
+	AlertTypeAnomaly    AlertType = "anomaly"
+	AlertTypeSLO        AlertType = "slo"
+	AlertTypeCustom     AlertType = "custom"
 )
 
 // Alert represents an active or historical alert
@@ -1,6 +1,7 @@
 package alerts
 
 import (
+	"sync"
 	"time"
 )
 
@@ -27,10 +28,17 @@ const (
 type AlertType string
 
 const (
-	AlertTypeSystem  AlertType = "system"
-	AlertTypeService AlertType = "service"
-	AlertTypeHTTP    AlertType = "http"
-	AlertTypeCustom  AlertType = "custom"
+	AlertTypeSystem     AlertType = "system"
+	AlertTypeService    AlertType = "service"
+	AlertTypeHTTP       AlertType = "http"
+	AlertTypeProbe      AlertType = "probe"
+	AlertTypePM2        AlertType = "pm2"
+	AlertTypeSupervisor AlertType = "supervisor"
+	AlertTypeLog        AlertType = "log"
+	AlertTypeCollector  AlertType = "collector"
+	AlertTypeAnomaly    AlertType = "anomaly"
+	AlertTypeSLO        AlertType = "slo"
+	AlertTypeCustom     AlertType = "custom"
 )
 
 // Alert represents an active or historical alert
@@ -90,6 +98,21 @@ type AlertConditions struct {
 	DiskThreshold   *float64 `json:"disk_threshold,omitempty"`
 	LoadThreshold   *float64 `json:"load_threshold,omitempty"`
 
+	// Disk I/O conditions, evaluated against the busiest device unless IODevice is set
+	IODevice         string   `json:"io_device,omitempty"`
+	IOUtilThreshold  *float64 `json:"io_util_threshold,omitempty"`  // Percent of time the device was busy
+	IOAwaitThreshold *float64 `json:"io_await_threshold,omitempty"` // Average request latency in milliseconds
+
+	// Pressure stall conditions
+	CPUPressureThreshold    *float64 `json:"cpu_pressure_threshold,omitempty"`    // PSI "some" avg10 percent
+	MemoryPressureThreshold *float64 `json:"memory_pressure_threshold,omitempty"` // PSI "some" avg10 percent
+	IOPressureThreshold     *float64 `json:"io_pressure_threshold,omitempty"`     // PSI "some" avg10 percent
+
+	// Capacity forecast conditions, fitted to stored history
+	PredictFullWithin time.Duration `json:"predict_full_within,omitempty"` // Alert when a resource is forecast to fill up sooner
+	PredictResource   string        `json:"predict_resource,omitempty"`    // "disk", "inodes" or "database", empty matches every resource
+	PredictTarget     string        `json:"predict_target,omitempty"`      // Mount point, empty matches every target
+
 	// Service conditions
 	ServiceName   string `json:"service_name,omitempty"`
 	ServiceStatus string `json:"service_status,omitempty"`
@@ -99,22 +122,86 @@ type AlertConditions struct {
 	ResponseTimeout time.Duration `json:"response_timeout,omitempty"`
 	ExpectedStatus  int           `json:"expected_status,omitempty"`
 
+	// TCP, DNS, ICMP and TLS probe conditions, failures always trigger. ResponseTimeout also applies.
+	ProbeName           string   `json:"probe_name,omitempty"`            // Empty matches every probe
+	PacketLossThreshold *float64 `json:"packet_loss_threshold,omitempty"` // ICMP packet loss percent
+	CertExpiryDays      *int     `json:"cert_expiry_days,omitempty"`      // TLS certificate days remaining
+
+	// PM2 process conditions
+	PM2App           string `json:"pm2_app,omitempty"`           // Empty matches every PM2 application
+	RestartThreshold *int   `json:"restart_threshold,omitempty"` // Restarts within the crash loop window
+
+	// Supervisor process conditions
+	SupervisorProgram string   `json:"supervisor_program,omitempty"` // Group or group:name, empty matches every program
+	SupervisorStates  []string `json:"supervisor_states,omitempty"`  // States that trigger the alert, defaults to FATAL and BACKOFF
+
+	// Journal log conditions
+	LogPattern     string        `json:"log_pattern,omitempty"`     // Journal pattern name, empty matches every pattern
+	LogUnit        string        `json:"log_unit,omitempty"`        // Service name or "kernel", empty matches every unit
+	MatchThreshold *int          `json:"match_threshold,omitempty"` // Matches within MatchWindow, defaults to 1
+	MatchWindow    time.Duration `json:"match_window,omitempty"`    // Defaults to 5 minutes
+
+	// Collector conditions
+	Collector  string        `json:"collector,omitempty"`   // Collector loop name, empty matches every loop
+	StaleAfter time.Duration `json:"stale_after,omitempty"` // Defaults to three collection intervals
+
+	// Anomaly conditions, scored against the seasonal baseline of each series
+	AnomalyMetric    string   `json:"anomaly_metric,omitempty"`    // Stored metric name, empty matches every learned metric
+	AnomalyEntity    string   `json:"anomaly_entity,omitempty"`    // Entity name, empty matches every entity
+	AnomalyScore     *float64 `json:"anomaly_score,omitempty"`     // Defaults to the sensitivity of anomaly detection
+	AnomalyDirection string   `json:"anomaly_direction,omitempty"` // "up", "down" or "both" (default)
+
+	// SLO conditions, on the error budget burn rate over a long and a short window
+	SLOName         string        `json:"slo_name,omitempty"`         // Empty matches every SLO
+	BurnRate        *float64      `json:"burn_rate,omitempty"`        // Budget consumption relative to a steady spend over the SLO window
+	LongWindow      time.Duration `json:"long_window,omitempty"`      // Defaults to 1 hour
+	ShortWindow     time.Duration `json:"short_window,omitempty"`     // Defaults to 5 minutes
+	BudgetRemaining *float64      `json:"budget_remaining,omitempty"` // Percent of the error budget left
+
 	// Duration requirements
 	Duration time.Duration `json:"duration,omitempty"` // How long condition must be true
 }
 
+// BurnRateWindows returns the long and short windows of an SLO burn rate condition
+func (c *AlertConditions) BurnRateWindows() (time.Duration, time.Duration) {
+	long, short := c.LongWindow, c.ShortWindow
+	if long <= 0 {
+		long = time.Hour
+	}
+	if short <= 0 {
+		short = 5 * time.Minute
+	}
+	return long, short
+}
+
 // AlertManager manages the alert system
 type AlertManager struct {
-	rules        map[string]*AlertRule
-	activeAlerts map[string]*Alert
-	alertHistory []*Alert
-	notifiers    []Notifier
+	mu        sync.RWMutex // Guards rules, config and notifiers against reloads
+	rules     map[string]*AlertRule
+	notifiers []Notifier
 
 	// Configuration
 	config *Config
 
-	// State tracking
+	// Alert state, written by concurrent rule evaluations and the API. Taken
+	// after mu when both are needed.
+	alertsMu       sync.Mutex
+	activeAlerts   map[string]*Alert
+	alertHistory   []*Alert
 	lastEvaluation time.Time
+
+	// Delivery counts per notifier, guarded separately as rules notify concurrently
+	statsMu       sync.Mutex
+	notifierStats map[string]*NotifierStats
+}
+
+// NotifierStats counts the deliveries of a notifier
+type NotifierStats struct {
+	Sent        int64      `json:"sent"`
+	Failed      int64      `json:"failed"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
 }
 
 // Notifier interface for different notification channels
@@ -181,6 +268,14 @@ type EvaluationContext struct {
 	SystemMetrics map[string]MetricData
 	ServiceStates map[string]string
 	HTTPResults   map[string]HTTPCheckResult
+	Probes        map[string]ProbeState
+	PM2Apps       map[string]PM2AppState
+	Supervisor    map[string]SupervisorProcessState
+	LogEvents     []LogEventState
+	Collectors    map[string]CollectorState
+	Anomalies     []AnomalyState
+	Forecasts     []ForecastState
+	SLOs          []SLOState
 	CurrentTime   time.Time
 }
 
@@ -192,4 +287,88 @@ type HTTPCheckResult struct {
 	Success      bool
 	Error        string
 	Timestamp    time.Time
+	FailedStep   string // Failing step of a synthetic transaction check
+}
+
+// ProbeState represents the result of a TCP, DNS, ICMP or TLS probe for alert evaluation
+type ProbeState struct {
+	Type         string
+	Target       string
+	Success      bool
+	ResponseTime time.Duration
+	Error        string
+	PacketLoss   float64
+	CertExpiry   *time.Time
+	Timestamp    time.Time
+}
+
+// PM2AppState represents the state of a PM2 application for alert evaluation
+type PM2AppState struct {
+	Status          string
+	Instances       int
+	OnlineInstances int
+	Restarts        int
+	RecentRestarts  int
+	Timestamp       time.Time
+}
+
+// SupervisorProcessState represents the state of a supervisord process for alert evaluation
+type SupervisorProcessState struct {
+	Group      string
+	State      string
+	ExitStatus int
+	SpawnError string
+	Restarts   int
+	Site       string // Owning Laravel site for queue workers
+	Timestamp  time.Time
+}
+
+// LogEventState represents a matched journal entry for alert evaluation
+type LogEventState struct {
+	Unit      string
+	Pattern   string
+	Severity  string
+	Message   string
+	Timestamp time.Time
+}
+
+// CollectorState represents the progress of a collector loop for alert evaluation
+type CollectorState struct {
+	Interval    time.Duration // Zero for loops that produce data on demand
+	StartedAt   time.Time
+	LastSuccess *time.Time
+	LastError   string
+}
+
+// AnomalyState represents the anomaly score of a stored metric series for alert evaluation
+type AnomalyState struct {
+	Metric     string
+	EntityType string
+	Entity     string
+	Value      float64
+	Expected   float64
+	Score      float64 // Signed, negative when the value is below the baseline
+	Threshold  float64
+	Bucket     string // Hour of the week the value was compared with
+	Since      *time.Time
+}
+
+// ForecastState represents when a disk, its inodes or the monitor database is forecast to fill up
+type ForecastState struct {
+	Resource     string
+	Target       string
+	Current      float64
+	Capacity     float64
+	Unit         string // "%" or "bytes"
+	GrowthPerDay float64
+	FullAt       *time.Time // Nil when not filling up
+}
+
+// SLOState represents the error budget of a service level objective for alert evaluation
+type SLOState struct {
+	Name            string
+	Check           string
+	Objective       float64                   // Percent of good checks
+	BudgetRemaining *float64                  // Percent of the error budget left, nil without data
+	BurnRates       map[time.Duration]float64 // By window, only windows with checks
 }
@@ -0,0 +1,426 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"crucible/internal/monitor/storage"
+)
+
+// File formats
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// Datasets that can be exported and imported
+const (
+	DatasetEntities = "entities"
+	DatasetEvents   = "events"
+	DatasetMetrics  = "metrics"
+)
+
+// Datasets lists all datasets, entities first so that imports can attach
+// events and metrics to the imported entities
+var Datasets = []string{DatasetEntities, DatasetEvents, DatasetMetrics}
+
+const (
+	// exportWindow is the time span loaded per query while exporting
+	exportWindow = time.Hour
+
+	// importBatchSize is the number of rows checked and written together
+	importBatchSize = 1000
+)
+
+// columnType is the value type of a column
+type columnType int
+
+const (
+	columnString columnType = iota
+	columnJSON
+	columnInt
+	columnFloat
+	columnTime
+)
+
+// column describes a column of a dataset
+type column struct {
+	Name string
+	Type columnType
+}
+
+// Entities are referenced by type and name instead of their ID, which
+// differs between databases
+var datasetColumns = map[string][]column{
+	DatasetEntities: {
+		{"type", columnString}, {"name", columnString}, {"status", columnString}, {"details", columnJSON},
+		{"created_at", columnTime}, {"updated_at", columnTime}, {"last_seen", columnTime},
+	},
+	DatasetEvents: {
+		{"entity_type", columnString}, {"entity_name", columnString}, {"timestamp", columnTime},
+		{"event_type", columnString}, {"severity", columnString}, {"message", columnString},
+		{"details", columnJSON}, {"expires_at", columnTime},
+	},
+	DatasetMetrics: {
+		{"entity_type", columnString}, {"entity_name", columnString}, {"timestamp", columnTime},
+		{"metric_name", columnString}, {"value", columnFloat}, {"aggregation_level", columnString},
+		{"sample_count", columnInt}, {"tags", columnJSON}, {"expires_at", columnTime},
+	},
+}
+
+// IsDataset reports whether name is a known dataset
+func IsDataset(name string) bool {
+	_, ok := datasetColumns[name]
+	return ok
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv", nil
+	case FormatJSONL:
+		return "application/x-ndjson", nil
+	case FormatParquet:
+		return "application/vnd.apache.parquet", nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// FileName returns the default file name of an exported dataset
+func FileName(dataset, format string) string {
+	return dataset + "." + format
+}
+
+// ExportOptions selects what is exported. The time range applies to events
+// and metrics, entities are always exported completely.
+type ExportOptions struct {
+	Format     string
+	Since      *time.Time
+	Until      *time.Time
+	MetricName string
+}
+
+// Export writes a dataset to w and returns the number of rows written
+func Export(store storage.Storage, w io.Writer, dataset string, options ExportOptions) (int, error) {
+	columns, ok := datasetColumns[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown dataset: %s", dataset)
+	}
+
+	// Entity references are resolved from one listing
+	entities, err := store.ListEntities(nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entities: %w", err)
+	}
+	refs := make(map[int64]*storage.Entity, len(entities))
+	for _, entity := range entities {
+		refs[entity.ID] = entity
+	}
+
+	writer, err := newRowWriter(options.Format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	// Rows written after the export started are left out
+	until := time.Now()
+	if options.Until != nil {
+		until = *options.Until
+	}
+
+	one := 1
+	count := 0
+	switch dataset {
+	case DatasetEntities:
+		for _, entity := range entities {
+			if err := writer.WriteRow(entityRow(entity)); err != nil {
+				return count, err
+			}
+			count++
+		}
+	case DatasetEvents:
+		err = exportWindows(options.Since, until,
+			func(end time.Time) (*time.Time, error) {
+				events, err := store.ListEvents(&storage.EventFilter{Since: options.Since, Until: &end, Limit: &one})
+				if err != nil || len(events) == 0 {
+					return nil, err
+				}
+				return &events[0].Timestamp, nil
+			},
+			func(start, end time.Time) error {
+				events, err := store.ListEvents(&storage.EventFilter{Since: &start, Until: &end})
+				if err != nil {
+					return fmt.Errorf("failed to list events: %w", err)
+				}
+				for _, event := range events {
+					if err := writer.WriteRow(eventRow(event, refs)); err != nil {
+						return err
+					}
+					count++
+				}
+				return nil
+			})
+	case DatasetMetrics:
+		var metricName *string
+		if options.MetricName != "" {
+			metricName = &options.MetricName
+		}
+		err = exportWindows(options.Since, until,
+			func(end time.Time) (*time.Time, error) {
+				metrics, err := store.ListMetrics(&storage.MetricFilter{MetricName: metricName, Since: options.Since, Until: &end, Limit: &one})
+				if err != nil || len(metrics) == 0 {
+					return nil, err
+				}
+				return &metrics[0].Timestamp, nil
+			},
+			func(start, end time.Time) error {
+				metrics, err := store.ListMetrics(&storage.MetricFilter{MetricName: metricName, Since: &start, Until: &end})
+				if err != nil {
+					return fmt.Errorf("failed to list metrics: %w", err)
+				}
+				for _, metric := range metrics {
+					if err := writer.WriteRow(metricRow(metric, refs)); err != nil {
+						return err
+					}
+					count++
+				}
+				return nil
+			})
+	}
+	if err != nil {
+		return count, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export: %w", err)
+	}
+	return count, nil
+}
+
+// exportWindows walks back from until in windows of exportWindow, newest rows
+// first. Each step looks up the newest remaining timestamp, so gaps without
+// data cost a single query.
+func exportWindows(since *time.Time, until time.Time,
+	newest func(end time.Time) (*time.Time, error), export func(start, end time.Time) error) error {
+	end := until
+	for {
+		latest, err := newest(end)
+		if err != nil {
+			return fmt.Errorf("failed to find export window: %w", err)
+		}
+		if latest == nil {
+			return nil
+		}
+
+		start := latest.Truncate(exportWindow)
+		if since != nil && start.Before(*since) {
+			start = *since
+		}
+		if err := export(start, *latest); err != nil {
+			return err
+		}
+
+		if since != nil && !start.After(*since) {
+			return nil
+		}
+		end = start.Add(-time.Nanosecond)
+	}
+}
+
+// entityRow converts an entity into a row of the entities dataset
+func entityRow(entity *storage.Entity) []interface{} {
+	return []interface{}{
+		entity.Type, entity.Name, entity.Status, jsonValue(entity.Details),
+		entity.CreatedAt, entity.UpdatedAt, timeValue(entity.LastSeen),
+	}
+}
+
+// eventRow converts an event into a row of the events dataset
+func eventRow(event *storage.Event, refs map[int64]*storage.Entity) []interface{} {
+	entityType, entityName := entityRef(event.EntityID, refs)
+	return []interface{}{
+		entityType, entityName, event.Timestamp, event.Type, event.Severity, event.Message,
+		jsonValue(event.Details), timeValue(event.ExpiresAt),
+	}
+}
+
+// metricRow converts a metric into a row of the metrics dataset
+func metricRow(metric *storage.Metric, refs map[int64]*storage.Entity) []interface{} {
+	entityType, entityName := entityRef(metric.EntityID, refs)
+	return []interface{}{
+		entityType, entityName, metric.Timestamp, metric.MetricName, metric.Value, metric.AggregationLevel,
+		int64(metric.SampleCount), jsonValue(metric.Tags), timeValue(metric.ExpiresAt),
+	}
+}
+
+// entityRef returns the type and name of a referenced entity
+func entityRef(id *int64, refs map[int64]*storage.Entity) (interface{}, interface{}) {
+	if id == nil || refs[*id] == nil {
+		return nil, nil
+	}
+	return refs[*id].Type, refs[*id].Name
+}
+
+// jsonValue encodes a JSON column, empty objects are null
+func jsonValue(value storage.JSON) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return string(data)
+}
+
+// timeValue returns an optional time as column value
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// Reader reads rows of an exported dataset
+type Reader struct {
+	Dataset string
+	Format  string
+	rows    rowReader
+	index   map[string]int
+	line    int
+}
+
+// NewReader opens an exported file. The format is detected from the content
+// when empty and the dataset from the columns.
+func NewReader(r io.Reader, format string) (*Reader, error) {
+	rows, format, err := newRowReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{Format: format, rows: rows, index: make(map[string]int)}
+	for i, name := range rows.Columns() {
+		reader.index[name] = i
+	}
+
+	switch {
+	case reader.hasColumns("metric_name", "timestamp", "value"):
+		reader.Dataset = DatasetMetrics
+	case reader.hasColumns("event_type", "timestamp"):
+		reader.Dataset = DatasetEvents
+	case reader.hasColumns("type", "name"):
+		reader.Dataset = DatasetEntities
+	default:
+		return nil, fmt.Errorf("unrecognized columns: %v", rows.Columns())
+	}
+
+	return reader, nil
+}
+
+// hasColumns reports whether all columns are present
+func (r *Reader) hasColumns(names ...string) bool {
+	for _, name := range names {
+		if _, ok := r.index[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// next returns the next row, or io.EOF
+func (r *Reader) next() (record, error) {
+	row, err := r.rows.ReadRow()
+	if err != nil {
+		if err != io.EOF {
+			err = fmt.Errorf("failed to read row %d: %w", r.line+1, err)
+		}
+		return record{}, err
+	}
+	r.line++
+	return record{index: r.index, row: row, line: r.line}, nil
+}
+
+// record gives typed access to the columns of a row
+type record struct {
+	index map[string]int
+	row   []interface{}
+	line  int
+}
+
+// value returns the raw value of a column, nil when missing
+func (r record) value(name string) interface{} {
+	if i, ok := r.index[name]; ok && i < len(r.row) {
+		return r.row[i]
+	}
+	return nil
+}
+
+// string returns a column as string
+func (r record) string(name string) string {
+	if value := r.value(name); value != nil {
+		return formatText(value)
+	}
+	return ""
+}
+
+// float returns a numeric column
+func (r record) float(name string) (float64, error) {
+	switch v := r.value(name).(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	default:
+		value, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: invalid %s: %v", r.line, name, v)
+		}
+		return value, nil
+	}
+}
+
+// time returns a time column, nil when empty
+func (r record) time(name string) (*time.Time, error) {
+	switch v := r.value(name).(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	default:
+		t, err := time.Parse(time.RFC3339Nano, fmt.Sprint(v))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid %s: %v", r.line, name, v)
+		}
+		return &t, nil
+	}
+}
+
+// json returns a JSON column, accepting encoded strings and decoded objects
+func (r record) json(name string) (storage.JSON, error) {
+	value := r.value(name)
+	result := make(storage.JSON)
+	if value == nil {
+		return result, nil
+	}
+
+	data, ok := value.(string)
+	if !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid %s: %w", r.line, name, err)
+		}
+		data = string(encoded)
+	}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, fmt.Errorf("row %d: invalid %s: %w", r.line, name, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,304 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// rowWriter writes the rows of one dataset in a file format
+type rowWriter interface {
+	WriteRow(row []interface{}) error
+	Close() error
+}
+
+// rowReader reads rows of a file, values are aligned with Columns
+type rowReader interface {
+	Columns() []string
+	ReadRow() ([]interface{}, error)
+}
+
+// newRowWriter returns a writer for the format
+func newRowWriter(format string, w io.Writer, columns []column) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSONL:
+		return &jsonlWriter{w: bufio.NewWriter(w), columns: columns}, nil
+	case FormatParquet:
+		return newParquetWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// newRowReader returns a reader for the format, detecting it from the
+// content when format is empty
+func newRowReader(format string, r io.Reader) (rowReader, string, error) {
+	buffered := bufio.NewReaderSize(r, 64*1024)
+	if format == "" {
+		head, _ := buffered.Peek(512)
+		format = detectFormat(head)
+	}
+
+	switch format {
+	case FormatCSV:
+		reader, err := newCSVReader(buffered)
+		return reader, format, err
+	case FormatJSONL:
+		reader, err := newJSONLReader(buffered)
+		return reader, format, err
+	case FormatParquet:
+		data, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, format, fmt.Errorf("failed to read parquet data: %w", err)
+		}
+		reader, err := newParquetReader(data)
+		return reader, format, err
+	default:
+		return nil, format, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// detectFormat guesses the format from the first bytes of a file
+func detectFormat(head []byte) string {
+	if bytes.HasPrefix(head, []byte(parquetMagic)) {
+		return FormatParquet
+	}
+	if trimmed := bytes.TrimLeft(head, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatJSONL
+	}
+	return FormatCSV
+}
+
+// formatText formats a value for text formats
+func formatText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvWriter writes a header row followed by one line per row
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+// newCSVWriter writes the header row
+func newCSVWriter(w io.Writer, columns []column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	for i, col := range columns {
+		cw.record[i] = col.Name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+	return cw, nil
+}
+
+// WriteRow writes a row
+func (cw *csvWriter) WriteRow(row []interface{}) error {
+	for i, value := range row {
+		cw.record[i] = formatText(value)
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return fmt.Errorf("failed to write csv row: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered rows
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// csvReader reads rows after the header row, empty fields are null
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+}
+
+// newCSVReader reads the header row
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := &csvReader{r: csv.NewReader(r)}
+	cr.r.ReuseRecord = true
+	header, err := cr.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	cr.columns = append([]string(nil), header...)
+	return cr, nil
+}
+
+// Columns returns the header names
+func (cr *csvReader) Columns() []string {
+	return cr.columns
+}
+
+// ReadRow returns the next row
+func (cr *csvReader) ReadRow() ([]interface{}, error) {
+	record, err := cr.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]interface{}, len(record))
+	for i, field := range record {
+		if field != "" {
+			row[i] = field
+		}
+	}
+	return row, nil
+}
+
+// jsonlWriter writes one JSON object per row with keys in column order
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns []column
+}
+
+// WriteRow writes a row
+func (jw *jsonlWriter) WriteRow(row []interface{}) error {
+	line := []byte{'{'}
+	for i, col := range jw.columns {
+		if i > 0 {
+			line = append(line, ',')
+		}
+		line = strconv.AppendQuote(line, col.Name)
+		line = append(line, ':')
+
+		value := row[i]
+		switch v := value.(type) {
+		case string:
+			// JSON columns are embedded as objects
+			if col.Type == columnJSON && json.Valid([]byte(v)) {
+				line = append(line, v...)
+				continue
+			}
+		case time.Time:
+			value = formatText(v)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", col.Name, err)
+		}
+		line = append(line, encoded...)
+	}
+	line = append(line, '}', '\n')
+
+	if _, err := jw.w.Write(line); err != nil {
+		return fmt.Errorf("failed to write json line: %w", err)
+	}
+	return nil
+}
+
+// Close flushes buffered rows
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
+
+// jsonlReader reads one JSON object per line. Columns are the keys of the
+// first object, keys missing from later objects are null.
+type jsonlReader struct {
+	r       *bufio.Reader
+	columns []string
+	index   map[string]int
+	pending []interface{}
+}
+
+// newJSONLReader reads the first object to learn the columns
+func newJSONLReader(r *bufio.Reader) (*jsonlReader, error) {
+	jr := &jsonlReader{r: r, index: make(map[string]int)}
+	object, err := jr.readObject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first json line: %w", err)
+	}
+
+	// Keep the key order of the line
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	decoder.Token()
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid json line: %w", err)
+		}
+		key, _ := token.(string)
+		jr.index[key] = len(jr.columns)
+		jr.columns = append(jr.columns, key)
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, fmt.Errorf("invalid json line: %w", err)
+		}
+	}
+
+	if jr.pending, err = jr.decode(object); err != nil {
+		return nil, err
+	}
+	return jr, nil
+}
+
+// Columns returns the keys of the first object
+func (jr *jsonlReader) Columns() []string {
+	return jr.columns
+}
+
+// ReadRow returns the next row
+func (jr *jsonlReader) ReadRow() ([]interface{}, error) {
+	if jr.pending != nil {
+		row := jr.pending
+		jr.pending = nil
+		return row, nil
+	}
+	object, err := jr.readObject()
+	if err != nil {
+		return nil, err
+	}
+	return jr.decode(object)
+}
+
+// readObject returns the next non-empty line
+func (jr *jsonlReader) readObject() ([]byte, error) {
+	for {
+		line, err := jr.r.ReadBytes('\n')
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			return trimmed, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// decode converts an object into a row
+func (jr *jsonlReader) decode(object []byte) ([]interface{}, error) {
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(object))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid json line: %w", err)
+	}
+
+	row := make([]interface{}, len(jr.columns))
+	for key, value := range values {
+		if i, ok := jr.index[key]; ok {
+			row[i] = value
+		}
+	}
+	return row, nil
+}
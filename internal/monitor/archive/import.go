@@ -0,0 +1,346 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"crucible/internal/monitor/storage"
+)
+
+// ImportResult reports what an import changed
+type ImportResult struct {
+	Dataset  string `json:"dataset"`
+	Format   string `json:"format"`
+	Imported int    `json:"imported"`
+	Updated  int    `json:"updated"`
+	Skipped  int    `json:"skipped"`
+}
+
+// Import writes the rows of a reader into storage. Importing is idempotent:
+// entities are matched by type and name and only updated from newer rows,
+// events and metrics already stored with the same entity, time and identity
+// are skipped.
+func Import(store storage.Storage, reader *Reader) (*ImportResult, error) {
+	im := &importer{
+		store:    store,
+		entities: make(map[string]*int64),
+		result:   &ImportResult{Dataset: reader.Dataset, Format: reader.Format},
+	}
+
+	var err error
+	switch reader.Dataset {
+	case DatasetEntities:
+		err = im.importEntities(reader)
+	case DatasetEvents:
+		err = im.importRows(reader, im.parseEvent, im.flushEvents)
+	case DatasetMetrics:
+		err = im.importRows(reader, im.parseMetric, im.flushMetrics)
+	default:
+		err = fmt.Errorf("unknown dataset: %s", reader.Dataset)
+	}
+
+	return im.result, err
+}
+
+// importer resolves entity references and deduplicates rows
+type importer struct {
+	store    storage.Storage
+	entities map[string]*int64
+	result   *ImportResult
+}
+
+// importedRow is a parsed event or metric with its deduplication key
+type importedRow struct {
+	key       string
+	timestamp time.Time
+	item      storage.BatchItem
+}
+
+// importEntities creates missing entities and updates outdated ones
+func (im *importer) importEntities(reader *Reader) error {
+	for {
+		rec, err := reader.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entity, err := parseEntity(rec)
+		if err != nil {
+			return err
+		}
+
+		existing, err := im.store.GetEntityByName(entity.Type, entity.Name)
+		if err != nil {
+			if err := im.store.CreateEntity(entity); err != nil {
+				return fmt.Errorf("row %d: %w", rec.line, err)
+			}
+			im.result.Imported++
+			continue
+		}
+
+		if !entity.UpdatedAt.After(existing.UpdatedAt) {
+			im.result.Skipped++
+			continue
+		}
+		existing.Status = entity.Status
+		existing.Details = entity.Details
+		existing.LastSeen = entity.LastSeen
+		if err := im.store.UpdateEntity(existing); err != nil {
+			return fmt.Errorf("row %d: %w", rec.line, err)
+		}
+		im.result.Updated++
+	}
+}
+
+// parseEntity converts a row of the entities dataset
+func parseEntity(rec record) (*storage.Entity, error) {
+	entity := storage.NewEntity(rec.string("type"), rec.string("name"))
+	if entity.Type == "" || entity.Name == "" {
+		return nil, fmt.Errorf("row %d: entity type and name are required", rec.line)
+	}
+	if status := rec.string("status"); status != "" {
+		entity.Status = status
+	}
+
+	var err error
+	if entity.Details, err = rec.json("details"); err != nil {
+		return nil, err
+	}
+	for name, target := range map[string]*time.Time{"created_at": &entity.CreatedAt, "updated_at": &entity.UpdatedAt} {
+		t, err := rec.time(name)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			*target = *t
+		}
+	}
+	if entity.LastSeen, err = rec.time("last_seen"); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// importRows parses rows in batches and writes the ones not stored yet
+func (im *importer) importRows(reader *Reader, parse func(record) (*importedRow, error),
+	flush func([]*importedRow) error) error {
+	batch := make([]*importedRow, 0, importBatchSize)
+	for {
+		rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		row, err := parse(rec)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) == importBatchSize {
+			if err := flush(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	return flush(batch)
+}
+
+// timeRange returns the oldest and newest timestamp of a batch
+func timeRange(batch []*importedRow) (time.Time, time.Time) {
+	since, until := batch[0].timestamp, batch[0].timestamp
+	for _, row := range batch[1:] {
+		if row.timestamp.Before(since) {
+			since = row.timestamp
+		}
+		if row.timestamp.After(until) {
+			until = row.timestamp
+		}
+	}
+	return since, until
+}
+
+// writeNew writes the rows whose key is not in existing
+func (im *importer) writeNew(batch []*importedRow, existing map[string]bool) error {
+	items := make([]storage.BatchItem, 0, len(batch))
+	for _, row := range batch {
+		if existing[row.key] {
+			im.result.Skipped++
+			continue
+		}
+		// Duplicates within the file are imported once
+		existing[row.key] = true
+		items = append(items, row.item)
+	}
+
+	if err := im.store.BatchWrite(items); err != nil {
+		return fmt.Errorf("failed to write imported rows: %w", err)
+	}
+	im.result.Imported += len(items)
+	return nil
+}
+
+// parseEvent converts a row of the events dataset
+func (im *importer) parseEvent(rec record) (*importedRow, error) {
+	entityID, err := im.entityID(rec)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := rec.time("timestamp")
+	if err != nil {
+		return nil, err
+	}
+	if timestamp == nil {
+		return nil, fmt.Errorf("row %d: timestamp is required", rec.line)
+	}
+
+	event := storage.NewEvent(entityID, rec.string("event_type"), rec.string("message"))
+	event.Timestamp = *timestamp
+	if severity := rec.string("severity"); severity != "" {
+		event.Severity = severity
+	}
+	if event.Details, err = rec.json("details"); err != nil {
+		return nil, err
+	}
+	if event.ExpiresAt, err = rec.time("expires_at"); err != nil {
+		return nil, err
+	}
+
+	return &importedRow{key: eventKey(event), timestamp: event.Timestamp, item: storage.BatchItem{Type: "event", Data: event}}, nil
+}
+
+// flushEvents writes the events of a batch not stored yet
+func (im *importer) flushEvents(batch []*importedRow) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	since, until := timeRange(batch)
+	events, err := im.store.ListEvents(&storage.EventFilter{Since: &since, Until: &until})
+	if err != nil {
+		return fmt.Errorf("failed to list existing events: %w", err)
+	}
+	existing := make(map[string]bool, len(events))
+	for _, event := range events {
+		existing[eventKey(event)] = true
+	}
+
+	return im.writeNew(batch, existing)
+}
+
+// eventKey identifies an event by entity, second, type and message
+func eventKey(event *storage.Event) string {
+	return fmt.Sprintf("%d\x00%d\x00%s\x00%s", entityKey(event.EntityID), event.Timestamp.Unix(), event.Type, event.Message)
+}
+
+// parseMetric converts a row of the metrics dataset
+func (im *importer) parseMetric(rec record) (*importedRow, error) {
+	entityID, err := im.entityID(rec)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := rec.time("timestamp")
+	if err != nil {
+		return nil, err
+	}
+	if timestamp == nil || rec.string("metric_name") == "" {
+		return nil, fmt.Errorf("row %d: timestamp and metric_name are required", rec.line)
+	}
+	value, err := rec.float("value")
+	if err != nil {
+		return nil, err
+	}
+
+	metric := storage.NewMetric(entityID, rec.string("metric_name"), value)
+	metric.Timestamp = *timestamp
+	if level := rec.string("aggregation_level"); level != "" {
+		metric.AggregationLevel = level
+	}
+	if count, err := rec.float("sample_count"); err != nil {
+		return nil, err
+	} else if count > 0 {
+		metric.SampleCount = int(count)
+	}
+	if metric.Tags, err = rec.json("tags"); err != nil {
+		return nil, err
+	}
+	if metric.ExpiresAt, err = rec.time("expires_at"); err != nil {
+		return nil, err
+	}
+
+	return &importedRow{key: metricKey(metric), timestamp: metric.Timestamp, item: storage.BatchItem{Type: "metric", Data: metric}}, nil
+}
+
+// flushMetrics writes the metrics of a batch not stored yet
+func (im *importer) flushMetrics(batch []*importedRow) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	since, until := timeRange(batch)
+	metrics, err := im.store.ListMetrics(&storage.MetricFilter{Since: &since, Until: &until})
+	if err != nil {
+		return fmt.Errorf("failed to list existing metrics: %w", err)
+	}
+	existing := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		existing[metricKey(metric)] = true
+	}
+
+	return im.writeNew(batch, existing)
+}
+
+// metricKey identifies a sample by its series, second and aggregation level
+func metricKey(metric *storage.Metric) string {
+	tags := "{}"
+	if len(metric.Tags) > 0 {
+		// encoding/json sorts map keys
+		if data, err := json.Marshal(metric.Tags); err == nil {
+			tags = string(data)
+		}
+	}
+	return fmt.Sprintf("%d\x00%s\x00%s\x00%d\x00%s", entityKey(metric.EntityID), metric.MetricName, tags,
+		metric.Timestamp.Unix(), metric.AggregationLevel)
+}
+
+// entityKey returns an entity ID for keys, 0 for none
+func entityKey(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// entityID resolves the entity referenced by a row, creating it when the
+// entities dataset was not imported first
+func (im *importer) entityID(rec record) (*int64, error) {
+	entityType, name := rec.string("entity_type"), rec.string("entity_name")
+	if entityType == "" && name == "" {
+		return nil, nil
+	}
+
+	key := entityType + "\x00" + name
+	if id, ok := im.entities[key]; ok {
+		return id, nil
+	}
+
+	entity, err := im.store.GetEntityByName(entityType, name)
+	if err != nil {
+		entity = storage.NewEntity(entityType, name)
+		if err := im.store.CreateEntity(entity); err != nil {
+			return nil, fmt.Errorf("row %d: %w", rec.line, err)
+		}
+	}
+	im.entities[key] = &entity.ID
+	return &entity.ID, nil
+}
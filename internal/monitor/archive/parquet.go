@@ -0,0 +1,560 @@
+package archive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet files are written with one plain encoded, uncompressed data page per
+// column chunk. The reader handles that layout, which keeps files readable by
+// any Parquet tool without pulling in a compression library.
+const (
+	parquetMagic        = "PAR1"
+	parquetRowGroupSize = 50000
+)
+
+// Parquet physical types
+const (
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet converted types
+const (
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+	parquetTimestampMicros = 10
+	parquetJSON            = 19
+)
+
+// Parquet encodings, page types and repetition types
+const (
+	parquetPlain    = 0
+	parquetRLE      = 3
+	parquetDataPage = 0
+	parquetRequired = 0
+	parquetOptional = 1
+)
+
+// parquetChunk locates a written column chunk
+type parquetChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+// parquetRowGroup describes a written row group
+type parquetRowGroup struct {
+	numRows int64
+	chunks  []parquetChunk
+}
+
+// parquetWriter buffers rows and writes them as row groups
+type parquetWriter struct {
+	w         io.Writer
+	columns   []column
+	rows      [][]interface{}
+	offset    int64
+	numRows   int64
+	rowGroups []parquetRowGroup
+}
+
+// newParquetWriter starts a Parquet file with the given columns
+func newParquetWriter(w io.Writer, columns []column) (*parquetWriter, error) {
+	pw := &parquetWriter{w: w, columns: columns}
+	if err := pw.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// WriteRow adds a row, flushing a row group when it is full
+func (pw *parquetWriter) WriteRow(row []interface{}) error {
+	pw.rows = append(pw.rows, row)
+	if len(pw.rows) >= parquetRowGroupSize {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+// Close writes the remaining rows and the file footer
+func (pw *parquetWriter) Close() error {
+	if err := pw.flushRowGroup(); err != nil {
+		return err
+	}
+
+	footer := pw.footer()
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	if err := pw.write(size[:]); err != nil {
+		return err
+	}
+	return pw.write([]byte(parquetMagic))
+}
+
+// write writes data and tracks the file offset
+func (pw *parquetWriter) write(data []byte) error {
+	n, err := pw.w.Write(data)
+	pw.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write parquet data: %w", err)
+	}
+	return nil
+}
+
+// flushRowGroup writes the buffered rows column by column
+func (pw *parquetWriter) flushRowGroup() error {
+	if len(pw.rows) == 0 {
+		return nil
+	}
+
+	group := parquetRowGroup{numRows: int64(len(pw.rows))}
+	values := make([]interface{}, len(pw.rows))
+	for i, col := range pw.columns {
+		for j, row := range pw.rows {
+			values[j] = row[i]
+		}
+		page, err := encodeParquetPage(col, values)
+		if err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, parquetChunk{offset: pw.offset, size: int64(len(page)), numValues: int64(len(values))})
+		if err := pw.write(page); err != nil {
+			return err
+		}
+	}
+
+	pw.rowGroups = append(pw.rowGroups, group)
+	pw.numRows += group.numRows
+	pw.rows = pw.rows[:0]
+	return nil
+}
+
+// footer encodes the file metadata
+func (pw *parquetWriter) footer() []byte {
+	w := &thriftWriter{}
+	w.begin()
+	w.i32(1, 1)
+
+	w.list(2, thriftStruct, len(pw.columns)+1)
+	w.begin()
+	w.binary(4, "schema")
+	w.i32(5, int32(len(pw.columns)))
+	w.end()
+	for _, col := range pw.columns {
+		physicalType, convertedType := parquetTypes(col.Type)
+		w.begin()
+		w.i32(1, physicalType)
+		w.i32(3, parquetOptional)
+		w.binary(4, col.Name)
+		if convertedType >= 0 {
+			w.i32(6, convertedType)
+		}
+		w.end()
+	}
+
+	w.i64(3, pw.numRows)
+	w.list(4, thriftStruct, len(pw.rowGroups))
+	for _, group := range pw.rowGroups {
+		w.begin()
+		w.list(1, thriftStruct, len(group.chunks))
+		var totalSize int64
+		for i, chunk := range group.chunks {
+			physicalType, _ := parquetTypes(pw.columns[i].Type)
+			w.begin()
+			w.i64(2, chunk.offset)
+			w.structField(3)
+			w.i32(1, physicalType)
+			w.list(2, thriftI32, 2)
+			w.listI32(parquetPlain)
+			w.listI32(parquetRLE)
+			w.list(3, thriftBinary, 1)
+			w.listBinary(pw.columns[i].Name)
+			w.i32(4, 0)
+			w.i64(5, chunk.numValues)
+			w.i64(6, chunk.size)
+			w.i64(7, chunk.size)
+			w.i64(9, chunk.offset)
+			w.end()
+			w.end()
+			totalSize += chunk.size
+		}
+		w.i64(2, totalSize)
+		w.i64(3, group.numRows)
+		w.end()
+	}
+
+	w.binary(6, "crucible-monitor")
+	w.end()
+	return w.buf
+}
+
+// parquetTypes returns the physical and converted type of a column, the
+// converted type is -1 when there is none
+func parquetTypes(columnType columnType) (int32, int32) {
+	switch columnType {
+	case columnJSON:
+		return parquetByteArray, parquetJSON
+	case columnInt:
+		return parquetInt64, -1
+	case columnFloat:
+		return parquetDouble, -1
+	case columnTime:
+		return parquetInt64, parquetTimestampMillis
+	default:
+		return parquetByteArray, parquetUTF8
+	}
+}
+
+// encodeParquetPage encodes the values of a column chunk as one data page
+func encodeParquetPage(col column, values []interface{}) ([]byte, error) {
+	levels := make([]byte, 0, 16)
+	var data []byte
+	for i := 0; i < len(values); {
+		// Definition levels are run-length encoded, 1 for present values
+		present := values[i] != nil
+		run := 0
+		for ; i < len(values) && (values[i] != nil) == present; i++ {
+			run++
+		}
+		levels = binary.AppendUvarint(levels, uint64(run)<<1)
+		if present {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+	}
+
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		var err error
+		if data, err = appendParquetValue(data, col, value); err != nil {
+			return nil, err
+		}
+	}
+
+	page := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(levels)+len(data)), uint32(len(levels)))
+	page = append(page, levels...)
+	page = append(page, data...)
+
+	w := &thriftWriter{}
+	w.begin()
+	w.i32(1, parquetDataPage)
+	w.i32(2, int32(len(page)))
+	w.i32(3, int32(len(page)))
+	w.structField(5)
+	w.i32(1, int32(len(values)))
+	w.i32(2, parquetPlain)
+	w.i32(3, parquetRLE)
+	w.i32(4, parquetRLE)
+	w.end()
+	w.end()
+
+	return append(w.buf, page...), nil
+}
+
+// appendParquetValue appends a plain encoded value
+func appendParquetValue(data []byte, col column, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		if col.Type != columnString && col.Type != columnJSON {
+			break
+		}
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+		return append(data, v...), nil
+	case int64:
+		if col.Type == columnInt {
+			return binary.LittleEndian.AppendUint64(data, uint64(v)), nil
+		}
+	case float64:
+		if col.Type == columnFloat {
+			return binary.LittleEndian.AppendUint64(data, math.Float64bits(v)), nil
+		}
+	case time.Time:
+		if col.Type == columnTime {
+			return binary.LittleEndian.AppendUint64(data, uint64(v.UnixMilli())), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value for column %s: %v", col.Name, value)
+}
+
+// parquetColumn is a column of a Parquet file being read
+type parquetColumn struct {
+	name          string
+	physicalType  int64
+	convertedType int64
+	optional      bool
+}
+
+// parquetReader reads the rows of a Parquet file held in memory
+type parquetReader struct {
+	data      []byte
+	columns   []parquetColumn
+	rowGroups []interface{}
+	group     int
+	values    [][]interface{}
+	row       int
+}
+
+// newParquetReader parses the footer of a Parquet file
+func newParquetReader(data []byte) (*parquetReader, error) {
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, fmt.Errorf("not a parquet file")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if footerSize > len(data)-12 {
+		return nil, fmt.Errorf("invalid parquet footer size")
+	}
+
+	footer := &thriftReader{data: data[len(data)-8-footerSize : len(data)-8]}
+	meta, err := footer.readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet footer: %w", err)
+	}
+
+	pr := &parquetReader{data: data, rowGroups: meta.list(4)}
+	schema := meta.list(2)
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("parquet file has no schema")
+	}
+	for _, item := range schema[1:] {
+		element, _ := item.(thriftStructValue)
+		if element.has(5) {
+			return nil, fmt.Errorf("nested parquet column %s is not supported", element.string(4))
+		}
+		col := parquetColumn{
+			name:          element.string(4),
+			physicalType:  element.i64(1),
+			convertedType: -1,
+		}
+		if element.has(6) {
+			col.convertedType = element.i64(6)
+		}
+		switch element.i64(3) {
+		case parquetRequired:
+		case parquetOptional:
+			col.optional = true
+		default:
+			return nil, fmt.Errorf("repeated parquet column %s is not supported", col.name)
+		}
+		pr.columns = append(pr.columns, col)
+	}
+
+	return pr, nil
+}
+
+// Columns returns the column names
+func (pr *parquetReader) Columns() []string {
+	names := make([]string, len(pr.columns))
+	for i, col := range pr.columns {
+		names[i] = col.name
+	}
+	return names
+}
+
+// ReadRow returns the next row, or io.EOF after the last one
+func (pr *parquetReader) ReadRow() ([]interface{}, error) {
+	for len(pr.values) == 0 || pr.row >= len(pr.values[0]) {
+		if pr.group >= len(pr.rowGroups) {
+			return nil, io.EOF
+		}
+		if err := pr.readRowGroup(); err != nil {
+			return nil, err
+		}
+	}
+
+	row := make([]interface{}, len(pr.columns))
+	for i := range pr.columns {
+		row[i] = pr.values[i][pr.row]
+	}
+	pr.row++
+	return row, nil
+}
+
+// readRowGroup decodes all column chunks of the next row group
+func (pr *parquetReader) readRowGroup() error {
+	group, _ := pr.rowGroups[pr.group].(thriftStructValue)
+	pr.group++
+	pr.row = 0
+
+	chunks := group.list(1)
+	if len(chunks) != len(pr.columns) {
+		return fmt.Errorf("parquet row group has %d columns, schema has %d", len(chunks), len(pr.columns))
+	}
+
+	pr.values = make([][]interface{}, len(pr.columns))
+	for i, item := range chunks {
+		chunk, _ := item.(thriftStructValue)
+		values, err := pr.readColumnChunk(pr.columns[i], chunk.structField(3))
+		if err != nil {
+			return fmt.Errorf("failed to read parquet column %s: %w", pr.columns[i].name, err)
+		}
+		pr.values[i] = values
+	}
+	return nil
+}
+
+// readColumnChunk decodes the data pages of a column chunk
+func (pr *parquetReader) readColumnChunk(col parquetColumn, meta thriftStructValue) ([]interface{}, error) {
+	if meta == nil {
+		return nil, fmt.Errorf("missing column metadata")
+	}
+	if codec := meta.i64(4); codec != 0 {
+		return nil, fmt.Errorf("compression codec %d is not supported, write the file uncompressed", codec)
+	}
+
+	numValues := int(meta.i64(5))
+	values := make([]interface{}, 0, numValues)
+	pos := int(meta.i64(9))
+	for len(values) < numValues {
+		if pos < 0 || pos >= len(pr.data) {
+			return nil, fmt.Errorf("page offset out of range")
+		}
+		reader := &thriftReader{data: pr.data, pos: pos}
+		header, err := reader.readStruct()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page header: %w", err)
+		}
+		if pageType := header.i64(1); pageType != parquetDataPage {
+			return nil, fmt.Errorf("page type %d is not supported, dictionary encoded files cannot be imported", pageType)
+		}
+		size := int(header.i64(3))
+		if size < 0 || reader.pos+size > len(pr.data) {
+			return nil, fmt.Errorf("page exceeds file size")
+		}
+		page := pr.data[reader.pos : reader.pos+size]
+		pos = reader.pos + size
+
+		dataPage := header.structField(5)
+		if encoding := dataPage.i64(2); encoding != parquetPlain {
+			return nil, fmt.Errorf("encoding %d is not supported, only plain encoded files can be imported", encoding)
+		}
+		pageValues, err := decodeParquetPage(col, page, int(dataPage.i64(1)))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, pageValues...)
+	}
+
+	return values, nil
+}
+
+// decodeParquetPage decodes the definition levels and plain values of a data page
+func decodeParquetPage(col parquetColumn, page []byte, count int) ([]interface{}, error) {
+	present := make([]bool, count)
+	if col.optional {
+		if len(page) < 4 {
+			return nil, fmt.Errorf("truncated definition levels")
+		}
+		size := int(binary.LittleEndian.Uint32(page))
+		if size > len(page)-4 {
+			return nil, fmt.Errorf("truncated definition levels")
+		}
+		if err := decodeLevels(page[4:4+size], present); err != nil {
+			return nil, err
+		}
+		page = page[4+size:]
+	} else {
+		for i := range present {
+			present[i] = true
+		}
+	}
+
+	values := make([]interface{}, count)
+	for i := range values {
+		if !present[i] {
+			continue
+		}
+		value, n, err := decodeParquetValue(col, page)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+		page = page[n:]
+	}
+	return values, nil
+}
+
+// decodeLevels decodes bit width 1 definition levels in the RLE/bit-packed hybrid encoding
+func decodeLevels(data []byte, present []bool) error {
+	pos, i := 0, 0
+	for i < len(present) {
+		header, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return fmt.Errorf("invalid definition levels")
+		}
+		pos += n
+
+		if header&1 == 0 {
+			if pos >= len(data) {
+				return fmt.Errorf("truncated definition levels")
+			}
+			value := data[pos] == 1
+			pos++
+			for run := int(header >> 1); run > 0 && i < len(present); run-- {
+				present[i] = value
+				i++
+			}
+			continue
+		}
+
+		groups := int(header >> 1)
+		if pos+groups > len(data) {
+			return fmt.Errorf("truncated definition levels")
+		}
+		for bit := 0; bit < groups*8 && i < len(present); bit++ {
+			present[i] = data[pos+bit/8]>>(bit%8)&1 == 1
+			i++
+		}
+		pos += groups
+	}
+	return nil
+}
+
+// decodeParquetValue decodes one plain value and returns its size
+func decodeParquetValue(col parquetColumn, data []byte) (interface{}, int, error) {
+	size := map[int64]int{parquetInt32: 4, parquetInt64: 8, parquetFloat: 4, parquetDouble: 8}[col.physicalType]
+	if col.physicalType == parquetByteArray {
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("truncated value")
+		}
+		size = 4 + int(binary.LittleEndian.Uint32(data))
+	}
+	if size == 0 {
+		return nil, 0, fmt.Errorf("physical type %d is not supported", col.physicalType)
+	}
+	if size > len(data) || size < 0 {
+		return nil, 0, fmt.Errorf("truncated value")
+	}
+
+	switch col.physicalType {
+	case parquetByteArray:
+		return string(data[4:size]), size, nil
+	case parquetInt32:
+		return int64(int32(binary.LittleEndian.Uint32(data))), size, nil
+	case parquetFloat:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(data))), size, nil
+	case parquetDouble:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), size, nil
+	}
+
+	value := int64(binary.LittleEndian.Uint64(data))
+	switch col.convertedType {
+	case parquetTimestampMillis:
+		return time.UnixMilli(value), size, nil
+	case parquetTimestampMicros:
+		return time.UnixMicro(value), size, nil
+	}
+	return value, size, nil
+}
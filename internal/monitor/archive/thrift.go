@@ -0,0 +1,290 @@
+package archive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Thrift compact protocol types used by the Parquet footer and page headers
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs with the Thrift compact protocol
+type thriftWriter struct {
+	buf    []byte
+	fields []int16
+}
+
+// begin starts a struct
+func (w *thriftWriter) begin() {
+	w.fields = append(w.fields, 0)
+}
+
+// end closes the current struct
+func (w *thriftWriter) end() {
+	w.buf = append(w.buf, thriftStop)
+	w.fields = w.fields[:len(w.fields)-1]
+}
+
+// field writes a field header, using the short form for small id deltas
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	last := &w.fields[len(w.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|fieldType)
+	} else {
+		w.buf = append(w.buf, fieldType)
+		w.buf = binary.AppendUvarint(w.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+// i32 writes an i32 field
+func (w *thriftWriter) i32(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.buf = binary.AppendUvarint(w.buf, zigzag(int64(value)))
+}
+
+// i64 writes an i64 field
+func (w *thriftWriter) i64(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.buf = binary.AppendUvarint(w.buf, zigzag(value))
+}
+
+// binary writes a string field
+func (w *thriftWriter) binary(id int16, value string) {
+	w.field(id, thriftBinary)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+// list writes a list field header, the elements follow
+func (w *thriftWriter) list(id int16, elemType byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elemType)
+	} else {
+		w.buf = append(w.buf, 0xf0|elemType)
+		w.buf = binary.AppendUvarint(w.buf, uint64(size))
+	}
+}
+
+// struct starts a struct field, closed with end
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// listI32 writes a list element
+func (w *thriftWriter) listI32(value int32) {
+	w.buf = binary.AppendUvarint(w.buf, zigzag(int64(value)))
+}
+
+// listBinary writes a list element
+func (w *thriftWriter) listBinary(value string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+// zigzag maps signed integers to unsigned varints
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+// thriftStructValue is a decoded struct keyed by field id
+type thriftStructValue map[int16]interface{}
+
+// i64 returns an integer field, or 0 when it is not set
+func (s thriftStructValue) i64(id int16) int64 {
+	value, _ := s[id].(int64)
+	return value
+}
+
+// has reports whether a field is set
+func (s thriftStructValue) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+// string returns a binary field as string
+func (s thriftStructValue) string(id int16) string {
+	value, _ := s[id].([]byte)
+	return string(value)
+}
+
+// structField returns a nested struct field
+func (s thriftStructValue) structField(id int16) thriftStructValue {
+	value, _ := s[id].(thriftStructValue)
+	return value
+}
+
+// list returns a list field
+func (s thriftStructValue) list(id int16) []interface{} {
+	value, _ := s[id].([]interface{})
+	return value
+}
+
+// thriftReader decodes Thrift compact structs without a schema. Integers are
+// returned as int64, binaries as []byte, structs as thriftStructValue.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+// readStruct decodes a struct
+func (r *thriftReader) readStruct() (thriftStructValue, error) {
+	result := make(thriftStructValue)
+	var last int16
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == thriftStop {
+			return result, nil
+		}
+
+		fieldType := header & 0x0f
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			value, err := r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(value)
+		}
+		last = id
+
+		switch fieldType {
+		case thriftTrue:
+			result[id] = true
+		case thriftFalse:
+			result[id] = false
+		default:
+			value, err := r.readValue(fieldType)
+			if err != nil {
+				return nil, err
+			}
+			result[id] = value
+		}
+	}
+}
+
+// readValue decodes a value of the given type
+func (r *thriftReader) readValue(valueType byte) (interface{}, error) {
+	switch valueType {
+	case thriftTrue, thriftFalse:
+		// Booleans inside lists take a full byte
+		b, err := r.readByte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.readVarint()
+	case thriftDouble:
+		if r.pos+8 > len(r.data) {
+			return nil, fmt.Errorf("unexpected end of thrift data")
+		}
+		value := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return value, nil
+	case thriftBinary:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		value := r.data[r.pos : r.pos+size]
+		r.pos += size
+		return value, nil
+	case thriftList, thriftSet:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := int(header >> 4)
+		if size == 15 {
+			if size, err = r.readSize(); err != nil {
+				return nil, err
+			}
+		}
+		values := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			value, err := r.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case thriftMap:
+		size, err := r.readSize()
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < 2*size; i++ {
+			valueType := types >> 4
+			if i%2 == 1 {
+				valueType = types & 0x0f
+			}
+			if _, err := r.readValue(valueType); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unknown thrift type: %d", valueType)
+	}
+}
+
+// readByte reads a single byte
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("unexpected end of thrift data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+// readVarint reads a zigzag encoded integer
+func (r *thriftReader) readVarint() (int64, error) {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid thrift varint")
+	}
+	r.pos += n
+	return int64(value>>1) ^ -int64(value&1), nil
+}
+
+// readSize reads a length and checks it against the remaining data
+func (r *thriftReader) readSize() (int, error) {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid thrift length")
+	}
+	r.pos += n
+	if value > uint64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("thrift length exceeds data")
+	}
+	return int(value), nil
+}
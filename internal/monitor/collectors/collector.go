@@ -0,0 +1,36 @@
+package collectors
+
+import (
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// Collector is the interface of custom collectors. Gather returns a sample as
+// flat metrics, which the agent stores without knowing the collector's own
+// result types. The built-in collectors keep their typed results, which the
+// agent stores as entities and events.
+type Collector interface {
+	// Name returns the unique collector name
+	Name() string
+
+	// Gather collects a sample and returns it as metrics
+	Gather() ([]monitor.Metric, error)
+}
+
+var _ Collector = (*ExecCollector)(nil)
+
+// newMetric creates a metric with the given labels
+func newMetric(name string, metricType monitor.MetricType, value float64, unit string, timestamp time.Time, labels map[string]string) monitor.Metric {
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return monitor.Metric{
+		Name:      name,
+		Type:      metricType,
+		Value:     value,
+		Unit:      unit,
+		Labels:    labels,
+		Timestamp: timestamp,
+	}
+}
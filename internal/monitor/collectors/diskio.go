@@ -0,0 +1,239 @@
+package collectors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// Sector size used by /proc/diskstats regardless of the device's physical sector size
+const diskStatsSectorSize = 512
+
+// diskStatsSample holds the cumulative counters of one /proc/diskstats line
+type diskStatsSample struct {
+	reads        uint64
+	readSectors  uint64
+	readTimeMs   uint64
+	writes       uint64
+	writeSectors uint64
+	writeTimeMs  uint64
+	inFlight     uint64
+	ioTimeMs     uint64
+}
+
+// collectDiskIOMetrics reads /proc/diskstats and returns per-device rates since
+// the previous call. The first call only records a baseline and returns no rates.
+func (s *SystemCollector) collectDiskIOMetrics(now time.Time) ([]monitor.DiskIOMetrics, error) {
+	samples, err := readDiskStats("/proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.lastDiskStats
+	elapsed := now.Sub(s.lastDiskStatsTime)
+	s.lastDiskStats = samples
+	s.lastDiskStatsTime = now
+
+	if len(previous) == 0 || elapsed <= 0 {
+		return []monitor.DiskIOMetrics{}, nil
+	}
+
+	devices := make([]string, 0, len(samples))
+	for device := range samples {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	metrics := make([]monitor.DiskIOMetrics, 0, len(devices))
+	for _, device := range devices {
+		prev, ok := previous[device]
+		if !ok {
+			continue
+		}
+		metrics = append(metrics, diskIORates(device, prev, samples[device], elapsed))
+	}
+
+	return metrics, nil
+}
+
+// readDiskStats parses /proc/diskstats, keeping whole block devices only
+func readDiskStats(path string) (map[string]diskStatsSample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	samples := make(map[string]diskStatsSample)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+
+		device := fields[2]
+		if !isWholeBlockDevice(device) {
+			continue
+		}
+
+		var counters [11]uint64
+		for i := range counters {
+			counters[i], err = strconv.ParseUint(fields[3+i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse diskstats field %d for %s: %w", i+4, device, err)
+			}
+		}
+
+		samples[device] = diskStatsSample{
+			reads:        counters[0],
+			readSectors:  counters[2],
+			readTimeMs:   counters[3],
+			writes:       counters[4],
+			writeSectors: counters[6],
+			writeTimeMs:  counters[7],
+			inFlight:     counters[8],
+			ioTimeMs:     counters[9],
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return samples, nil
+}
+
+// isWholeBlockDevice reports whether a diskstats device is a disk rather than a
+// partition or a virtual loop/ram/zram device
+func isWholeBlockDevice(device string) bool {
+	if strings.HasPrefix(device, "loop") || strings.HasPrefix(device, "ram") || strings.HasPrefix(device, "zram") {
+		return false
+	}
+
+	// Partitions have no entry of their own under /sys/block
+	_, err := os.Stat("/sys/block/" + strings.ReplaceAll(device, "/", "!"))
+	return err == nil
+}
+
+// diskIORates computes rates between two diskstats samples
+func diskIORates(device string, prev, cur diskStatsSample, elapsed time.Duration) monitor.DiskIOMetrics {
+	seconds := elapsed.Seconds()
+
+	reads := counterDelta(cur.reads, prev.reads)
+	writes := counterDelta(cur.writes, prev.writes)
+	readTime := counterDelta(cur.readTimeMs, prev.readTimeMs)
+	writeTime := counterDelta(cur.writeTimeMs, prev.writeTimeMs)
+	ioTime := counterDelta(cur.ioTimeMs, prev.ioTimeMs)
+
+	metrics := monitor.DiskIOMetrics{
+		Device:           device,
+		ReadsPerSec:      float64(reads) / seconds,
+		WritesPerSec:     float64(writes) / seconds,
+		ReadBytesPerSec:  float64(counterDelta(cur.readSectors, prev.readSectors)*diskStatsSectorSize) / seconds,
+		WriteBytesPerSec: float64(counterDelta(cur.writeSectors, prev.writeSectors)*diskStatsSectorSize) / seconds,
+		InFlight:         cur.inFlight,
+	}
+
+	if reads > 0 {
+		metrics.ReadAwaitMs = float64(readTime) / float64(reads)
+	}
+	if writes > 0 {
+		metrics.WriteAwaitMs = float64(writeTime) / float64(writes)
+	}
+	if reads+writes > 0 {
+		metrics.AwaitMs = float64(readTime+writeTime) / float64(reads+writes)
+	}
+
+	metrics.UtilPercent = float64(ioTime) / float64(elapsed.Milliseconds()) * 100
+	if metrics.UtilPercent > 100 {
+		metrics.UtilPercent = 100
+	}
+
+	return metrics
+}
+
+// counterDelta returns the increase of a cumulative counter, treating a
+// decrease as a counter reset
+func counterDelta(current, previous uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
+
+// collectPressureMetrics reads /proc/pressure/{cpu,memory,io}. It returns nil
+// when PSI is not available.
+func (s *SystemCollector) collectPressureMetrics() *monitor.PressureMetrics {
+	cpu, err := readPressureFile("/proc/pressure/cpu")
+	if err != nil {
+		return nil
+	}
+
+	pressure := &monitor.PressureMetrics{CPU: cpu}
+
+	if memory, err := readPressureFile("/proc/pressure/memory"); err == nil {
+		pressure.Memory = memory
+	}
+	if io, err := readPressureFile("/proc/pressure/io"); err == nil {
+		pressure.IO = io
+	}
+
+	return pressure
+}
+
+// readPressureFile parses a PSI file of the form
+// "some avg10=0.00 avg60=0.00 avg300=0.00 total=0"
+func readPressureFile(path string) (monitor.PressureStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return monitor.PressureStats{}, err
+	}
+	defer file.Close()
+
+	var stats monitor.PressureStats
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+
+		values := make(map[string]float64)
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				values[key] = parsed
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			stats.SomeAvg10 = values["avg10"]
+			stats.SomeAvg60 = values["avg60"]
+			stats.SomeAvg300 = values["avg300"]
+		case "full":
+			stats.FullAvg10 = values["avg10"]
+			stats.FullAvg60 = values["avg60"]
+			stats.FullAvg300 = values["avg300"]
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return monitor.PressureStats{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return stats, nil
+}
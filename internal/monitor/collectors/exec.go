@@ -0,0 +1,450 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// Nagios plugin exit codes
+var nagiosStates = map[int]string{
+	0: "OK",
+	1: "WARNING",
+	2: "CRITICAL",
+	3: "UNKNOWN",
+}
+
+// perfDataValuePattern splits a perfdata value into its number and unit of measure
+var perfDataValuePattern = regexp.MustCompile(`^([-+]?[0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)([a-zA-Z%]*)$`)
+
+// metricNameReplacer replaces characters that are not valid in metric names
+var metricNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_.:-]+`)
+
+// ExecCollector runs an external command and parses its output into metrics
+type ExecCollector struct {
+	config monitor.ExecCollectorConfig
+}
+
+// NewExecCollector creates a new exec collector
+func NewExecCollector(config monitor.ExecCollectorConfig) (*ExecCollector, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("exec collector name is required")
+	}
+	if config.Command == "" {
+		return nil, fmt.Errorf("exec collector %s: command is required", config.Name)
+	}
+
+	switch config.Format {
+	case "":
+		config.Format = "nagios"
+	case "nagios", "json", "influx":
+	default:
+		return nil, fmt.Errorf("exec collector %s: unsupported format %q", config.Name, config.Format)
+	}
+
+	return &ExecCollector{config: config}, nil
+}
+
+// Name returns the collector name
+func (e *ExecCollector) Name() string {
+	return e.config.Name
+}
+
+// Gather runs the command and parses its output
+func (e *ExecCollector) Gather() ([]monitor.Metric, error) {
+	timeout := e.config.GetTimeout()
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.config.Command, e.config.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exitCode := 0
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("command %s timed out after %s", e.config.Command, timeout)
+		}
+		if !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run %s: %w", e.config.Command, err)
+		}
+
+		// Nagios plugins report their state through the exit code
+		exitCode = exitErr.ExitCode()
+		if e.config.Format != "nagios" {
+			return nil, fmt.Errorf("command %s exited with code %d: %s", e.config.Command, exitCode, strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	now := time.Now()
+	var metrics []monitor.Metric
+	var err error
+	switch e.config.Format {
+	case "nagios":
+		metrics, err = ParseNagiosOutput(stdout.String(), exitCode, now)
+	case "json":
+		metrics, err = ParseJSONMetrics(stdout.Bytes(), now)
+	case "influx":
+		metrics, err = ParseInfluxLineProtocol(stdout.String(), now)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse output of %s: %w", e.config.Command, err)
+	}
+
+	for i := range metrics {
+		if metrics[i].Labels == nil {
+			metrics[i].Labels = make(map[string]string)
+		}
+		for key, value := range e.config.Tags {
+			if _, exists := metrics[i].Labels[key]; !exists {
+				metrics[i].Labels[key] = value
+			}
+		}
+		metrics[i].Labels["collector"] = e.config.Name
+	}
+
+	return metrics, nil
+}
+
+// ParseNagiosOutput parses Nagios plugin output of the form
+// "STATUS TEXT | label=value[UOM];[warn];[crit];[min];[max] ..." together with
+// the plugin exit code. It returns a check_status metric plus one metric per
+// performance data label.
+func ParseNagiosOutput(output string, exitCode int, timestamp time.Time) ([]monitor.Metric, error) {
+	state, ok := nagiosStates[exitCode]
+	if !ok {
+		state = "UNKNOWN"
+		exitCode = 3
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	text, _, _ := strings.Cut(lines[0], "|")
+
+	status := newMetric("check_status", monitor.MetricTypeCustom, float64(exitCode), "", timestamp, map[string]string{"state": state})
+	status.Metadata = map[string]interface{}{"output": strings.TrimSpace(text)}
+	metrics := []monitor.Metric{status}
+
+	// Performance data follows the first "|" on the first line and on any long output line
+	for _, line := range lines {
+		_, perfData, found := strings.Cut(line, "|")
+		if !found {
+			continue
+		}
+
+		for _, token := range splitPerfData(perfData) {
+			metric, ok := parsePerfDataToken(token, timestamp)
+			if ok {
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// splitPerfData splits performance data on spaces outside single-quoted labels
+func splitPerfData(perfData string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuote := false
+
+	for _, r := range perfData {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && !inQuote:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// parsePerfDataToken parses a single label=value[UOM];warn;crit;min;max token
+func parsePerfDataToken(token string, timestamp time.Time) (monitor.Metric, bool) {
+	var label, rest string
+	if strings.HasPrefix(token, "'") {
+		end := strings.Index(token[1:], "'=")
+		if end < 0 {
+			return monitor.Metric{}, false
+		}
+		label = token[1 : end+1]
+		rest = token[end+3:]
+	} else {
+		var found bool
+		label, rest, found = strings.Cut(token, "=")
+		if !found {
+			return monitor.Metric{}, false
+		}
+	}
+
+	parts := strings.Split(rest, ";")
+	match := perfDataValuePattern.FindStringSubmatch(parts[0])
+	if match == nil {
+		return monitor.Metric{}, false
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return monitor.Metric{}, false
+	}
+
+	metric := newMetric(sanitizeMetricName(label), monitor.MetricTypeCustom, value, match[2], timestamp, nil)
+
+	// Thresholds and range are kept as metadata
+	metadata := make(map[string]interface{})
+	for i, key := range []string{"warn", "crit", "min", "max"} {
+		if i+1 < len(parts) && parts[i+1] != "" {
+			metadata[key] = parts[i+1]
+		}
+	}
+	if len(metadata) > 0 {
+		metric.Metadata = metadata
+	}
+
+	return metric, true
+}
+
+// ParseJSONMetrics parses JSON collector output. Accepted shapes are a list of
+// {"name", "value", "unit", "tags"} objects (optionally wrapped in {"metrics": [...]})
+// or an object of numeric values, where nested objects are flattened with "_".
+func ParseJSONMetrics(data []byte, timestamp time.Time) ([]monitor.Metric, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if object, ok := decoded.(map[string]interface{}); ok {
+		if list, ok := object["metrics"].([]interface{}); ok {
+			decoded = list
+		}
+	}
+
+	switch value := decoded.(type) {
+	case []interface{}:
+		return parseJSONMetricList(value, timestamp)
+	case map[string]interface{}:
+		var metrics []monitor.Metric
+		flattenJSONMetrics("", value, timestamp, &metrics)
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+		return metrics, nil
+	default:
+		return nil, fmt.Errorf("expected a JSON object or array, got %T", decoded)
+	}
+}
+
+// parseJSONMetricList parses a list of metric objects
+func parseJSONMetricList(list []interface{}, timestamp time.Time) ([]monitor.Metric, error) {
+	metrics := make([]monitor.Metric, 0, len(list))
+	for i, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("metric %d: expected an object", i)
+		}
+
+		name, _ := object["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("metric %d: name is required", i)
+		}
+		value, ok := jsonNumber(object["value"])
+		if !ok {
+			return nil, fmt.Errorf("metric %s: value must be a number", name)
+		}
+		unit, _ := object["unit"].(string)
+
+		labels := make(map[string]string)
+		tags, ok := object["tags"].(map[string]interface{})
+		if !ok {
+			tags, _ = object["labels"].(map[string]interface{})
+		}
+		for key, tag := range tags {
+			labels[key] = fmt.Sprint(tag)
+		}
+
+		metric := newMetric(sanitizeMetricName(name), monitor.MetricTypeCustom, value, unit, timestamp, labels)
+		if ts, ok := object["timestamp"].(float64); ok && ts > 0 {
+			metric.Timestamp = time.Unix(int64(ts), 0)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, nil
+}
+
+// flattenJSONMetrics collects numeric and boolean values from nested objects
+func flattenJSONMetrics(prefix string, object map[string]interface{}, timestamp time.Time, metrics *[]monitor.Metric) {
+	for key, raw := range object {
+		name := key
+		if prefix != "" {
+			name = prefix + "_" + key
+		}
+
+		if nested, ok := raw.(map[string]interface{}); ok {
+			flattenJSONMetrics(name, nested, timestamp, metrics)
+			continue
+		}
+		if value, ok := jsonNumber(raw); ok {
+			*metrics = append(*metrics, newMetric(sanitizeMetricName(name), monitor.MetricTypeCustom, value, "", timestamp, nil))
+		}
+	}
+}
+
+// jsonNumber converts a decoded JSON number or boolean to a float
+func jsonNumber(raw interface{}) (float64, bool) {
+	switch value := raw.(type) {
+	case float64:
+		return value, true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// ParseInfluxLineProtocol parses InfluxDB line protocol:
+// measurement[,tag=value...] field=value[,field=value...] [timestamp_ns]
+// Each numeric or boolean field becomes a metric named measurement_field, or
+// just measurement for a field called "value". String fields are skipped.
+func ParseInfluxLineProtocol(output string, timestamp time.Time) ([]monitor.Metric, error) {
+	var metrics []monitor.Metric
+
+	for lineNumber, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sections := splitUnescaped(line, ' ', true)
+		if len(sections) < 2 {
+			return nil, fmt.Errorf("line %d: missing fields", lineNumber+1)
+		}
+
+		series := splitUnescaped(sections[0], ',', false)
+		measurement := unescapeInflux(series[0])
+		labels := make(map[string]string)
+		for _, tag := range series[1:] {
+			key, value, found := strings.Cut(tag, "=")
+			if !found {
+				return nil, fmt.Errorf("line %d: invalid tag %q", lineNumber+1, tag)
+			}
+			labels[unescapeInflux(key)] = unescapeInflux(value)
+		}
+
+		pointTime := timestamp
+		if len(sections) > 2 {
+			ns, err := strconv.ParseInt(sections[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid timestamp: %w", lineNumber+1, err)
+			}
+			pointTime = time.Unix(0, ns)
+		}
+
+		for _, field := range splitUnescaped(sections[1], ',', true) {
+			key, raw, found := strings.Cut(field, "=")
+			if !found {
+				return nil, fmt.Errorf("line %d: invalid field %q", lineNumber+1, field)
+			}
+
+			value, ok := parseInfluxFieldValue(raw)
+			if !ok {
+				continue
+			}
+
+			name := measurement
+			if key = unescapeInflux(key); key != "value" {
+				name = measurement + "_" + key
+			}
+
+			fieldLabels := make(map[string]string, len(labels))
+			for k, v := range labels {
+				fieldLabels[k] = v
+			}
+			metrics = append(metrics, newMetric(sanitizeMetricName(name), monitor.MetricTypeCustom, value, "", pointTime, fieldLabels))
+		}
+	}
+
+	return metrics, nil
+}
+
+// splitUnescaped splits s on sep, ignoring backslash-escaped separators and,
+// when quotes is set, separators inside double-quoted strings
+func splitUnescaped(s string, sep byte, quotes bool) []string {
+	var parts []string
+	start := 0
+	inQuote := false
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"' && quotes:
+			inQuote = !inQuote
+		case s[i] == sep && !inQuote:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// unescapeInflux removes line protocol escapes from measurement, tag and field names
+func unescapeInflux(s string) string {
+	replacer := strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ", `\\`, `\`)
+	return replacer.Replace(s)
+}
+
+// parseInfluxFieldValue parses a numeric or boolean field value
+func parseInfluxFieldValue(raw string) (float64, bool) {
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, true
+	case "f", "F", "false", "False", "FALSE":
+		return 0, true
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		return 0, false
+	}
+
+	// Integer fields carry an i (signed) or u (unsigned) suffix
+	raw = strings.TrimSuffix(strings.TrimSuffix(raw, "i"), "u")
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// sanitizeMetricName replaces characters that are not valid in metric names
+func sanitizeMetricName(name string) string {
+	return strings.Trim(metricNameReplacer.ReplaceAllString(name, "_"), "_")
+}
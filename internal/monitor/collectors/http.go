@@ -34,6 +34,10 @@ func NewHTTPCollector() *HTTPCollector {
 
 // PerformCheck performs a single HTTP health check
 func (h *HTTPCollector) PerformCheck(check monitor.HTTPCheck) monitor.HTTPCheckResult {
+	if len(check.Steps) > 0 {
+		return h.performTransaction(check)
+	}
+
 	startTime := time.Now()
 	result := monitor.HTTPCheckResult{
 		Name:      check.Name,
@@ -106,8 +110,11 @@ func (h *HTTPCollector) ValidateCheck(check monitor.HTTPCheck) error {
 	if check.Name == "" {
 		return fmt.Errorf("check name is required")
 	}
-	if check.URL == "" {
-		return fmt.Errorf("check URL is required")
+	if check.URL == "" && len(check.Steps) == 0 {
+		return fmt.Errorf("check URL or steps are required")
+	}
+	if err := check.ValidateSteps(); err != nil {
+		return err
 	}
 
 	// Validate timeout
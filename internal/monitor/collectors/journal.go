@@ -0,0 +1,189 @@
+package collectors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// journalMaxEntrySize bounds a single JSON journal entry, which can hold long
+// messages and binary fields
+const journalMaxEntrySize = 16 * 1024 * 1024
+
+// JournalCollector follows the systemd journal and reports entries matching
+// configured patterns
+type JournalCollector struct {
+	units    map[string]bool
+	patterns []journalPattern
+}
+
+// journalPattern is a compiled monitor.JournalPattern
+type journalPattern struct {
+	name     string
+	severity string
+	unit     string
+	re       *regexp.Regexp
+}
+
+// NewJournalCollector creates a new journal collector. Entries are only matched
+// for the given units (or syslog identifiers) plus kernel messages; an empty
+// unit list matches every entry.
+func NewJournalCollector(units []string, patterns []monitor.JournalPattern) (*JournalCollector, error) {
+	collector := &JournalCollector{
+		units: make(map[string]bool),
+	}
+
+	for _, unit := range units {
+		collector.units[strings.TrimSuffix(unit, ".service")] = true
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal pattern %s: %w", pattern.Name, err)
+		}
+		collector.patterns = append(collector.patterns, journalPattern{
+			name:     pattern.Name,
+			severity: pattern.Severity,
+			unit:     strings.TrimSuffix(pattern.Unit, ".service"),
+			re:       re,
+		})
+	}
+
+	return collector, nil
+}
+
+// Follow runs `journalctl -f` and calls handler for every matching entry until
+// the context is cancelled or journalctl exits
+func (j *JournalCollector) Follow(parent context.Context, handler func(monitor.LogEvent)) error {
+	// Cancelled to stop journalctl when reading its output fails
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "journalctl", "-o", "json", "-f", "-n", "0", "--no-pager")
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open journalctl output: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start journalctl: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), journalMaxEntrySize)
+	for scanner.Scan() {
+		if event, ok := j.Match(scanner.Bytes()); ok {
+			handler(event)
+		}
+	}
+
+	// journalctl -f never exits on its own, stop it before waiting
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		cancel()
+	}
+	waitErr := cmd.Wait()
+
+	if parent.Err() != nil {
+		return nil
+	}
+	if scanErr != nil {
+		return fmt.Errorf("failed to read journal: %w", scanErr)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("journalctl exited: %w", waitErr)
+	}
+
+	return nil
+}
+
+// Match parses a JSON journal entry and returns the event for the first
+// pattern it matches
+func (j *JournalCollector) Match(line []byte) (monitor.LogEvent, bool) {
+	var entry map[string]interface{}
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return monitor.LogEvent{}, false
+	}
+
+	message := journalField(entry, "MESSAGE")
+	if message == "" {
+		return monitor.LogEvent{}, false
+	}
+
+	identifier := journalField(entry, "SYSLOG_IDENTIFIER")
+	unit := journalUnit(entry, identifier)
+	if len(j.units) > 0 && unit != "kernel" && !j.units[unit] && !j.units[identifier] {
+		return monitor.LogEvent{}, false
+	}
+
+	for _, pattern := range j.patterns {
+		if pattern.unit != "" && pattern.unit != unit && pattern.unit != identifier {
+			continue
+		}
+		if !pattern.re.MatchString(message) {
+			continue
+		}
+
+		event := monitor.LogEvent{
+			Unit:       unit,
+			Identifier: identifier,
+			Message:    message,
+			Pattern:    pattern.name,
+			Severity:   pattern.severity,
+			Timestamp:  time.Now(),
+		}
+		event.PID, _ = strconv.Atoi(journalField(entry, "_PID"))
+		if usec, err := strconv.ParseInt(journalField(entry, "__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+			event.Timestamp = time.UnixMicro(usec)
+		}
+
+		return event, true
+	}
+
+	return monitor.LogEvent{}, false
+}
+
+// journalUnit returns the unit an entry is about. systemd's own messages carry
+// the affected unit in UNIT, kernel messages have no unit at all.
+func journalUnit(entry map[string]interface{}, identifier string) string {
+	if journalField(entry, "_TRANSPORT") == "kernel" {
+		return "kernel"
+	}
+
+	for _, field := range []string{"UNIT", "_SYSTEMD_UNIT"} {
+		if unit := journalField(entry, field); unit != "" {
+			return strings.TrimSuffix(unit, ".service")
+		}
+	}
+
+	return identifier
+}
+
+// journalField returns a journal field as a string. Fields that are not valid
+// UTF-8 are exported by journalctl as arrays of bytes.
+func journalField(entry map[string]interface{}, name string) string {
+	switch value := entry[name].(type) {
+	case string:
+		return value
+	case []interface{}:
+		data := make([]byte, 0, len(value))
+		for _, b := range value {
+			if n, ok := b.(float64); ok {
+				data = append(data, byte(n))
+			}
+		}
+		return string(data)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,136 @@
+package collectors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// applyNetworkRates fills in per-second rates from the change in interface
+// counters since the previous call. Rates stay zero on the first call and for
+// interfaces that were not seen before.
+func (s *SystemCollector) applyNetworkRates(interfaces []monitor.NetworkMetrics, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.lastNetStats
+	elapsed := now.Sub(s.lastNetStatsTime).Seconds()
+
+	s.lastNetStats = make(map[string]monitor.NetworkMetrics, len(interfaces))
+	s.lastNetStatsTime = now
+
+	for i := range interfaces {
+		cur := &interfaces[i]
+		s.lastNetStats[cur.Interface] = *cur
+
+		prev, ok := previous[cur.Interface]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+
+		// counterDelta treats decreasing counters (interface reset, driver reload) as zero
+		rate := func(current, previous uint64) float64 {
+			return float64(counterDelta(current, previous)) / elapsed
+		}
+
+		cur.BytesRecvPerSec = rate(cur.BytesRecv, prev.BytesRecv)
+		cur.BytesSentPerSec = rate(cur.BytesSent, prev.BytesSent)
+		cur.PacketsRecvPerSec = rate(cur.PacketsRecv, prev.PacketsRecv)
+		cur.PacketsSentPerSec = rate(cur.PacketsSent, prev.PacketsSent)
+		cur.ErrorsRecvPerSec = rate(cur.ErrorsRecv, prev.ErrorsRecv)
+		cur.ErrorsSentPerSec = rate(cur.ErrorsSent, prev.ErrorsSent)
+		cur.DroppedRecvPerSec = rate(cur.DroppedRecv, prev.DroppedRecv)
+		cur.DroppedSentPerSec = rate(cur.DroppedSent, prev.DroppedSent)
+	}
+}
+
+// collectTCPMetrics counts TCP sockets per state over IPv4 and IPv6
+func (s *SystemCollector) collectTCPMetrics() (monitor.TCPMetrics, error) {
+	var metrics monitor.TCPMetrics
+
+	for _, path := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		if err := readTCPStates(path, &metrics); err != nil {
+			// IPv6 may be disabled, which removes /proc/net/tcp6
+			if os.IsNotExist(err) && path == "/proc/net/tcp6" {
+				continue
+			}
+			return monitor.TCPMetrics{}, err
+		}
+	}
+
+	return metrics, nil
+}
+
+// readTCPStates adds the sockets listed in a /proc/net/tcp style file to metrics
+func readTCPStates(path string, metrics *monitor.TCPMetrics) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // sl local_address rem_address st tx_queue:rx_queue ...
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			continue
+		}
+
+		switch state {
+		case 0x01:
+			metrics.Established++
+		case 0x02:
+			metrics.SynSent++
+		case 0x03:
+			metrics.SynRecv++
+		case 0x04:
+			metrics.FinWait1++
+		case 0x05:
+			metrics.FinWait2++
+		case 0x06:
+			metrics.TimeWait++
+		case 0x07:
+			metrics.Close++
+		case 0x08:
+			metrics.CloseWait++
+		case 0x09:
+			metrics.LastAck++
+		case 0x0A:
+			metrics.Listen++
+
+			// For listening sockets rx_queue is the current accept queue length
+			_, rxQueue, found := strings.Cut(fields[4], ":")
+			if !found {
+				continue
+			}
+			backlog, err := strconv.ParseUint(rxQueue, 16, 32)
+			if err != nil {
+				continue
+			}
+			metrics.ListenBacklog += int(backlog)
+			if int(backlog) > metrics.ListenBacklogMax {
+				metrics.ListenBacklogMax = int(backlog)
+			}
+		case 0x0B:
+			metrics.Closing++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package collectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// PM2Collector collects process information from PM2 using `pm2 jlist`
+type PM2Collector struct {
+	pm2Home         string
+	crashLoopWindow time.Duration
+
+	mu           sync.Mutex
+	lastRestarts map[string]int         // app name -> last observed restart count
+	restartLog   map[string][]time.Time // app name -> timestamps of observed restarts
+}
+
+// pm2Process mirrors the subset of `pm2 jlist` output we care about
+type pm2Process struct {
+	Name  string `json:"name"`
+	PID   int    `json:"pid"`
+	PMID  int    `json:"pm_id"`
+	Monit struct {
+		Memory uint64  `json:"memory"`
+		CPU    float64 `json:"cpu"`
+	} `json:"monit"`
+	PM2Env struct {
+		Status           string `json:"status"`
+		ExecMode         string `json:"exec_mode"`
+		PMUptime         int64  `json:"pm_uptime"` // Unix milliseconds of the last start
+		RestartTime      int    `json:"restart_time"`
+		UnstableRestarts int    `json:"unstable_restarts"`
+	} `json:"pm2_env"`
+}
+
+// NewPM2Collector creates a new PM2 collector. pm2Home may be empty to use the
+// PM2_HOME of the current user, and crashLoopWindow controls how far back
+// restarts are counted towards RecentRestarts.
+func NewPM2Collector(pm2Home string, crashLoopWindow time.Duration) *PM2Collector {
+	if crashLoopWindow <= 0 {
+		crashLoopWindow = 10 * time.Minute
+	}
+
+	return &PM2Collector{
+		pm2Home:         pm2Home,
+		crashLoopWindow: crashLoopWindow,
+		lastRestarts:    make(map[string]int),
+		restartLog:      make(map[string][]time.Time),
+	}
+}
+
+// Collect gathers the current status of every PM2 application
+func (p *PM2Collector) Collect() ([]monitor.PM2AppStatus, error) {
+	cmd := exec.Command("pm2", "jlist")
+	if p.pm2Home != "" {
+		cmd.Env = append(os.Environ(), "PM2_HOME="+p.pm2Home)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run pm2 jlist: %w", err)
+	}
+
+	apps, err := ParsePM2JList(output, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	p.trackRestarts(apps)
+
+	return apps, nil
+}
+
+// ParsePM2JList parses `pm2 jlist` output and aggregates processes per application
+func ParsePM2JList(data []byte, now time.Time) ([]monitor.PM2AppStatus, error) {
+	var processes []pm2Process
+	if err := json.Unmarshal(data, &processes); err != nil {
+		return nil, fmt.Errorf("failed to parse pm2 jlist output: %w", err)
+	}
+
+	appsByName := make(map[string]*monitor.PM2AppStatus)
+	var names []string
+
+	for _, proc := range processes {
+		app, exists := appsByName[proc.Name]
+		if !exists {
+			app = &monitor.PM2AppStatus{
+				Name:      proc.Name,
+				ExecMode:  proc.PM2Env.ExecMode,
+				Timestamp: now,
+			}
+			appsByName[proc.Name] = app
+			names = append(names, proc.Name)
+		}
+
+		app.Instances++
+		app.Restarts += proc.PM2Env.RestartTime
+		app.UnstableRestarts += proc.PM2Env.UnstableRestarts
+		app.CPUPercent += proc.Monit.CPU
+		app.MemoryBytes += proc.Monit.Memory
+		if proc.PID > 0 {
+			app.PIDs = append(app.PIDs, proc.PID)
+		}
+
+		if proc.PM2Env.Status == "online" {
+			app.OnlineInstances++
+
+			// Report the uptime of the most recently started instance
+			if proc.PM2Env.PMUptime > 0 {
+				uptime := now.Sub(time.UnixMilli(proc.PM2Env.PMUptime))
+				if app.Uptime == 0 || uptime < app.Uptime {
+					app.Uptime = uptime
+				}
+			}
+		}
+
+		app.Status = mergePM2Status(app.Status, proc.PM2Env.Status)
+	}
+
+	sort.Strings(names)
+	apps := make([]monitor.PM2AppStatus, 0, len(names))
+	for _, name := range names {
+		apps = append(apps, *appsByName[name])
+	}
+
+	return apps, nil
+}
+
+// mergePM2Status combines instance statuses so the worst state wins
+func mergePM2Status(current, next string) string {
+	if current == "" {
+		return next
+	}
+	if pm2StatusRank(next) > pm2StatusRank(current) {
+		return next
+	}
+	return current
+}
+
+// pm2StatusRank orders PM2 statuses from healthy to failed
+func pm2StatusRank(status string) int {
+	switch status {
+	case "online":
+		return 0
+	case "launching", "one-launch-status":
+		return 1
+	case "stopping", "stopped":
+		return 2
+	case "waiting restart":
+		return 3
+	case "errored":
+		return 4
+	default:
+		return 1
+	}
+}
+
+// trackRestarts records restart count increases and fills in RecentRestarts
+func (p *PM2Collector) trackRestarts(apps []monitor.PM2AppStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range apps {
+		app := &apps[i]
+		previous, seen := p.lastRestarts[app.Name]
+		p.lastRestarts[app.Name] = app.Restarts
+
+		if seen && app.Restarts > previous {
+			for n := 0; n < app.Restarts-previous; n++ {
+				p.restartLog[app.Name] = append(p.restartLog[app.Name], app.Timestamp)
+			}
+		}
+
+		// Drop restarts that fell out of the window
+		cutoff := app.Timestamp.Add(-p.crashLoopWindow)
+		recent := p.restartLog[app.Name][:0]
+		for _, ts := range p.restartLog[app.Name] {
+			if ts.After(cutoff) {
+				recent = append(recent, ts)
+			}
+		}
+		p.restartLog[app.Name] = recent
+		app.RecentRestarts = len(recent)
+	}
+}
+
+// GetApp returns the status of a single PM2 application by name
+func (p *PM2Collector) GetApp(name string) (*monitor.PM2AppStatus, error) {
+	apps, err := p.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, app := range apps {
+		if app.Name == name {
+			return &app, nil
+		}
+	}
+
+	return nil, fmt.Errorf("PM2 application not found: %s", name)
+}
@@ -0,0 +1,276 @@
+package collectors
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+var (
+	pingPacketsPattern = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received`)
+	pingRTTPattern     = regexp.MustCompile(`= [\d.]+/([\d.]+)/[\d.]+`)
+)
+
+// ProbeCollector performs TCP, DNS, ICMP and TLS probes
+type ProbeCollector struct{}
+
+// NewProbeCollector creates a new probe collector
+func NewProbeCollector() *ProbeCollector {
+	return &ProbeCollector{}
+}
+
+// PerformProbe performs a single probe
+func (p *ProbeCollector) PerformProbe(probe monitor.ProbeCheck) monitor.ProbeResult {
+	result := monitor.ProbeResult{
+		Name:      probe.Name,
+		Type:      probe.Type,
+		Target:    probe.Target,
+		Timestamp: time.Now(),
+	}
+
+	timeout := probe.GetTimeout()
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	var err error
+	switch probe.Type {
+	case "tcp":
+		err = p.probeTCP(probe, timeout, &result)
+	case "dns":
+		err = p.probeDNS(probe, timeout, &result)
+	case "icmp":
+		err = p.probeICMP(probe, timeout, &result)
+	case "tls":
+		err = p.probeTLS(probe, timeout, &result)
+	default:
+		err = fmt.Errorf("unknown probe type %q", probe.Type)
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Success = true
+	return result
+}
+
+// probeTCP checks that a TCP port accepts connections
+func (p *ProbeCollector) probeTCP(probe monitor.ProbeCheck, timeout time.Duration, result *monitor.ProbeResult) error {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", probe.Target, timeout)
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	conn.Close()
+	return nil
+}
+
+// probeDNS resolves the target and checks the expected records are returned
+func (p *ProbeCollector) probeDNS(probe monitor.ProbeCheck, timeout time.Duration, result *monitor.ProbeResult) error {
+	resolver := net.DefaultResolver
+	if probe.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, probe.Resolver)
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	records, err := lookupRecords(ctx, resolver, strings.ToUpper(probe.RecordType), probe.Target)
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		return fmt.Errorf("lookup failed: %w", err)
+	}
+	sort.Strings(records)
+	result.Records = records
+
+	if len(records) == 0 {
+		return fmt.Errorf("no %s records returned", probe.RecordType)
+	}
+
+	var missing []string
+	for _, expected := range probe.Expected {
+		if !containsRecord(records, expected) {
+			missing = append(missing, expected)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("expected %s records missing: %s", probe.RecordType, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// lookupRecords returns the records of the given type as strings
+func lookupRecords(ctx context.Context, resolver *net.Resolver, recordType, host string) ([]string, error) {
+	var records []string
+
+	switch recordType {
+	case "A", "AAAA", "":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, mx.Host)
+		}
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	return records, nil
+}
+
+// containsRecord reports whether records include expected, ignoring case and trailing dots
+func containsRecord(records []string, expected string) bool {
+	want := strings.TrimSuffix(strings.ToLower(expected), ".")
+	for _, record := range records {
+		if strings.TrimSuffix(strings.ToLower(record), ".") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// probeICMP pings the target using the system ping binary, which avoids
+// needing raw socket privileges in the agent
+func (p *ProbeCollector) probeICMP(probe monitor.ProbeCheck, timeout time.Duration, result *monitor.ProbeResult) error {
+	count := probe.Count
+	if count <= 0 {
+		count = 3
+	}
+
+	waitSeconds := int(timeout.Seconds())
+	if waitSeconds < 1 {
+		waitSeconds = 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(count)*timeout+timeout)
+	defer cancel()
+
+	// ping exits non-zero when no replies arrive, the summary is still printed
+	output, runErr := exec.CommandContext(ctx, "ping", "-n", "-q",
+		"-c", strconv.Itoa(count), "-W", strconv.Itoa(waitSeconds), probe.Target).CombinedOutput()
+
+	transmitted, received, avgRTT, err := ParsePingOutput(string(output))
+	if err != nil {
+		if runErr != nil {
+			if message := strings.TrimSpace(string(output)); message != "" {
+				return fmt.Errorf("ping failed: %s", message)
+			}
+			return fmt.Errorf("ping failed: %w", runErr)
+		}
+		return err
+	}
+
+	result.PacketLoss = float64(transmitted-received) / float64(transmitted) * 100
+	result.ResponseTime = avgRTT
+
+	if received == 0 {
+		return fmt.Errorf("no replies from %s (%d packets sent)", probe.Target, transmitted)
+	}
+
+	return nil
+}
+
+// ParsePingOutput extracts packet counts and the average round-trip time from ping output
+func ParsePingOutput(output string) (int, int, time.Duration, error) {
+	match := pingPacketsPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, 0, 0, fmt.Errorf("failed to parse ping summary")
+	}
+
+	transmitted, _ := strconv.Atoi(match[1])
+	received, _ := strconv.Atoi(match[2])
+	if transmitted == 0 {
+		return 0, 0, 0, fmt.Errorf("ping sent no packets")
+	}
+
+	var avgRTT time.Duration
+	if rtt := pingRTTPattern.FindStringSubmatch(output); rtt != nil {
+		if ms, err := strconv.ParseFloat(rtt[1], 64); err == nil {
+			avgRTT = time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+
+	return transmitted, received, avgRTT, nil
+}
+
+// probeTLS completes a TLS handshake without sending a request
+func (p *ProbeCollector) probeTLS(probe monitor.ProbeCheck, timeout time.Duration, result *monitor.ProbeResult) error {
+	serverName := probe.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(probe.Target)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	start := time.Now()
+	conn, err := tls.DialWithDialer(dialer, "tcp", probe.Target, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: probe.SkipVerify,
+	})
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	result.TLSVersion = tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		expiry := state.PeerCertificates[0].NotAfter
+		result.CertExpiry = &expiry
+	}
+
+	return nil
+}
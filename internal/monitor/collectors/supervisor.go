@@ -0,0 +1,273 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// Queue worker program prefixes created by crucible for Laravel sites
+var laravelWorkerPrefixes = []string{"laravel-worker-", "laravel-queue-"}
+
+// SupervisorCollector collects process information from supervisord over its
+// XML-RPC interface on the unix socket
+type SupervisorCollector struct {
+	socketPath string
+	client     *http.Client
+
+	mu         sync.Mutex
+	lastStarts map[string]int64 // full name -> last observed start time
+	restarts   map[string]int   // full name -> starts observed since collector start
+}
+
+// NewSupervisorCollector creates a new supervisor collector for the given socket
+func NewSupervisorCollector(socketPath string) *SupervisorCollector {
+	if socketPath == "" {
+		socketPath = "/var/run/supervisor.sock"
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+
+	return &SupervisorCollector{
+		socketPath: socketPath,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+		lastStarts: make(map[string]int64),
+		restarts:   make(map[string]int),
+	}
+}
+
+// Collect gathers the current state of every supervisord process
+func (s *SupervisorCollector) Collect() ([]monitor.SupervisorProcessStatus, error) {
+	value, err := s.call("supervisor.getAllProcessInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	processes, starts, err := parseSupervisorProcessInfo(value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.trackRestarts(processes, starts)
+
+	return processes, nil
+}
+
+// call performs an XML-RPC method call without parameters
+func (s *SupervisorCollector) call(method string) (interface{}, error) {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	body.WriteString("<methodCall><methodName>")
+	if err := xml.EscapeText(&body, []byte(method)); err != nil {
+		return nil, fmt.Errorf("failed to encode XML-RPC request: %w", err)
+	}
+	body.WriteString("</methodName><params></params></methodCall>")
+
+	// The host is ignored, the transport always dials the unix socket
+	resp, err := s.client.Post("http://localhost/RPC2", "text/xml", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call supervisord at %s: %w", s.socketPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("supervisord returned HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read supervisord response: %w", err)
+	}
+
+	return ParseXMLRPCResponse(data)
+}
+
+// xmlrpcResponse mirrors an XML-RPC methodResponse document
+type xmlrpcResponse struct {
+	Params []xmlrpcValue `xml:"params>param>value"`
+	Fault  *xmlrpcValue  `xml:"fault>value"`
+}
+
+// xmlrpcValue mirrors a single XML-RPC <value> element
+type xmlrpcValue struct {
+	Int     *string        `xml:"int"`
+	I4      *string        `xml:"i4"`
+	Boolean *string        `xml:"boolean"`
+	Double  *string        `xml:"double"`
+	String  *string        `xml:"string"`
+	Array   *[]xmlrpcValue `xml:"array>data>value"`
+	Members *[]struct {
+		Name  string      `xml:"name"`
+		Value xmlrpcValue `xml:"value"`
+	} `xml:"struct>member"`
+	Text string `xml:",chardata"`
+}
+
+// ParseXMLRPCResponse decodes an XML-RPC methodResponse into Go values
+func ParseXMLRPCResponse(data []byte) (interface{}, error) {
+	var resp xmlrpcResponse
+	if err := xml.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse XML-RPC response: %w", err)
+	}
+
+	if resp.Fault != nil {
+		fault, _ := resp.Fault.decode().(map[string]interface{})
+		return nil, fmt.Errorf("XML-RPC fault %v: %v", fault["faultCode"], fault["faultString"])
+	}
+
+	if len(resp.Params) == 0 {
+		return nil, fmt.Errorf("XML-RPC response has no result")
+	}
+
+	return resp.Params[0].decode(), nil
+}
+
+// decode converts an XML-RPC value into int, bool, float64, string, []interface{} or map[string]interface{}
+func (v xmlrpcValue) decode() interface{} {
+	switch {
+	case v.Int != nil:
+		n, _ := strconv.Atoi(strings.TrimSpace(*v.Int))
+		return n
+	case v.I4 != nil:
+		n, _ := strconv.Atoi(strings.TrimSpace(*v.I4))
+		return n
+	case v.Boolean != nil:
+		return strings.TrimSpace(*v.Boolean) == "1"
+	case v.Double != nil:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+		return f
+	case v.String != nil:
+		return *v.String
+	case v.Array != nil:
+		values := make([]interface{}, 0, len(*v.Array))
+		for _, item := range *v.Array {
+			values = append(values, item.decode())
+		}
+		return values
+	case v.Members != nil:
+		members := make(map[string]interface{}, len(*v.Members))
+		for _, member := range *v.Members {
+			members[member.Name] = member.Value.decode()
+		}
+		return members
+	default:
+		// Values without a type element are strings
+		return v.Text
+	}
+}
+
+// parseSupervisorProcessInfo converts the result of supervisor.getAllProcessInfo,
+// also returning the last start time of each process keyed by full name
+func parseSupervisorProcessInfo(value interface{}, now time.Time) ([]monitor.SupervisorProcessStatus, map[string]int, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected supervisord process info type %T", value)
+	}
+
+	processes := make([]monitor.SupervisorProcessStatus, 0, len(items))
+	starts := make(map[string]int, len(items))
+	for _, item := range items {
+		info, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		proc := monitor.SupervisorProcessStatus{
+			Name:        xmlrpcString(info["name"]),
+			Group:       xmlrpcString(info["group"]),
+			State:       xmlrpcString(info["statename"]),
+			StateCode:   xmlrpcInt(info["state"]),
+			Description: xmlrpcString(info["description"]),
+			PID:         xmlrpcInt(info["pid"]),
+			ExitStatus:  xmlrpcInt(info["exitstatus"]),
+			SpawnError:  xmlrpcString(info["spawnerr"]),
+			Timestamp:   now,
+		}
+		proc.FullName = proc.Group + ":" + proc.Name
+		proc.Site = SiteForSupervisorGroup(proc.Group)
+
+		start := xmlrpcInt(info["start"])
+		starts[proc.FullName] = start
+		if proc.State == "RUNNING" && start > 0 {
+			// Use supervisord's clock to avoid skew with the local one
+			reference := int64(xmlrpcInt(info["now"]))
+			if reference == 0 {
+				reference = now.Unix()
+			}
+			proc.Uptime = time.Duration(reference-int64(start)) * time.Second
+		}
+
+		processes = append(processes, proc)
+	}
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].FullName < processes[j].FullName
+	})
+
+	return processes, starts, nil
+}
+
+// trackRestarts counts new process starts and fills in Restarts. supervisord
+// updates the start time on every spawn attempt, including BACKOFF retries.
+func (s *SupervisorCollector) trackRestarts(processes []monitor.SupervisorProcessStatus, starts map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range processes {
+		proc := &processes[i]
+		start := int64(starts[proc.FullName])
+
+		previous, seen := s.lastStarts[proc.FullName]
+		if seen && start > 0 && start != previous {
+			s.restarts[proc.FullName]++
+		}
+		s.lastStarts[proc.FullName] = start
+
+		proc.Restarts = s.restarts[proc.FullName]
+	}
+}
+
+// SiteForSupervisorGroup returns the Laravel site owning a queue worker program group
+func SiteForSupervisorGroup(group string) string {
+	for _, prefix := range laravelWorkerPrefixes {
+		if strings.HasPrefix(group, prefix) {
+			return strings.TrimPrefix(group, prefix)
+		}
+	}
+	return ""
+}
+
+// xmlrpcString returns a decoded XML-RPC value as a string
+func xmlrpcString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+// xmlrpcInt returns a decoded XML-RPC value as an int
+func xmlrpcInt(value interface{}) int {
+	if n, ok := value.(int); ok {
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,320 @@
+package collectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// variablePattern matches ${name} and ${env:NAME} references in step fields
+var variablePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// performTransaction runs the steps of a synthetic transaction check in order,
+// sharing cookies and extracted variables between them. The run stops at the
+// first failing step and is reported as a single result.
+func (h *HTTPCollector) performTransaction(check monitor.HTTPCheck) monitor.HTTPCheckResult {
+	startTime := time.Now()
+	result := monitor.HTTPCheckResult{
+		Name:      check.Name,
+		URL:       check.URL,
+		Timestamp: startTime,
+		Success:   true,
+	}
+	if result.URL == "" {
+		result.URL = displayURL(check.Steps[0].URL, check.Variables)
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		result.Success = false
+		result.Error = fmt.Sprintf("Failed to create cookie jar: %v", err)
+		return result
+	}
+	client := &http.Client{
+		Transport: h.client.Transport,
+		Jar:       jar,
+		Timeout:   check.GetTimeout(),
+	}
+
+	variables := make(map[string]string, len(check.Variables))
+	for name, value := range check.Variables {
+		variables[name] = value
+	}
+
+	for i, step := range check.Steps {
+		stepResult, resp, body := runStep(client, step, variables)
+		if stepResult.Name == "" {
+			stepResult.Name = fmt.Sprintf("step %d", i+1)
+		}
+		stepResult.URL = displayURL(step.URL, check.Variables)
+		result.Steps = append(result.Steps, stepResult)
+
+		result.StatusCode = stepResult.StatusCode
+		result.ContentLength = int64(len(body))
+		if resp != nil && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 && result.SSLExpiry == nil {
+			expiry := resp.TLS.PeerCertificates[0].NotAfter
+			result.SSLExpiry = &expiry
+		}
+
+		if !stepResult.Success {
+			result.Success = false
+			result.FailedStep = stepResult.Name
+			result.Error = fmt.Sprintf("step %d (%s): %s", i+1, stepResult.Name, stepResult.Error)
+			break
+		}
+	}
+
+	result.ResponseTime = time.Since(startTime)
+	return result
+}
+
+// runStep performs a single transaction step, checking its assertions and
+// storing extracted values in variables
+func runStep(client *http.Client, step monitor.HTTPStep, variables map[string]string) (monitor.HTTPStepResult, *http.Response, []byte) {
+	method := strings.ToUpper(step.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	result := monitor.HTTPStepResult{
+		Name:   step.Name,
+		Method: method,
+	}
+
+	fail := func(format string, args ...interface{}) {
+		result.Error = fmt.Sprintf(format, args...)
+	}
+
+	url, err := substituteVariables(step.URL, variables)
+	if err != nil {
+		fail("%v", err)
+		return result, nil, nil
+	}
+	requestBody, err := substituteVariables(step.Body, variables)
+	if err != nil {
+		fail("%v", err)
+		return result, nil, nil
+	}
+
+	req, err := http.NewRequest(method, url, strings.NewReader(requestBody))
+	if err != nil {
+		fail("Failed to create request: %v", err)
+		return result, nil, nil
+	}
+	req.Header.Set("User-Agent", "Crucible-Monitor/1.0.0")
+	for name, value := range step.Headers {
+		value, err := substituteVariables(value, variables)
+		if err != nil {
+			fail("header %s: %v", name, err)
+			return result, nil, nil
+		}
+		req.Header.Set(name, value)
+	}
+
+	startTime := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.ResponseTime = time.Since(startTime)
+		fail("Request failed: %v", err)
+		return result, nil, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	result.ResponseTime = time.Since(startTime)
+	result.StatusCode = resp.StatusCode
+	if err != nil {
+		fail("Failed to read response body: %v", err)
+		return result, resp, nil
+	}
+
+	expectedStatus := step.ExpectedStatus
+	if expectedStatus == 0 {
+		expectedStatus = 200
+	}
+	if resp.StatusCode != expectedStatus {
+		fail("Unexpected status code: got %d, expected %d", resp.StatusCode, expectedStatus)
+		return result, resp, body
+	}
+
+	for _, assertion := range step.Assert {
+		if err := checkAssertion(assertion, resp, body, result.ResponseTime); err != nil {
+			fail("assertion failed: %v", err)
+			return result, resp, body
+		}
+	}
+
+	for _, extract := range step.Extract {
+		value, err := extractValue(extract, resp, body)
+		if err != nil {
+			fail("extract %s: %v", extract.Var, err)
+			return result, resp, body
+		}
+		variables[extract.Var] = value
+	}
+
+	result.Success = true
+	return result, resp, body
+}
+
+// substituteVariables replaces ${name} with extracted or configured variables
+// and ${env:NAME} with environment variables
+func substituteVariables(text string, variables map[string]string) (string, error) {
+	var missing string
+	replaced := variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if env, ok := strings.CutPrefix(name, "env:"); ok {
+			if value, ok := os.LookupEnv(env); ok {
+				return value
+			}
+		} else if value, ok := variables[name]; ok {
+			return value
+		}
+		if missing == "" {
+			missing = name
+		}
+		return ref
+	})
+	if missing != "" {
+		return "", fmt.Errorf("undefined variable %s", missing)
+	}
+	return replaced, nil
+}
+
+// displayURL resolves the configured variables in a step URL for results,
+// leaving extracted and environment values out of stored URLs
+func displayURL(text string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		if value, ok := variables[ref[2:len(ref)-1]]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// checkAssertion checks a single step assertion against the response
+func checkAssertion(assertion monitor.HTTPAssertion, resp *http.Response, body []byte, responseTime time.Duration) error {
+	switch {
+	case assertion.BodyContains != "":
+		if !bytes.Contains(body, []byte(assertion.BodyContains)) {
+			return fmt.Errorf("body does not contain %q", assertion.BodyContains)
+		}
+	case assertion.BodyRegex != "":
+		pattern, err := regexp.Compile(assertion.BodyRegex)
+		if err != nil {
+			return fmt.Errorf("invalid body_regex: %w", err)
+		}
+		if !pattern.Match(body) {
+			return fmt.Errorf("body does not match %q", assertion.BodyRegex)
+		}
+	case assertion.JSON != "":
+		value, err := jsonPathValue(body, assertion.JSON)
+		if err != nil {
+			return err
+		}
+		if assertion.Equals != "" && value != assertion.Equals {
+			return fmt.Errorf("%s is %q, expected %q", assertion.JSON, value, assertion.Equals)
+		}
+	case assertion.Header != "":
+		value := resp.Header.Get(assertion.Header)
+		if value == "" {
+			return fmt.Errorf("header %s is missing", assertion.Header)
+		}
+		if assertion.Equals != "" && value != assertion.Equals {
+			return fmt.Errorf("header %s is %q, expected %q", assertion.Header, value, assertion.Equals)
+		}
+	case assertion.MaxResponseTime != "":
+		limit, err := time.ParseDuration(assertion.MaxResponseTime)
+		if err != nil {
+			return fmt.Errorf("invalid max_response_time: %w", err)
+		}
+		if responseTime > limit {
+			return fmt.Errorf("response time %dms exceeds %dms", responseTime.Milliseconds(), limit.Milliseconds())
+		}
+	}
+	return nil
+}
+
+// extractValue returns the part of a response selected by an extraction
+func extractValue(extract monitor.HTTPExtract, resp *http.Response, body []byte) (string, error) {
+	switch {
+	case extract.JSON != "":
+		return jsonPathValue(body, extract.JSON)
+	case extract.Regex != "":
+		pattern, err := regexp.Compile(extract.Regex)
+		if err != nil {
+			return "", fmt.Errorf("invalid regex: %w", err)
+		}
+		match := pattern.FindSubmatch(body)
+		if match == nil {
+			return "", fmt.Errorf("regex %q did not match", extract.Regex)
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	case extract.Header != "":
+		value := resp.Header.Get(extract.Header)
+		if value == "" {
+			return "", fmt.Errorf("header %s is missing", extract.Header)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("no extraction source")
+}
+
+// jsonPathValue returns the value at a dot path such as data.token or
+// items.0.id in a JSON body, with objects and arrays rendered as JSON
+func jsonPathValue(body []byte, path string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("failed to parse JSON body: %w", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			child, ok := node[key]
+			if !ok {
+				return "", fmt.Errorf("JSON path %s not found", path)
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", fmt.Errorf("JSON path %s not found", path)
+			}
+			value = node[index]
+		default:
+			return "", fmt.Errorf("JSON path %s not found", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON value: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -13,11 +14,24 @@ import (
 )
 
 // SystemCollector collects system-wide metrics using /proc filesystem
-type SystemCollector struct{}
+type SystemCollector struct {
+	mu sync.Mutex
+
+	// Previous /proc/diskstats sample used to compute rates
+	lastDiskStats     map[string]diskStatsSample
+	lastDiskStatsTime time.Time
+
+	// Previous /proc/net/dev sample used to compute rates
+	lastNetStats     map[string]monitor.NetworkMetrics
+	lastNetStatsTime time.Time
+}
 
 // NewSystemCollector creates a new system metrics collector
 func NewSystemCollector() *SystemCollector {
-	return &SystemCollector{}
+	return &SystemCollector{
+		lastDiskStats: make(map[string]diskStatsSample),
+		lastNetStats:  make(map[string]monitor.NetworkMetrics),
+	}
 }
 
 // Collect gathers current system metrics
@@ -59,8 +73,26 @@ func (s *SystemCollector) Collect() (*monitor.SystemMetrics, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect network metrics: %w", err)
 	}
+	s.applyNetworkRates(networkMetrics, metrics.Timestamp)
 	metrics.Network = networkMetrics
 
+	// Collect TCP connection states
+	tcpMetrics, err := s.collectTCPMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect TCP metrics: %w", err)
+	}
+	metrics.TCP = tcpMetrics
+
+	// Collect disk I/O metrics
+	diskIOMetrics, err := s.collectDiskIOMetrics(metrics.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect disk I/O metrics: %w", err)
+	}
+	metrics.DiskIO = diskIOMetrics
+
+	// Collect pressure stall information, which older kernels do not provide
+	metrics.Pressure = s.collectPressureMetrics()
+
 	return metrics, nil
 }
 
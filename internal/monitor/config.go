@@ -2,8 +2,12 @@ package monitor
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -49,6 +53,7 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := validateAndSetDefaults(&config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
+	config.path = configFile
 
 	return &config, nil
 }
@@ -88,9 +93,22 @@ func validateAndSetDefaults(config *Config) error {
 	if config.Storage.Type == "" {
 		config.Storage.Type = "memory"
 	}
+	switch config.Storage.Type {
+	case "memory", "sqlite", "none":
+	default:
+		return fmt.Errorf("invalid storage type: %s", config.Storage.Type)
+	}
 	if config.Storage.SQLite.Path == "" {
 		config.Storage.SQLite.Path = "/var/lib/crucible/monitor.db"
 	}
+	if config.Storage.SQLite.Driver == "" {
+		config.Storage.SQLite.Driver = "auto"
+	}
+	switch config.Storage.SQLite.Driver {
+	case "auto", "cgo", "purego":
+	default:
+		return fmt.Errorf("invalid sqlite driver: %s", config.Storage.SQLite.Driver)
+	}
 	if config.Storage.SQLite.BatchSize == 0 {
 		config.Storage.SQLite.BatchSize = 100
 	}
@@ -109,6 +127,57 @@ func validateAndSetDefaults(config *Config) error {
 	if config.Storage.SQLite.Retention.AggregatesDays == 0 {
 		config.Storage.SQLite.Retention.AggregatesDays = 365
 	}
+	if err := validateRetentionPolicy(&config.Storage.SQLite.RetentionPolicy); err != nil {
+		return fmt.Errorf("invalid retention_policy: %w", err)
+	}
+	if config.Storage.Memory.MaxEvents == 0 {
+		config.Storage.Memory.MaxEvents = 10000
+	}
+	if config.Storage.Memory.MaxMetrics == 0 {
+		config.Storage.Memory.MaxMetrics = 100000
+	}
+	remoteWriteNames := make(map[string]bool)
+	for i := range config.Storage.RemoteWrite {
+		remote := &config.Storage.RemoteWrite[i]
+		if remote.Name == "" {
+			return fmt.Errorf("remote write %d: name is required", i)
+		}
+		if remoteWriteNames[remote.Name] {
+			return fmt.Errorf("remote write %s: duplicate name", remote.Name)
+		}
+		remoteWriteNames[remote.Name] = true
+
+		switch remote.Type {
+		case "prometheus", "influxdb", "otlp":
+		default:
+			return fmt.Errorf("remote write %s: invalid type %q, must be prometheus, influxdb or otlp", remote.Name, remote.Type)
+		}
+		if remote.URL == "" {
+			return fmt.Errorf("remote write %s: url is required", remote.Name)
+		}
+
+		if remote.Timeout == "" {
+			remote.Timeout = "10s"
+		}
+		if _, err := time.ParseDuration(remote.Timeout); err != nil {
+			return fmt.Errorf("remote write %s: invalid timeout: %w", remote.Name, err)
+		}
+		if remote.FlushInterval == "" {
+			remote.FlushInterval = "15s"
+		}
+		if _, err := time.ParseDuration(remote.FlushInterval); err != nil {
+			return fmt.Errorf("remote write %s: invalid flush_interval: %w", remote.Name, err)
+		}
+		if remote.BatchSize == 0 {
+			remote.BatchSize = 500
+		}
+		if remote.QueueDir == "" {
+			remote.QueueDir = filepath.Join(filepath.Dir(config.Storage.SQLite.Path), "remote-write", remote.Name)
+		}
+		if remote.MaxQueueBatches == 0 {
+			remote.MaxQueueBatches = 1000
+		}
+	}
 	if config.Storage.Aggregation.RawRetention == "" {
 		config.Storage.Aggregation.RawRetention = "24h"
 	}
@@ -126,6 +195,24 @@ func validateAndSetDefaults(config *Config) error {
 	if config.Collectors.Services.Interval == "" {
 		config.Collectors.Services.Interval = "60s"
 	}
+	if config.Collectors.PM2.Interval == "" {
+		config.Collectors.PM2.Interval = "30s"
+	}
+	if config.Collectors.PM2.CrashLoopWindow == "" {
+		config.Collectors.PM2.CrashLoopWindow = "10m"
+	}
+	if config.Collectors.Supervisor.Interval == "" {
+		config.Collectors.Supervisor.Interval = "30s"
+	}
+	if config.Collectors.Supervisor.SocketPath == "" {
+		config.Collectors.Supervisor.SocketPath = "/var/run/supervisor.sock"
+	}
+	if len(config.Collectors.Journal.Units) == 0 {
+		config.Collectors.Journal.Units = config.Collectors.Services.Services
+	}
+	if len(config.Collectors.Journal.Patterns) == 0 {
+		config.Collectors.Journal.Patterns = DefaultJournalPatterns()
+	}
 
 	// Validate collector intervals
 	if config.Collectors.System.Enabled {
@@ -139,13 +226,47 @@ func validateAndSetDefaults(config *Config) error {
 		}
 	}
 
+	if config.Collectors.PM2.Enabled {
+		if _, err := time.ParseDuration(config.Collectors.PM2.Interval); err != nil {
+			return fmt.Errorf("invalid pm2 collector interval: %w", err)
+		}
+		if _, err := time.ParseDuration(config.Collectors.PM2.CrashLoopWindow); err != nil {
+			return fmt.Errorf("invalid pm2 crash_loop_window: %w", err)
+		}
+	}
+
+	if config.Collectors.Supervisor.Enabled {
+		if _, err := time.ParseDuration(config.Collectors.Supervisor.Interval); err != nil {
+			return fmt.Errorf("invalid supervisor collector interval: %w", err)
+		}
+	}
+
+	if config.Collectors.Journal.Enabled {
+		for i, pattern := range config.Collectors.Journal.Patterns {
+			if pattern.Name == "" {
+				return fmt.Errorf("journal pattern %d: name is required", i)
+			}
+			if _, err := regexp.Compile(pattern.Pattern); err != nil {
+				return fmt.Errorf("journal pattern %s: invalid pattern: %w", pattern.Name, err)
+			}
+			switch pattern.Severity {
+			case "info", "warning", "error", "critical":
+			default:
+				return fmt.Errorf("journal pattern %s: invalid severity %q", pattern.Name, pattern.Severity)
+			}
+		}
+	}
+
 	// Validate HTTP checks
 	for i, check := range config.Collectors.HTTPChecks.Checks {
 		if check.Name == "" {
 			return fmt.Errorf("HTTP check %d: name is required", i)
 		}
-		if check.URL == "" {
-			return fmt.Errorf("HTTP check %s: URL is required", check.Name)
+		if check.URL == "" && len(check.Steps) == 0 {
+			return fmt.Errorf("HTTP check %s: URL or steps are required", check.Name)
+		}
+		if err := check.ValidateSteps(); err != nil {
+			return fmt.Errorf("HTTP check %s: %w", check.Name, err)
 		}
 		if check.Interval == "" {
 			config.Collectors.HTTPChecks.Checks[i].Interval = "60s"
@@ -166,14 +287,124 @@ func validateAndSetDefaults(config *Config) error {
 		}
 	}
 
+	// Validate probes
+	probeNames := make(map[string]bool)
+	for i, probe := range config.Collectors.Probes.Checks {
+		if probe.Name == "" {
+			return fmt.Errorf("probe %d: name is required", i)
+		}
+		if probeNames[probe.Name] {
+			return fmt.Errorf("probe %s: duplicate name", probe.Name)
+		}
+		probeNames[probe.Name] = true
+
+		if probe.Target == "" {
+			return fmt.Errorf("probe %s: target is required", probe.Name)
+		}
+		switch probe.Type {
+		case "tcp", "tls":
+			if _, _, err := net.SplitHostPort(probe.Target); err != nil {
+				return fmt.Errorf("probe %s: target must be host:port: %w", probe.Name, err)
+			}
+		case "dns":
+			if probe.RecordType == "" {
+				config.Collectors.Probes.Checks[i].RecordType = "A"
+			}
+			switch strings.ToUpper(config.Collectors.Probes.Checks[i].RecordType) {
+			case "A", "AAAA", "CNAME", "MX", "NS", "TXT":
+			default:
+				return fmt.Errorf("probe %s: unsupported record type %q", probe.Name, probe.RecordType)
+			}
+		case "icmp":
+			if probe.Count <= 0 {
+				config.Collectors.Probes.Checks[i].Count = 3
+			}
+		default:
+			return fmt.Errorf("probe %s: invalid type %q", probe.Name, probe.Type)
+		}
+
+		if probe.Interval == "" {
+			config.Collectors.Probes.Checks[i].Interval = "60s"
+		}
+		if probe.Timeout == "" {
+			config.Collectors.Probes.Checks[i].Timeout = "5s"
+		}
+		if _, err := time.ParseDuration(config.Collectors.Probes.Checks[i].Interval); err != nil {
+			return fmt.Errorf("probe %s: invalid interval: %w", probe.Name, err)
+		}
+		if _, err := time.ParseDuration(config.Collectors.Probes.Checks[i].Timeout); err != nil {
+			return fmt.Errorf("probe %s: invalid timeout: %w", probe.Name, err)
+		}
+	}
+
+	// Discovery defaults
+	if config.Discovery.CaddySitesDir == "" {
+		config.Discovery.CaddySitesDir = "/etc/caddy/sites"
+	}
+	if config.Discovery.CheckInterval == "" {
+		config.Discovery.CheckInterval = "60s"
+	}
+	if _, err := time.ParseDuration(config.Discovery.CheckInterval); err != nil {
+		return fmt.Errorf("invalid discovery check_interval: %w", err)
+	}
+
+	// Validate exec collectors
+	execNames := make(map[string]bool)
+	for i, execConfig := range config.Collectors.Exec {
+		if execConfig.Name == "" {
+			return fmt.Errorf("exec collector %d: name is required", i)
+		}
+		if execNames[execConfig.Name] {
+			return fmt.Errorf("exec collector %s: duplicate name", execConfig.Name)
+		}
+		execNames[execConfig.Name] = true
+
+		if execConfig.Command == "" {
+			return fmt.Errorf("exec collector %s: command is required", execConfig.Name)
+		}
+		if execConfig.Interval == "" {
+			config.Collectors.Exec[i].Interval = "60s"
+		}
+		if execConfig.Timeout == "" {
+			config.Collectors.Exec[i].Timeout = "10s"
+		}
+		if execConfig.Format == "" {
+			config.Collectors.Exec[i].Format = "nagios"
+		}
+
+		switch config.Collectors.Exec[i].Format {
+		case "nagios", "json", "influx":
+		default:
+			return fmt.Errorf("exec collector %s: invalid format %q", execConfig.Name, execConfig.Format)
+		}
+		if _, err := time.ParseDuration(config.Collectors.Exec[i].Interval); err != nil {
+			return fmt.Errorf("exec collector %s: invalid interval: %w", execConfig.Name, err)
+		}
+		if _, err := time.ParseDuration(config.Collectors.Exec[i].Timeout); err != nil {
+			return fmt.Errorf("exec collector %s: invalid timeout: %w", execConfig.Name, err)
+		}
+	}
+
 	// Alert defaults
+	if config.Alerts.RulesFile == "" {
+		config.Alerts.RulesFile = "configs/alerts.yaml"
+	}
 	if config.Alerts.CheckInterval == "" {
 		config.Alerts.CheckInterval = "60s"
 	}
+	if config.Alerts.Incidents.Lookback == "" {
+		config.Alerts.Incidents.Lookback = "30m"
+	}
+	if config.Alerts.Incidents.MaxEvents <= 0 {
+		config.Alerts.Incidents.MaxEvents = 500
+	}
 	if config.Alerts.Enabled {
 		if _, err := time.ParseDuration(config.Alerts.CheckInterval); err != nil {
 			return fmt.Errorf("invalid alert check_interval: %w", err)
 		}
+		if _, err := time.ParseDuration(config.Alerts.Incidents.Lookback); err != nil {
+			return fmt.Errorf("invalid incidents lookback: %w", err)
+		}
 
 		// Set default thresholds if not provided
 		if config.Alerts.Thresholds.CPUPercent == 0 {
@@ -193,6 +424,114 @@ func validateAndSetDefaults(config *Config) error {
 		}
 	}
 
+	// Anomaly detection defaults
+	anomaly := &config.AI.AnomalyDetection
+	if anomaly.Sensitivity == "" {
+		anomaly.Sensitivity = "medium"
+	}
+	switch anomaly.Sensitivity {
+	case "low", "medium", "high":
+	default:
+		return fmt.Errorf("invalid anomaly_detection sensitivity %q: must be low, medium or high", anomaly.Sensitivity)
+	}
+	if anomaly.LearningPeriod == "" {
+		anomaly.LearningPeriod = "7d"
+	}
+	if _, err := ParseRetentionDuration(anomaly.LearningPeriod); err != nil {
+		return fmt.Errorf("invalid anomaly_detection learning_period: %w", err)
+	}
+	if len(anomaly.Metrics) == 0 {
+		anomaly.Metrics = []string{"cpu_usage", "memory_usage", "load_1", "response_time_ms"}
+	}
+
+	// Status page defaults
+	statusPage := &config.StatusPage
+	if statusPage.Title == "" {
+		statusPage.Title = "Service Status"
+	}
+	if statusPage.Interval == "" {
+		statusPage.Interval = "5m"
+	}
+	if statusPage.Days == 0 {
+		statusPage.Days = 90
+	}
+	if statusPage.Enabled {
+		if interval, err := time.ParseDuration(statusPage.Interval); err != nil || interval <= 0 {
+			return fmt.Errorf("invalid status_page interval %q", statusPage.Interval)
+		}
+		if statusPage.Days < 1 || statusPage.Days > 365 {
+			return fmt.Errorf("invalid status_page days %d: must be between 1 and 365", statusPage.Days)
+		}
+		if statusPage.ListenAddr != "" && statusPage.ListenAddr == config.Agent.ListenAddr {
+			return fmt.Errorf("status_page listen_addr must differ from the agent listen_addr")
+		}
+		for i, group := range statusPage.Groups {
+			if group.Name == "" {
+				return fmt.Errorf("status_page group %d: name is required", i)
+			}
+			for _, component := range group.Components {
+				if component.Check == "" {
+					return fmt.Errorf("status_page group %s: component check is required", group.Name)
+				}
+			}
+		}
+		for i, window := range statusPage.Maintenance {
+			if window.Title == "" {
+				return fmt.Errorf("status_page maintenance %d: title is required", i)
+			}
+			start, err := time.Parse(time.RFC3339, window.Start)
+			if err != nil {
+				return fmt.Errorf("status_page maintenance %s: invalid start: %w", window.Title, err)
+			}
+			end, err := time.Parse(time.RFC3339, window.End)
+			if err != nil {
+				return fmt.Errorf("status_page maintenance %s: invalid end: %w", window.Title, err)
+			}
+			if !end.After(start) {
+				return fmt.Errorf("status_page maintenance %s: end must be after start", window.Title)
+			}
+		}
+	}
+
+	// Validate SLOs
+	sloNames := make(map[string]bool)
+	for i := range config.SLOs {
+		slo := &config.SLOs[i]
+		if slo.Check == "" {
+			return fmt.Errorf("SLO %d: check is required", i)
+		}
+		if slo.Type == "" {
+			slo.Type = "availability"
+		}
+		if slo.Name == "" {
+			slo.Name = slo.Check + "-" + slo.Type
+		}
+		if sloNames[slo.Name] {
+			return fmt.Errorf("SLO %s: duplicate name", slo.Name)
+		}
+		sloNames[slo.Name] = true
+
+		switch slo.Type {
+		case "availability":
+		case "latency":
+			threshold, err := time.ParseDuration(slo.Threshold)
+			if err != nil || threshold <= 0 {
+				return fmt.Errorf("SLO %s: latency SLOs need a threshold such as 500ms", slo.Name)
+			}
+		default:
+			return fmt.Errorf("SLO %s: invalid type %q: must be availability or latency", slo.Name, slo.Type)
+		}
+		if slo.Objective <= 0 || slo.Objective >= 100 {
+			return fmt.Errorf("SLO %s: objective must be between 0 and 100 percent", slo.Name)
+		}
+		if slo.Window == "" {
+			slo.Window = "30d"
+		}
+		if window, err := ParseRetentionDuration(slo.Window); err != nil || window < time.Hour {
+			return fmt.Errorf("SLO %s: invalid window %q: must be at least 1h", slo.Name, slo.Window)
+		}
+	}
+
 	// Notification defaults
 	if config.Notifications.Email.SMTPPort == 0 {
 		config.Notifications.Email.SMTPPort = 587
@@ -226,6 +565,55 @@ func SaveConfig(config *Config, configPath string) error {
 	return nil
 }
 
+// validateRetentionPolicy checks that all retention durations parse
+func validateRetentionPolicy(policy *RetentionPolicy) error {
+	for section, durations := range map[string]map[string]string{
+		"entity_types": policy.EntityTypes,
+		"event_types":  policy.EventTypes,
+		"metric_types": policy.MetricTypes,
+		"global": {
+			"max_event_age":   policy.Global.MaxEventAge,
+			"max_metric_age":  policy.Global.MaxMetricAge,
+			"aggregation_age": policy.Global.AggregationAge,
+		},
+	} {
+		for name, value := range durations {
+			if value == "" && section == "global" {
+				continue
+			}
+			if _, err := ParseRetentionDuration(value); err != nil {
+				return fmt.Errorf("%s.%s: %w", section, name, err)
+			}
+		}
+	}
+	if policy.Global.MaxDatabaseSize < 0 {
+		return fmt.Errorf("global.max_database_size_bytes must not be negative")
+	}
+	return nil
+}
+
+// ParseRetentionDuration parses a Go duration or a number of days such as "30d"
+func ParseRetentionDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		duration = d
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("duration must be positive: %s", value)
+	}
+	return duration, nil
+}
+
 // GetCollectInterval parses and returns the collect interval as a duration
 func (c *Config) GetCollectInterval() time.Duration {
 	duration, _ := time.ParseDuration(c.Agent.CollectInterval)
@@ -244,12 +632,98 @@ func (c *Config) GetServicesCollectorInterval() time.Duration {
 	return duration
 }
 
+// GetPM2CollectorInterval parses and returns the PM2 collector interval as a duration
+func (c *Config) GetPM2CollectorInterval() time.Duration {
+	duration, _ := time.ParseDuration(c.Collectors.PM2.Interval)
+	return duration
+}
+
+// GetPM2CrashLoopWindow parses and returns the PM2 crash loop window as a duration
+func (c *Config) GetPM2CrashLoopWindow() time.Duration {
+	duration, _ := time.ParseDuration(c.Collectors.PM2.CrashLoopWindow)
+	return duration
+}
+
+// GetSupervisorCollectorInterval parses and returns the supervisor collector interval as a duration
+func (c *Config) GetSupervisorCollectorInterval() time.Duration {
+	duration, _ := time.ParseDuration(c.Collectors.Supervisor.Interval)
+	return duration
+}
+
+// DefaultJournalPatterns returns the journal patterns used when none are configured
+func DefaultJournalPatterns() []JournalPattern {
+	return []JournalPattern{
+		{
+			Name:     "oom-kill",
+			Pattern:  `Out of memory: Killed process|oom-kill:|invoked oom-killer`,
+			Severity: "critical",
+			Unit:     "kernel",
+		},
+		{
+			Name:     "sshd-auth-failure",
+			Pattern:  `Failed password for|Invalid user|authentication failure`,
+			Severity: "warning",
+			Unit:     "sshd",
+		},
+		{
+			Name:     "mysqld-crash",
+			Pattern:  `mysqld got signal|Assertion failure|InnoDB: Database page corruption`,
+			Severity: "critical",
+		},
+		{
+			Name:     "service-failed",
+			Pattern:  `\.service: Failed with result`,
+			Severity: "error",
+		},
+	}
+}
+
+// Path returns the file the configuration was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
 // GetAlertCheckInterval parses and returns the alert check interval as a duration
 func (c *Config) GetAlertCheckInterval() time.Duration {
 	duration, _ := time.ParseDuration(c.Alerts.CheckInterval)
 	return duration
 }
 
+// GetIncidentLookback parses and returns how far before an alert incident timelines start
+func (c *Config) GetIncidentLookback() time.Duration {
+	duration, _ := time.ParseDuration(c.Alerts.Incidents.Lookback)
+	return duration
+}
+
+// GetStatusPageInterval returns how often the status page is rebuilt
+func (c *Config) GetStatusPageInterval() time.Duration {
+	duration, _ := time.ParseDuration(c.StatusPage.Interval)
+	return duration
+}
+
+// GetWindow returns the rolling window of an SLO
+func (slo *SLOConfig) GetWindow() time.Duration {
+	duration, _ := ParseRetentionDuration(slo.Window)
+	return duration
+}
+
+// GetThreshold returns the latency threshold of an SLO
+func (slo *SLOConfig) GetThreshold() time.Duration {
+	duration, _ := time.ParseDuration(slo.Threshold)
+	return duration
+}
+
+// IsAnomalyDetectionEnabled reports whether the AI settings turn on anomaly detection
+func (c *Config) IsAnomalyDetectionEnabled() bool {
+	return c.AI.Enabled && c.AI.AnomalyDetection.Enabled
+}
+
+// GetAnomalyLearningPeriod parses and returns the anomaly detection learning period as a duration
+func (c *Config) GetAnomalyLearningPeriod() time.Duration {
+	duration, _ := ParseRetentionDuration(c.AI.AnomalyDetection.LearningPeriod)
+	return duration
+}
+
 // IsSystemMetricEnabled checks if a specific system metric is enabled
 func (c *Config) IsSystemMetricEnabled(metric string) bool {
 	if !c.Collectors.System.Enabled {
@@ -282,3 +756,86 @@ func (check *HTTPCheck) GetTimeout() time.Duration {
 	duration, _ := time.ParseDuration(check.Timeout)
 	return duration
 }
+
+// ValidateSteps validates the steps of a synthetic transaction check
+func (check *HTTPCheck) ValidateSteps() error {
+	for i, step := range check.Steps {
+		name := step.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		if step.URL == "" {
+			return fmt.Errorf("step %s: URL is required", name)
+		}
+		if step.ExpectedStatus < 0 || step.ExpectedStatus > 599 {
+			return fmt.Errorf("step %s: invalid expected status code: %d", name, step.ExpectedStatus)
+		}
+
+		for _, extract := range step.Extract {
+			if extract.Var == "" {
+				return fmt.Errorf("step %s: extract var is required", name)
+			}
+			sources := 0
+			for _, source := range []string{extract.JSON, extract.Regex, extract.Header} {
+				if source != "" {
+					sources++
+				}
+			}
+			if sources != 1 {
+				return fmt.Errorf("step %s: extract %s needs exactly one of json, regex or header", name, extract.Var)
+			}
+			if extract.Regex != "" {
+				if _, err := regexp.Compile(extract.Regex); err != nil {
+					return fmt.Errorf("step %s: extract %s: invalid regex: %w", name, extract.Var, err)
+				}
+			}
+		}
+
+		for _, assertion := range step.Assert {
+			checks := 0
+			for _, value := range []string{assertion.BodyContains, assertion.BodyRegex, assertion.JSON, assertion.Header, assertion.MaxResponseTime} {
+				if value != "" {
+					checks++
+				}
+			}
+			if checks != 1 {
+				return fmt.Errorf("step %s: assertion needs exactly one of body_contains, body_regex, json, header or max_response_time", name)
+			}
+			if assertion.BodyRegex != "" {
+				if _, err := regexp.Compile(assertion.BodyRegex); err != nil {
+					return fmt.Errorf("step %s: invalid body_regex: %w", name, err)
+				}
+			}
+			if assertion.MaxResponseTime != "" {
+				if _, err := time.ParseDuration(assertion.MaxResponseTime); err != nil {
+					return fmt.Errorf("step %s: invalid max_response_time: %w", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetInterval parses and returns the probe interval as a duration
+func (probe *ProbeCheck) GetInterval() time.Duration {
+	duration, _ := time.ParseDuration(probe.Interval)
+	return duration
+}
+
+// GetTimeout parses and returns the probe timeout as a duration
+func (probe *ProbeCheck) GetTimeout() time.Duration {
+	duration, _ := time.ParseDuration(probe.Timeout)
+	return duration
+}
+
+// GetInterval parses and returns the exec collector interval as a duration
+func (c *ExecCollectorConfig) GetInterval() time.Duration {
+	duration, _ := time.ParseDuration(c.Interval)
+	return duration
+}
+
+// GetTimeout parses and returns the exec collector timeout as a duration
+func (c *ExecCollectorConfig) GetTimeout() time.Duration {
+	duration, _ := time.ParseDuration(c.Timeout)
+	return duration
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConfigChange describes a single setting that differs between two configurations
+type ConfigChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DiffConfig returns the settings that differ between two configurations. Paths
+// use YAML keys, and list entries with a name are identified by it, e.g.
+// collectors.http_checks.checks[uxvalidate].timeout.
+func DiffConfig(old, new *Config) []ConfigChange {
+	var changes []ConfigChange
+	diffValues("", reflect.ValueOf(*old), reflect.ValueOf(*new), &changes)
+	return changes
+}
+
+// diffValues appends the differences between two values of the same type
+func diffValues(path string, old, new reflect.Value, changes *[]ConfigChange) {
+	switch old.Kind() {
+	case reflect.Struct:
+		t := old.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := yamlKey(field)
+			// Skip unexported and runtime-only fields such as Discovered
+			if !field.IsExported() || key == "-" {
+				continue
+			}
+			diffValues(joinPath(path, key), old.Field(i), new.Field(i), changes)
+		}
+		return
+	case reflect.Slice:
+		if hasNameField(old.Type().Elem()) {
+			diffNamedSlices(path, old, new, changes)
+			return
+		}
+		if old.Len() == 0 && new.Len() == 0 {
+			return
+		}
+	case reflect.Map:
+		if old.Len() == 0 && new.Len() == 0 {
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+		*changes = append(*changes, ConfigChange{Path: path, Old: old.Interface(), New: new.Interface()})
+	}
+}
+
+// diffNamedSlices compares list entries by name, reporting added and removed entries whole
+func diffNamedSlices(path string, old, new reflect.Value, changes *[]ConfigChange) {
+	oldItems := make(map[string]reflect.Value, old.Len())
+	for i := 0; i < old.Len(); i++ {
+		oldItems[old.Index(i).FieldByName("Name").String()] = old.Index(i)
+	}
+
+	seen := make(map[string]bool, new.Len())
+	for i := 0; i < new.Len(); i++ {
+		item := new.Index(i)
+		name := item.FieldByName("Name").String()
+		seen[name] = true
+
+		itemPath := fmt.Sprintf("%s[%s]", path, name)
+		if previous, exists := oldItems[name]; exists {
+			diffValues(itemPath, previous, item, changes)
+		} else {
+			*changes = append(*changes, ConfigChange{Path: itemPath, New: item.Interface()})
+		}
+	}
+
+	for i := 0; i < old.Len(); i++ {
+		item := old.Index(i)
+		name := item.FieldByName("Name").String()
+		if !seen[name] {
+			*changes = append(*changes, ConfigChange{Path: fmt.Sprintf("%s[%s]", path, name), Old: item.Interface()})
+		}
+	}
+}
+
+// hasNameField reports whether t is a struct with a string Name field
+func hasNameField(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := t.FieldByName("Name")
+	return ok && field.Type.Kind() == reflect.String
+}
+
+// yamlKey returns the YAML key of a struct field
+func yamlKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if key == "" {
+		return strings.ToLower(field.Name)
+	}
+	return key
+}
+
+// joinPath joins a parent path and a key with a dot
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
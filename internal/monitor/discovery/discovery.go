@@ -0,0 +1,240 @@
+package discovery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"crucible/internal/monitor"
+)
+
+// Systemd units installed by crucible that should be monitored when present
+var servicePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^php[0-9.]*-fpm$`),
+	regexp.MustCompile(`^caddy$`),
+	regexp.MustCompile(`^(mysql|mysqld|mariadb)$`),
+	regexp.MustCompile(`^(supervisor|supervisord)$`),
+	regexp.MustCompile(`^pm2-.+$`),
+}
+
+// Result holds the services and HTTP checks found on the host
+type Result struct {
+	Services   []string
+	HTTPChecks []monitor.HTTPCheck
+}
+
+// Discover finds crucible-managed services and Caddy sites on the host. The
+// two are independent, so a result holds whatever was found even when the
+// error reports that one of them failed, such as systemctl in a container.
+func Discover(config *monitor.Config) (*Result, error) {
+	result := &Result{}
+	var errs []error
+
+	units, err := listServiceUnits()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	result.Services = MatchServices(units)
+
+	domains, err := DiscoverCaddyDomains(config.Discovery.CaddySitesDir)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, domain := range domains {
+		result.HTTPChecks = append(result.HTTPChecks, monitor.HTTPCheck{
+			Name:           domain.Name,
+			URL:            domain.URL,
+			Interval:       config.Discovery.CheckInterval,
+			Timeout:        "10s",
+			ExpectedStatus: 200,
+			Discovered:     true,
+		})
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// Apply merges discovered items into the config, skipping excluded and
+// already configured ones. It returns the number of items added.
+func Apply(config *monitor.Config, result *Result) int {
+	excluded := make(map[string]bool, len(config.Discovery.Exclude))
+	for _, item := range config.Discovery.Exclude {
+		excluded[item] = true
+	}
+
+	added := 0
+
+	// Discovered services also fill an empty service list, which would
+	// otherwise monitor every unit on the host
+	services := &config.Collectors.Services
+	existing := make(map[string]bool, len(services.Services))
+	for _, name := range services.Services {
+		existing[name] = true
+	}
+	for _, name := range result.Services {
+		if existing[name] || excluded[name] {
+			continue
+		}
+		services.Services = append(services.Services, name)
+		services.Discovered = append(services.Discovered, name)
+		existing[name] = true
+		added++
+	}
+
+	checks := &config.Collectors.HTTPChecks
+	existingNames := make(map[string]bool, len(checks.Checks))
+	existingURLs := make(map[string]bool, len(checks.Checks))
+	for _, check := range checks.Checks {
+		existingNames[check.Name] = true
+		existingURLs[strings.TrimSuffix(check.URL, "/")] = true
+	}
+	for _, check := range result.HTTPChecks {
+		if existingNames[check.Name] || existingURLs[strings.TrimSuffix(check.URL, "/")] || excluded[check.Name] {
+			continue
+		}
+		checks.Checks = append(checks.Checks, check)
+		existingNames[check.Name] = true
+		existingURLs[strings.TrimSuffix(check.URL, "/")] = true
+		added++
+	}
+
+	return added
+}
+
+// MatchServices returns the unit names belonging to crucible-managed services
+func MatchServices(units []string) []string {
+	var services []string
+	for _, unit := range units {
+		for _, pattern := range servicePatterns {
+			if pattern.MatchString(unit) {
+				services = append(services, unit)
+				break
+			}
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// listServiceUnits returns the names of all installed systemd service units
+func listServiceUnits() ([]string, error) {
+	output, err := exec.Command("systemctl", "list-unit-files", "--type=service", "--no-legend", "--no-pager").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service units: %w", err)
+	}
+
+	var units []string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// Template units such as pm2-@.service cannot be monitored directly
+		if strings.HasSuffix(fields[0], "@.service") {
+			continue
+		}
+		units = append(units, strings.TrimSuffix(fields[0], ".service"))
+	}
+
+	return units, nil
+}
+
+// Domain is a site address served by Caddy
+type Domain struct {
+	Name string
+	URL  string
+}
+
+// DiscoverCaddyDomains returns the site addresses from every *.caddy file in dir
+func DiscoverCaddyDomains(dir string) ([]Domain, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.caddy"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Caddy sites: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var domains []Domain
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			// Site files are root-owned on some hosts, skip unreadable ones
+			continue
+		}
+		for _, domain := range ParseCaddyAddresses(string(data)) {
+			if seen[domain.Name] {
+				continue
+			}
+			seen[domain.Name] = true
+			domains = append(domains, domain)
+		}
+	}
+
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].Name < domains[j].Name
+	})
+
+	return domains, nil
+}
+
+// ParseCaddyAddresses extracts the top-level site addresses from a Caddyfile
+func ParseCaddyAddresses(content string) []Domain {
+	var domains []Domain
+	depth := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if depth == 0 && strings.HasSuffix(line, "{") {
+			addresses := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			// Skip the global options block and snippets
+			if addresses != "" && !strings.HasPrefix(addresses, "(") {
+				for _, address := range strings.FieldsFunc(addresses, func(r rune) bool {
+					return r == ',' || r == ' ' || r == '\t'
+				}) {
+					if domain, ok := parseCaddyAddress(address); ok {
+						domains = append(domains, domain)
+					}
+				}
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			depth = 0
+		}
+	}
+
+	return domains
+}
+
+// parseCaddyAddress converts a single site address into a checkable domain
+func parseCaddyAddress(address string) (Domain, bool) {
+	scheme := "https"
+	if strings.HasPrefix(address, "http://") {
+		scheme = "http"
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(address, "http://"), "https://")
+	host = strings.SplitN(host, "/", 2)[0]
+
+	// Port-only, wildcard and local addresses have no public hostname to check
+	if host == "" || strings.HasPrefix(host, ":") || strings.Contains(host, "*") ||
+		strings.Contains(host, "{") || strings.HasPrefix(host, "localhost") {
+		return Domain{}, false
+	}
+
+	return Domain{
+		Name: strings.SplitN(host, ":", 2)[0],
+		URL:  scheme + "://" + host,
+	}, true
+}
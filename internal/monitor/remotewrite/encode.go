@@ -0,0 +1,293 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// encoder serializes a batch of samples for one remote protocol
+type encoder interface {
+	Encode(samples []Sample) ([]byte, error)
+	Headers() map[string]string
+}
+
+// newEncoder returns the encoder for a remote write type
+func newEncoder(remoteType string) (encoder, error) {
+	switch remoteType {
+	case "prometheus":
+		return prometheusEncoder{}, nil
+	case "influxdb":
+		return influxEncoder{}, nil
+	case "otlp":
+		return otlpEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported remote write type: %s", remoteType)
+	}
+}
+
+// sortedKeys returns the keys of a label set in order
+func sortedKeys(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PROMETHEUS REMOTE WRITE
+
+// prometheusEncoder encodes a snappy-compressed protobuf WriteRequest as
+// defined by the Prometheus remote write 1.0 specification
+type prometheusEncoder struct{}
+
+// Headers returns the headers required by the remote write specification
+func (prometheusEncoder) Headers() map[string]string {
+	return map[string]string{
+		"Content-Type":                      "application/x-protobuf",
+		"Content-Encoding":                  "snappy",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	}
+}
+
+// Encode groups samples into time series and encodes the write request
+func (prometheusEncoder) Encode(samples []Sample) ([]byte, error) {
+	type series struct {
+		labels  []byte
+		samples [][]byte
+	}
+
+	var order []string
+	seriesByKey := make(map[string]*series)
+	for _, sample := range samples {
+		labels := map[string]string{"__name__": PrometheusName(sample.Name)}
+		for key, value := range sample.Labels {
+			labels[PrometheusName(key)] = value
+		}
+
+		var encodedLabels []byte
+		var key strings.Builder
+		for _, name := range sortedKeys(labels) {
+			var label []byte
+			label = appendProtoString(label, 1, name)
+			label = appendProtoString(label, 2, labels[name])
+			encodedLabels = appendProtoBytes(encodedLabels, 1, label)
+			key.WriteString(name + "\xff" + labels[name] + "\xff")
+		}
+
+		var encodedSample []byte
+		encodedSample = appendProtoDouble(encodedSample, 1, sample.Value)
+		encodedSample = appendProtoVarint(encodedSample, 2, uint64(sample.Timestamp.UnixMilli()))
+
+		s, exists := seriesByKey[key.String()]
+		if !exists {
+			s = &series{labels: encodedLabels}
+			seriesByKey[key.String()] = s
+			order = append(order, key.String())
+		}
+		s.samples = append(s.samples, encodedSample)
+	}
+
+	var request []byte
+	for _, key := range order {
+		s := seriesByKey[key]
+		timeSeries := append([]byte(nil), s.labels...)
+		for _, sample := range s.samples {
+			timeSeries = appendProtoBytes(timeSeries, 2, sample)
+		}
+		request = appendProtoBytes(request, 1, timeSeries)
+	}
+
+	return snappyEncode(request), nil
+}
+
+// PrometheusName converts a metric or label name to the Prometheus character set
+func PrometheusName(name string) string {
+	name = invalidMetricChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// appendProtoVarint appends a varint field
+func appendProtoVarint(buf []byte, field int, value uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3))
+	return binary.AppendUvarint(buf, value)
+}
+
+// appendProtoDouble appends a 64-bit floating point field
+func appendProtoDouble(buf []byte, field int, value float64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|1))
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(value))
+}
+
+// appendProtoBytes appends a length-delimited field
+func appendProtoBytes(buf []byte, field int, value []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|2))
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+// appendProtoString appends a string field
+func appendProtoString(buf []byte, field int, value string) []byte {
+	return appendProtoBytes(buf, field, []byte(value))
+}
+
+// snappyEncode encodes data in the snappy block format using literal chunks
+// only. Any snappy decoder accepts it, and it avoids a compression dependency
+// for payloads that are small and sent at most every few seconds.
+func snappyEncode(data []byte) []byte {
+	buf := binary.AppendUvarint(nil, uint64(len(data)))
+
+	for len(data) > 0 {
+		chunk := data
+		if len(chunk) > 65536 {
+			chunk = chunk[:65536]
+		}
+		data = data[len(chunk):]
+
+		n := len(chunk) - 1
+		switch {
+		case n < 60:
+			buf = append(buf, byte(n<<2))
+		case n < 1<<8:
+			buf = append(buf, 60<<2, byte(n))
+		default:
+			buf = append(buf, 61<<2, byte(n), byte(n>>8))
+		}
+		buf = append(buf, chunk...)
+	}
+
+	return buf
+}
+
+// INFLUXDB LINE PROTOCOL
+
+// influxEncoder encodes samples in the InfluxDB line protocol with
+// nanosecond timestamps
+type influxEncoder struct{}
+
+// Headers returns the content type of the line protocol
+func (influxEncoder) Headers() map[string]string {
+	return map[string]string{"Content-Type": "text/plain; charset=utf-8"}
+}
+
+// Encode writes one line per sample with the value in a field named value
+func (influxEncoder) Encode(samples []Sample) ([]byte, error) {
+	var buf strings.Builder
+	for _, sample := range samples {
+		buf.WriteString(influxEscape(sample.Name, ", "))
+		for _, key := range sortedKeys(sample.Labels) {
+			if sample.Labels[key] == "" {
+				continue
+			}
+			buf.WriteString("," + influxEscape(key, ",= ") + "=" + influxEscape(sample.Labels[key], ",= "))
+		}
+		buf.WriteString(" value=" + strconv.FormatFloat(sample.Value, 'g', -1, 64))
+		buf.WriteString(" " + strconv.FormatInt(sample.Timestamp.UnixNano(), 10) + "\n")
+	}
+	return []byte(buf.String()), nil
+}
+
+// influxEscape escapes the given special characters with a backslash
+func influxEscape(value, special string) string {
+	var buf strings.Builder
+	for _, r := range value {
+		if r == '\\' || strings.ContainsRune(special, r) {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// OTLP
+
+// otlpEncoder encodes samples as OTLP/HTTP JSON gauges
+type otlpEncoder struct{}
+
+// Headers returns the content type of OTLP/HTTP JSON
+func (otlpEncoder) Headers() map[string]string {
+	return map[string]string{"Content-Type": "application/json"}
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+	TimeUnixNano string          `json:"timeUnixNano"`
+	AsDouble     float64         `json:"asDouble"`
+}
+
+type otlpMetric struct {
+	Name  string `json:"name"`
+	Gauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+}
+
+// Encode groups samples into one gauge per metric name
+func (otlpEncoder) Encode(samples []Sample) ([]byte, error) {
+	var metrics []*otlpMetric
+	byName := make(map[string]*otlpMetric)
+	for _, sample := range samples {
+		metric, exists := byName[sample.Name]
+		if !exists {
+			metric = &otlpMetric{Name: sample.Name}
+			byName[sample.Name] = metric
+			metrics = append(metrics, metric)
+		}
+		metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpDataPoint{
+			Attributes:   otlpAttributes(sample.Labels),
+			TimeUnixNano: strconv.FormatInt(sample.Timestamp.UnixNano(), 10),
+			AsDouble:     sample.Value,
+		})
+	}
+
+	request := map[string]interface{}{
+		"resourceMetrics": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]string{"service.name": "crucible-monitor"}),
+				},
+				"scopeMetrics": []interface{}{
+					map[string]interface{}{
+						"scope":   map[string]string{"name": "crucible-monitor"},
+						"metrics": metrics,
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OTLP request: %w", err)
+	}
+	return data, nil
+}
+
+// otlpAttributes converts a label set to OTLP string attributes
+func otlpAttributes(labels map[string]string) []otlpAttribute {
+	attributes := make([]otlpAttribute, 0, len(labels))
+	for _, key := range sortedKeys(labels) {
+		attribute := otlpAttribute{Key: key}
+		attribute.Value.StringValue = labels[key]
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
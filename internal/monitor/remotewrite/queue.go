@@ -0,0 +1,140 @@
+package remotewrite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Queue is a disk-backed FIFO of encoded batches, so batches survive both
+// remote outages and agent restarts
+type Queue struct {
+	mu         sync.Mutex
+	dir        string
+	maxBatches int
+	lastSeq    int64
+}
+
+// QueuedBatch is an encoded batch read from the queue
+type QueuedBatch struct {
+	path    string
+	Data    []byte
+	Samples int
+}
+
+// NewQueue opens the queue in dir, creating the directory if needed
+func NewQueue(dir string, maxBatches int) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory %s: %w", dir, err)
+	}
+
+	// Remove batches left half-written by a crash
+	temps, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	for _, temp := range temps {
+		os.Remove(temp)
+	}
+
+	return &Queue{dir: dir, maxBatches: maxBatches}, nil
+}
+
+// Push appends a batch holding the given number of samples, dropping the
+// oldest batches beyond the size limit. It returns the number of dropped batches.
+func (q *Queue) Push(batch []byte, samples int) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Names sort in push order, also across restarts
+	seq := time.Now().UnixNano()
+	if seq <= q.lastSeq {
+		seq = q.lastSeq + 1
+	}
+	q.lastSeq = seq
+
+	path := filepath.Join(q.dir, fmt.Sprintf("%020d-%d.batch", seq, samples))
+	if err := os.WriteFile(path+".tmp", batch, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write queued batch: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return 0, fmt.Errorf("failed to write queued batch: %w", err)
+	}
+
+	files, err := q.files()
+	if err != nil {
+		return 0, err
+	}
+
+	dropped := 0
+	for len(files)-dropped > q.maxBatches {
+		os.Remove(files[dropped])
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// Peek returns the oldest batch, or nil if the queue is empty
+func (q *Queue) Peek() (*QueuedBatch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, err := q.files()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queued batch: %w", err)
+	}
+
+	batch := &QueuedBatch{path: files[0], Data: data}
+	name := strings.TrimSuffix(filepath.Base(files[0]), ".batch")
+	if _, count, found := strings.Cut(name, "-"); found {
+		batch.Samples, _ = strconv.Atoi(count)
+	}
+	return batch, nil
+}
+
+// Remove deletes a batch returned by Peek
+func (q *Queue) Remove(batch *QueuedBatch) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.Remove(batch.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove queued batch: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of queued batches
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	files, _ := q.files()
+	return len(files)
+}
+
+// files returns the queued batch files, oldest first
+func (q *Queue) files() ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queue directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".batch") {
+			files = append(files, filepath.Join(q.dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
@@ -0,0 +1,389 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"crucible/internal/logging"
+	"crucible/internal/monitor"
+)
+
+const (
+	// Maximum delay between retries while a remote is failing
+	maxBackoff = 5 * time.Minute
+	// Samples buffered in memory per writer before new ones are dropped
+	maxPendingSamples = 100000
+)
+
+// Sample is a single metric value forwarded to a remote time-series database
+type Sample struct {
+	Name      string
+	Labels    map[string]string
+	Value     float64
+	Timestamp time.Time
+}
+
+// Status describes the state of a remote write endpoint
+type Status struct {
+	Name           string     `json:"name"`
+	Type           string     `json:"type"`
+	URL            string     `json:"url"`
+	PendingSamples int        `json:"pending_samples"`
+	QueuedBatches  int        `json:"queued_batches"`
+	SentSamples    int64      `json:"sent_samples"`
+	DroppedBatches int64      `json:"dropped_batches"`
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastErrorTime  *time.Time `json:"last_error_time,omitempty"`
+}
+
+// Pipeline forwards samples to every configured remote write endpoint
+type Pipeline struct {
+	writers []*Writer
+}
+
+// NewPipeline creates a writer for each remote write configuration
+func NewPipeline(configs []monitor.RemoteWriteConfig, logger *logging.Logger) (*Pipeline, error) {
+	pipeline := &Pipeline{}
+	for _, config := range configs {
+		writer, err := NewWriter(config, logger)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.writers = append(pipeline.writers, writer)
+	}
+	return pipeline, nil
+}
+
+// Start starts sending batches in the background
+func (p *Pipeline) Start() {
+	for _, writer := range p.writers {
+		writer.Start()
+	}
+}
+
+// Stop stops all writers, queueing unsent samples on disk
+func (p *Pipeline) Stop() {
+	for _, writer := range p.writers {
+		writer.Stop()
+	}
+}
+
+// Add hands a sample to every writer
+func (p *Pipeline) Add(sample Sample) {
+	for _, writer := range p.writers {
+		writer.Add(sample)
+	}
+}
+
+// Status returns the state of every writer
+func (p *Pipeline) Status() []Status {
+	statuses := make([]Status, 0, len(p.writers))
+	for _, writer := range p.writers {
+		statuses = append(statuses, writer.Status())
+	}
+	return statuses
+}
+
+// Writer batches samples for one remote endpoint. Batches that cannot be
+// delivered are kept in a disk queue and retried with backoff, oldest first.
+type Writer struct {
+	config  monitor.RemoteWriteConfig
+	labels  map[string]string
+	encoder encoder
+	queue   *Queue
+	client  *http.Client
+	logger  *logging.Logger
+
+	mu      sync.Mutex
+	pending []Sample
+	status  Status
+
+	flush  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWriter creates a writer for a remote write endpoint
+func NewWriter(config monitor.RemoteWriteConfig, logger *logging.Logger) (*Writer, error) {
+	encoder, err := newEncoder(config.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := NewQueue(config.QueueDir, config.MaxQueueBatches)
+	if err != nil {
+		return nil, fmt.Errorf("remote write %s: %w", config.Name, err)
+	}
+
+	labels := make(map[string]string, len(config.Labels)+1)
+	if hostname, err := os.Hostname(); err == nil {
+		labels["host"] = hostname
+	}
+	for key, value := range config.Labels {
+		labels[key] = value
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Writer{
+		config:  config,
+		labels:  labels,
+		encoder: encoder,
+		queue:   queue,
+		client:  &http.Client{Timeout: config.GetTimeout()},
+		logger:  logger,
+		status:  Status{Name: config.Name, Type: config.Type, URL: config.URL},
+		flush:   make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Add buffers a sample, triggering a flush once a batch is full
+func (w *Writer) Add(sample Sample) {
+	labels := make(map[string]string, len(w.labels)+len(sample.Labels))
+	for key, value := range w.labels {
+		labels[key] = value
+	}
+	for key, value := range sample.Labels {
+		labels[key] = value
+	}
+	sample.Labels = labels
+
+	w.mu.Lock()
+	if len(w.pending) >= maxPendingSamples {
+		w.mu.Unlock()
+		return
+	}
+	w.pending = append(w.pending, sample)
+	full := len(w.pending) >= w.config.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start starts the send loop
+func (w *Writer) Start() {
+	w.logger.Info("Starting remote write", "name", w.config.Name, "type", w.config.Type,
+		"url", w.config.URL, "queued_batches", w.queue.Len())
+	go w.run()
+}
+
+// Stop stops the send loop and queues unsent samples on disk
+func (w *Writer) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// Status returns the state of the writer
+func (w *Writer) Status() Status {
+	w.mu.Lock()
+	status := w.status
+	status.PendingSamples = len(w.pending)
+	w.mu.Unlock()
+
+	status.QueuedBatches = w.queue.Len()
+	return status
+}
+
+// run flushes batches on the flush interval or when a batch is full
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.GetFlushInterval())
+	defer ticker.Stop()
+
+	backoff := time.Duration(0)
+	var nextAttempt time.Time
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			w.queuePending()
+			return
+		case <-ticker.C:
+		case <-w.flush:
+		}
+
+		w.queuePending()
+
+		if time.Now().Before(nextAttempt) {
+			continue
+		}
+		if err := w.drain(); err != nil {
+			backoff = nextBackoff(backoff, w.config.GetFlushInterval())
+			nextAttempt = time.Now().Add(backoff)
+			w.logger.Warn("Remote write failed, retrying later", "name", w.config.Name,
+				"error", err, "retry_in", backoff, "queued_batches", w.queue.Len())
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// queuePending encodes buffered samples into batches and appends them to the disk queue
+func (w *Writer) queuePending() {
+	w.mu.Lock()
+	samples := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	for len(samples) > 0 {
+		batch := samples
+		if len(batch) > w.config.BatchSize {
+			batch = batch[:w.config.BatchSize]
+		}
+		samples = samples[len(batch):]
+
+		data, err := w.encoder.Encode(batch)
+		if err != nil {
+			w.recordError(err)
+			continue
+		}
+
+		dropped, err := w.queue.Push(data, len(batch))
+		if err != nil {
+			w.recordError(err)
+			continue
+		}
+		if dropped > 0 {
+			w.mu.Lock()
+			w.status.DroppedBatches += int64(dropped)
+			w.mu.Unlock()
+			w.logger.Warn("Remote write queue full, dropped oldest batches", "name", w.config.Name, "dropped", dropped)
+		}
+	}
+}
+
+// drain sends queued batches oldest first until the queue is empty or a send fails
+func (w *Writer) drain() error {
+	for {
+		if w.ctx.Err() != nil {
+			return nil
+		}
+
+		batch, err := w.queue.Peek()
+		if err != nil {
+			w.recordError(err)
+			return err
+		}
+		if batch == nil {
+			return nil
+		}
+
+		if err := w.send(batch.Data); err != nil {
+			if permanent, ok := err.(*permanentError); ok {
+				// The remote will never accept this batch, retrying would block the queue
+				w.logger.Error("Remote write rejected batch, dropping it", "name", w.config.Name, "error", permanent)
+				w.mu.Lock()
+				w.status.DroppedBatches++
+				w.mu.Unlock()
+				w.recordError(err)
+				if err := w.queue.Remove(batch); err != nil {
+					return err
+				}
+				continue
+			}
+			w.recordError(err)
+			return err
+		}
+
+		if err := w.queue.Remove(batch); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		w.mu.Lock()
+		w.status.LastSuccess = &now
+		w.status.SentSamples += int64(batch.Samples)
+		w.mu.Unlock()
+	}
+}
+
+// permanentError is a rejection that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// send posts one encoded batch to the remote endpoint
+func (w *Writer) send(data []byte) error {
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, w.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return &permanentError{fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	for key, value := range w.encoder.Headers() {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("User-Agent", "crucible-monitor")
+	switch {
+	case w.config.Username != "":
+		req.SetBasicAuth(w.config.Username, w.config.Password)
+	case w.config.Token != "" && w.config.Type == "influxdb":
+		req.Header.Set("Authorization", "Token "+w.config.Token)
+	case w.config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+w.config.Token)
+	}
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("remote returned %s: %s", resp.Status, bytes.TrimSpace(body))
+
+	// Client errors other than rate limiting mean the batch itself is bad
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// recordError stores the last error for the status endpoint
+func (w *Writer) recordError(err error) {
+	now := time.Now()
+	w.mu.Lock()
+	w.status.LastError = err.Error()
+	w.status.LastErrorTime = &now
+	w.mu.Unlock()
+}
+
+// nextBackoff doubles the retry delay up to maxBackoff
+func nextBackoff(current, initial time.Duration) time.Duration {
+	if current == 0 {
+		return initial
+	}
+	current *= 2
+	if current > maxBackoff {
+		return maxBackoff
+	}
+	return current
+}
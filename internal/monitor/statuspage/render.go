@@ -0,0 +1,152 @@
+package statuspage
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// statusLabels are the headings shown for the overall and component statuses
+var statusLabels = map[string]string{
+	StatusOperational: "All systems operational",
+	StatusDegraded:    "Some systems are affected",
+	StatusOutage:      "Service disruption",
+	StatusMaintenance: "Scheduled maintenance in progress",
+	StatusUnknown:     "No data",
+}
+
+// componentLabels are the statuses shown next to each component
+var componentLabels = map[string]string{
+	StatusOperational: "Operational",
+	StatusDegraded:    "Degraded",
+	StatusOutage:      "Outage",
+	StatusMaintenance: "Maintenance",
+	StatusUnknown:     "No data",
+}
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"statusLabel":    func(status string) string { return statusLabels[status] },
+	"componentLabel": func(status string) string { return componentLabels[status] },
+	"uptime":         formatUptime,
+	"dayClass":       dayClass,
+	"dayTitle":       dayTitle,
+	"time": func(t time.Time) string {
+		return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+	},
+}).Parse(pageHTML))
+
+// Render renders the page as a self-contained HTML document
+func Render(page *Page) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		return nil, fmt.Errorf("failed to render status page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatUptime formats an uptime percentage, a dash without data
+func formatUptime(uptime *float64) string {
+	if uptime == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *uptime)
+}
+
+// dayClass returns the bar colour of a day's uptime
+func dayClass(day Day) string {
+	switch {
+	case day.Uptime == nil:
+		return "none"
+	case *day.Uptime >= 99.9:
+		return "up"
+	case *day.Uptime >= 95:
+		return "partial"
+	default:
+		return "down"
+	}
+}
+
+// dayTitle returns the tooltip of a day's bar
+func dayTitle(day Day) string {
+	date := day.Date.UTC().Format("Jan 2, 2006")
+	if day.Uptime == nil {
+		return date + ": no data"
+	}
+	return fmt.Sprintf("%s: %s uptime over %d checks", date, formatUptime(day.Uptime), day.Checks)
+}
+
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f6f7f9; color: #1f2328; margin: 0; }
+main { max-width: 860px; margin: 0 auto; padding: 32px 16px; }
+h1 { font-size: 28px; margin: 0 0 24px; }
+h2 { font-size: 18px; margin: 32px 0 12px; }
+.banner { border-radius: 6px; padding: 16px 20px; color: #fff; font-weight: 600; font-size: 18px; }
+.banner.operational { background: #2da44e; }
+.banner.degraded { background: #d4a72c; }
+.banner.outage { background: #cf222e; }
+.banner.maintenance { background: #0969da; }
+.card { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; padding: 16px 20px; margin-bottom: 12px; }
+.component + .component { border-top: 1px solid #eaeef2; margin-top: 16px; padding-top: 16px; }
+.row { display: flex; justify-content: space-between; align-items: baseline; gap: 12px; }
+.name { font-weight: 600; }
+.description { color: #656d76; font-size: 14px; margin-top: 2px; }
+.status { font-size: 14px; white-space: nowrap; }
+.status.operational { color: #1a7f37; }
+.status.degraded { color: #9a6700; }
+.status.outage { color: #cf222e; }
+.status.maintenance { color: #0969da; }
+.status.unknown { color: #656d76; }
+.bars { display: flex; gap: 2px; height: 32px; margin: 10px 0 6px; }
+.bars span { flex: 1; border-radius: 2px; }
+.bars .up { background: #2da44e; }
+.bars .partial { background: #d4a72c; }
+.bars .down { background: #cf222e; }
+.bars .none { background: #d0d7de; }
+.legend { display: flex; justify-content: space-between; color: #656d76; font-size: 12px; }
+.meta { color: #656d76; font-size: 14px; margin-top: 4px; }
+footer { color: #656d76; font-size: 12px; margin-top: 32px; text-align: center; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+<div class="banner {{.Status}}">{{statusLabel .Status}}</div>
+{{if .Incidents}}
+<h2>Current incidents</h2>
+{{range .Incidents}}<div class="card">
+<div class="row"><span class="name">{{.Title}}</span><span class="status outage">{{.Severity}}</span></div>
+<div class="meta">Since {{time .Since}}{{if .Components}} &middot; Affects {{range $i, $c := .Components}}{{if $i}}, {{end}}{{$c}}{{end}}{{end}}</div>
+</div>
+{{end}}{{end}}
+{{if .Maintenance}}
+<h2>Scheduled maintenance</h2>
+{{range .Maintenance}}<div class="card">
+<div class="row"><span class="name">{{.Title}}</span>{{if .Active}}<span class="status maintenance">In progress</span>{{end}}</div>
+{{if .Description}}<div class="description">{{.Description}}</div>{{end}}
+<div class="meta">{{time .Start}} &ndash; {{time .End}}{{if .Components}} &middot; Affects {{range $i, $c := .Components}}{{if $i}}, {{end}}{{$c}}{{end}}{{end}}</div>
+</div>
+{{end}}{{end}}
+{{range .Groups}}
+<h2>{{.Name}}</h2>
+<div class="card">
+{{range .Components}}<div class="component">
+<div class="row"><span class="name">{{.Name}}</span><span class="status {{.Status}}">{{componentLabel .Status}}</span></div>
+{{if .Description}}<div class="description">{{.Description}}</div>{{end}}
+<div class="bars">{{range .History}}<span class="{{dayClass .}}" title="{{dayTitle .}}"></span>{{end}}</div>
+<div class="legend"><span>{{len .History}} days ago</span><span>{{uptime .Uptime}} uptime</span><span>Today</span></div>
+</div>
+{{end}}</div>
+{{end}}
+<footer>Updated {{time .GeneratedAt}}</footer>
+</main>
+</body>
+</html>
+`
@@ -0,0 +1,124 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Component and page statuses
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusOutage      = "outage"
+	StatusMaintenance = "maintenance"
+	StatusUnknown     = "unknown"
+)
+
+// Page is everything shown on the status page
+type Page struct {
+	Title       string        `json:"title"`
+	Status      string        `json:"status"`
+	Days        int           `json:"days"`
+	Groups      []Group       `json:"groups"`
+	Incidents   []Incident    `json:"incidents"`
+	Maintenance []Maintenance `json:"maintenance"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// Group is a titled group of components
+type Group struct {
+	Name       string      `json:"name"`
+	Components []Component `json:"components"`
+}
+
+// Component is an HTTP check with its current status and daily uptime, oldest day first
+type Component struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`
+	Uptime      *float64 `json:"uptime,omitempty"` // Percent over the days with data
+	History     []Day    `json:"history"`
+}
+
+// Day is the uptime of a component over one UTC day
+type Day struct {
+	Date   time.Time `json:"date"`
+	Uptime *float64  `json:"uptime,omitempty"` // Percent of successful checks, nil without data
+	Checks int       `json:"checks"`
+}
+
+// Incident is an open incident affecting components on the page
+type Incident struct {
+	Title      string    `json:"title"`
+	Severity   string    `json:"severity"`
+	Components []string  `json:"components"`
+	Since      time.Time `json:"since"`
+}
+
+// Maintenance is an active or upcoming maintenance window
+type Maintenance struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Components  []string  `json:"components"` // Empty for all components
+	Active      bool      `json:"active"`
+}
+
+// UpdateStatus sets the overall status from the components and incidents:
+// an outage if any component is down, degraded with open incidents, then
+// maintenance while a window is active
+func (p *Page) UpdateStatus() {
+	p.Status = StatusOperational
+	for _, group := range p.Groups {
+		for _, component := range group.Components {
+			if component.Status == StatusOutage {
+				p.Status = StatusOutage
+				return
+			}
+		}
+	}
+	if len(p.Incidents) > 0 {
+		p.Status = StatusDegraded
+		return
+	}
+	for _, window := range p.Maintenance {
+		if window.Active {
+			p.Status = StatusMaintenance
+			return
+		}
+	}
+}
+
+// WriteDir renders the page to index.html and status.json in dir, replacing
+// each file atomically so a web server never serves a partial page
+func WriteDir(dir string, page *Page) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create status page directory: %w", err)
+	}
+
+	html, err := Render(page)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode status page: %w", err)
+	}
+
+	for name, content := range map[string][]byte{"index.html": html, "status.json": data} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path+".tmp", content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			os.Remove(path + ".tmp")
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
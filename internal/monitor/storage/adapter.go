@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"crucible/internal/logging"
 	"crucible/internal/monitor"
+	"crucible/internal/monitor/remotewrite"
 )
 
 // StorageAdapter bridges the monitoring system with persistent storage
@@ -16,27 +18,50 @@ type StorageAdapter struct {
 	logger       *logging.Logger
 	cleanupSched *CleanupScheduler
 	entityCache  map[string]*Entity // Cache for entity lookups by type/name
+	remoteWrite  *remotewrite.Pipeline
 }
 
 // NewStorageAdapter creates a new storage adapter
 func NewStorageAdapter(config *monitor.Config, logger *logging.Logger) (*StorageAdapter, error) {
+	storage, err := NewStorage(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	adapter := &StorageAdapter{
+		storage:     storage,
+		logger:      logger,
+		entityCache: make(map[string]*Entity),
+	}
+
+	// Start cleanup scheduler for SQLite storage
+	if sqliteStorage, ok := storage.(*SQLiteStorage); ok {
+		adapter.cleanupSched = NewCleanupScheduler(sqliteStorage, logger, config.Storage.SQLite.CleanupInterval)
+		adapter.cleanupSched.Start()
+	}
+
+	// Forward stored metrics to external time-series databases
+	if len(config.Storage.RemoteWrite) > 0 {
+		pipeline, err := remotewrite.NewPipeline(config.Storage.RemoteWrite, logger)
+		if err != nil {
+			adapter.Close()
+			return nil, fmt.Errorf("failed to create remote write pipeline: %w", err)
+		}
+		adapter.remoteWrite = pipeline
+		adapter.remoteWrite.Start()
+	}
+
+	return adapter, nil
+}
+
+// NewStorage opens the storage backend selected in the configuration
+func NewStorage(config *monitor.Config, logger *logging.Logger) (Storage, error) {
 	var storage Storage
 	var err error
 
 	switch config.Storage.Type {
 	case "sqlite":
-		storageConfig := &Config{
-			DatabasePath:    config.Storage.SQLite.Path,
-			BatchSize:       config.Storage.SQLite.BatchSize,
-			CleanupInterval: config.Storage.SQLite.CleanupInterval,
-			BackupEnabled:   config.Storage.SQLite.BackupEnabled,
-			BackupInterval:  config.Storage.SQLite.BackupInterval,
-			RetentionDays: RetentionDays{
-				EventsDays:     config.Storage.SQLite.Retention.EventsDays,
-				MetricsDays:    config.Storage.SQLite.Retention.MetricsDays,
-				AggregatesDays: config.Storage.SQLite.Retention.AggregatesDays,
-			},
-		}
+		storageConfig := NewSQLiteConfig(config)
 
 		// Ensure directory exists
 		if err := ensureDir(filepath.Dir(storageConfig.DatabasePath)); err != nil {
@@ -48,25 +73,68 @@ func NewStorageAdapter(config *monitor.Config, logger *logging.Logger) (*Storage
 			return nil, fmt.Errorf("failed to create SQLite storage: %w", err)
 		}
 	case "memory":
-		// For backwards compatibility - could implement in-memory storage
-		return nil, fmt.Errorf("memory storage not implemented with persistent layer")
+		storage = NewMemoryStorage(&MemoryConfig{
+			MaxEvents:  config.Storage.Memory.MaxEvents,
+			MaxMetrics: config.Storage.Memory.MaxMetrics,
+		}, logger)
 	default:
 		return nil, fmt.Errorf("unsupported storage type: %s", config.Storage.Type)
 	}
 
-	adapter := &StorageAdapter{
-		storage:     storage,
-		logger:      logger,
-		entityCache: make(map[string]*Entity),
+	return storage, nil
+}
+
+// NewSQLiteConfig returns the SQLite storage settings of the configuration
+func NewSQLiteConfig(config *monitor.Config) *Config {
+	return &Config{
+		DatabasePath:    config.Storage.SQLite.Path,
+		Driver:          config.Storage.SQLite.Driver,
+		BatchSize:       config.Storage.SQLite.BatchSize,
+		CleanupInterval: config.Storage.SQLite.CleanupInterval,
+		BackupEnabled:   config.Storage.SQLite.BackupEnabled,
+		BackupInterval:  config.Storage.SQLite.BackupInterval,
+		RetentionDays: RetentionDays{
+			EventsDays:     config.Storage.SQLite.Retention.EventsDays,
+			MetricsDays:    config.Storage.SQLite.Retention.MetricsDays,
+			AggregatesDays: config.Storage.SQLite.Retention.AggregatesDays,
+		},
+		RetentionPolicy: newRetentionPolicy(&config.Storage.SQLite.RetentionPolicy),
 	}
+}
 
-	// Start cleanup scheduler for SQLite storage
-	if sqliteStorage, ok := storage.(*SQLiteStorage); ok {
-		adapter.cleanupSched = NewCleanupScheduler(sqliteStorage, logger, config.Storage.SQLite.CleanupInterval)
-		adapter.cleanupSched.Start()
+// newRetentionPolicy converts the configured retention policy, nil when empty
+func newRetentionPolicy(config *monitor.RetentionPolicy) *RetentionPolicy {
+	// Durations were validated when the configuration was loaded
+	durations := func(values map[string]string) map[string]time.Duration {
+		result := make(map[string]time.Duration, len(values))
+		for name, value := range values {
+			if d, err := monitor.ParseRetentionDuration(value); err == nil {
+				result[name] = d
+			}
+		}
+		return result
+	}
+	duration := func(value string) time.Duration {
+		d, _ := monitor.ParseRetentionDuration(value)
+		return d
 	}
 
-	return adapter, nil
+	policy := &RetentionPolicy{
+		EntityTypes: durations(config.EntityTypes),
+		EventTypes:  durations(config.EventTypes),
+		MetricTypes: durations(config.MetricTypes),
+		GlobalRules: GlobalRetentionRules{
+			MaxDatabaseSize: config.Global.MaxDatabaseSize,
+			MaxEventAge:     duration(config.Global.MaxEventAge),
+			MaxMetricAge:    duration(config.Global.MaxMetricAge),
+			AggregationAge:  duration(config.Global.AggregationAge),
+		},
+	}
+	if len(policy.EntityTypes) == 0 && len(policy.EventTypes) == 0 && len(policy.MetricTypes) == 0 &&
+		policy.GlobalRules == (GlobalRetentionRules{}) {
+		return nil
+	}
+	return policy
 }
 
 // Close shuts down the storage adapter
@@ -74,6 +142,9 @@ func (sa *StorageAdapter) Close() error {
 	if sa.cleanupSched != nil {
 		sa.cleanupSched.Stop()
 	}
+	if sa.remoteWrite != nil {
+		sa.remoteWrite.Stop()
+	}
 	return sa.storage.Close()
 }
 
@@ -96,24 +167,25 @@ func (sa *StorageAdapter) StoreSystemMetrics(metrics *monitor.SystemMetrics) err
 		return fmt.Errorf("failed to update server entity: %w", err)
 	}
 
-	// Store CPU metrics
-	if err := sa.storeSystemMetric(serverEntity.ID, "cpu_usage", metrics.CPU.UsagePercent, now, map[string]interface{}{
-		"user":   metrics.CPU.UserPercent,
-		"system": metrics.CPU.SystemPercent,
-		"idle":   metrics.CPU.IdlePercent,
-		"iowait": metrics.CPU.IOWaitPercent,
-	}); err != nil {
-		return fmt.Errorf("failed to store CPU metrics: %w", err)
+	// Store CPU and memory metrics. Tags identify a series, so the values
+	// that change with every sample are metrics of their own.
+	serverMetrics := []struct {
+		name  string
+		value float64
+	}{
+		{"cpu_usage", metrics.CPU.UsagePercent},
+		{"cpu_user_percent", metrics.CPU.UserPercent},
+		{"cpu_system_percent", metrics.CPU.SystemPercent},
+		{"cpu_iowait_percent", metrics.CPU.IOWaitPercent},
+		{"memory_usage", metrics.Memory.UsagePercent},
+		{"memory_total_bytes", float64(metrics.Memory.TotalBytes)},
+		{"memory_used_bytes", float64(metrics.Memory.UsedBytes)},
+		{"memory_available_bytes", float64(metrics.Memory.AvailableBytes)},
 	}
-
-	// Store memory metrics
-	if err := sa.storeSystemMetric(serverEntity.ID, "memory_usage", metrics.Memory.UsagePercent, now, map[string]interface{}{
-		"total":     metrics.Memory.TotalBytes,
-		"available": metrics.Memory.AvailableBytes,
-		"used":      metrics.Memory.UsedBytes,
-		"free":      metrics.Memory.FreeBytes,
-	}); err != nil {
-		return fmt.Errorf("failed to store memory metrics: %w", err)
+	for _, metric := range serverMetrics {
+		if err := sa.storeSystemMetric(serverEntity.ID, metric.name, metric.value, now, nil); err != nil {
+			return fmt.Errorf("failed to store %s metric: %w", metric.name, err)
+		}
 	}
 
 	// Store load metrics
@@ -146,13 +218,23 @@ func (sa *StorageAdapter) StoreSystemMetrics(metrics *monitor.SystemMetrics) err
 			metricName = "disk_usage_root"
 		}
 
-		if err := sa.storeSystemMetric(diskEntity.ID, metricName, disk.UsagePercent, now, map[string]interface{}{
-			"total": disk.TotalBytes,
-			"used":  disk.UsedBytes,
-			"free":  disk.FreeBytes,
-		}); err != nil {
+		if err := sa.storeSystemMetric(diskEntity.ID, metricName, disk.UsagePercent, now, nil); err != nil {
 			return fmt.Errorf("failed to store disk metrics: %w", err)
 		}
+		if err := sa.storeSystemMetric(diskEntity.ID, "disk_used_bytes", float64(disk.UsedBytes), now, nil); err != nil {
+			return fmt.Errorf("failed to store disk metrics: %w", err)
+		}
+		if err := sa.storeSystemMetric(diskEntity.ID, "disk_free_bytes", float64(disk.FreeBytes), now, nil); err != nil {
+			return fmt.Errorf("failed to store disk metrics: %w", err)
+		}
+
+		// Filesystems without a fixed inode table report no inodes
+		if disk.InodesTotal > 0 {
+			inodeUsage := float64(disk.InodesUsed) / float64(disk.InodesTotal) * 100
+			if err := sa.storeSystemMetric(diskEntity.ID, "inode_usage", inodeUsage, now, nil); err != nil {
+				return fmt.Errorf("failed to store inode metrics: %w", err)
+			}
+		}
 	}
 
 	// Store network metrics
@@ -168,11 +250,98 @@ func (sa *StorageAdapter) StoreSystemMetrics(metrics *monitor.SystemMetrics) err
 			return fmt.Errorf("failed to update network entity: %w", err)
 		}
 
-		if err := sa.storeSystemMetric(netEntity.ID, "network_bytes_sent", float64(iface.BytesSent), now, nil); err != nil {
-			return fmt.Errorf("failed to store network sent metrics: %w", err)
+		netMetrics := map[string]float64{
+			"network_bytes_sent_per_sec":   iface.BytesSentPerSec,
+			"network_bytes_recv_per_sec":   iface.BytesRecvPerSec,
+			"network_packets_sent_per_sec": iface.PacketsSentPerSec,
+			"network_packets_recv_per_sec": iface.PacketsRecvPerSec,
+			"network_errors_sent_per_sec":  iface.ErrorsSentPerSec,
+			"network_errors_recv_per_sec":  iface.ErrorsRecvPerSec,
+			"network_dropped_sent_per_sec": iface.DroppedSentPerSec,
+			"network_dropped_recv_per_sec": iface.DroppedRecvPerSec,
 		}
-		if err := sa.storeSystemMetric(netEntity.ID, "network_bytes_recv", float64(iface.BytesRecv), now, nil); err != nil {
-			return fmt.Errorf("failed to store network recv metrics: %w", err)
+		for name, value := range netMetrics {
+			if err := sa.storeSystemMetric(netEntity.ID, name, value, now, nil); err != nil {
+				return fmt.Errorf("failed to store network metrics: %w", err)
+			}
+		}
+	}
+
+	// Store TCP connection state metrics, one series per state
+	tcpStates := map[string]int{
+		"established": metrics.TCP.Established,
+		"syn_sent":    metrics.TCP.SynSent,
+		"syn_recv":    metrics.TCP.SynRecv,
+		"fin_wait1":   metrics.TCP.FinWait1,
+		"fin_wait2":   metrics.TCP.FinWait2,
+		"time_wait":   metrics.TCP.TimeWait,
+		"close":       metrics.TCP.Close,
+		"close_wait":  metrics.TCP.CloseWait,
+		"last_ack":    metrics.TCP.LastAck,
+		"listen":      metrics.TCP.Listen,
+		"closing":     metrics.TCP.Closing,
+	}
+	for state, count := range tcpStates {
+		if err := sa.storeSystemMetric(serverEntity.ID, "tcp_connections", float64(count), now, map[string]interface{}{
+			"state": state,
+		}); err != nil {
+			return fmt.Errorf("failed to store TCP metrics: %w", err)
+		}
+	}
+	if err := sa.storeSystemMetric(serverEntity.ID, "tcp_listen_backlog", float64(metrics.TCP.ListenBacklog), now, nil); err != nil {
+		return fmt.Errorf("failed to store TCP metrics: %w", err)
+	}
+	if err := sa.storeSystemMetric(serverEntity.ID, "tcp_listen_backlog_max", float64(metrics.TCP.ListenBacklogMax), now, nil); err != nil {
+		return fmt.Errorf("failed to store TCP metrics: %w", err)
+	}
+
+	// Store disk I/O metrics
+	for _, io := range metrics.DiskIO {
+		deviceEntity, err := sa.getOrCreateEntity("block_device", io.Device)
+		if err != nil {
+			return fmt.Errorf("failed to get block device entity: %w", err)
+		}
+
+		deviceEntity.Status = EntityStatusActive
+		deviceEntity.Touch()
+		if err := sa.storage.UpdateEntity(deviceEntity); err != nil {
+			return fmt.Errorf("failed to update block device entity: %w", err)
+		}
+
+		ioMetrics := map[string]float64{
+			"disk_read_iops":           io.ReadsPerSec,
+			"disk_write_iops":          io.WritesPerSec,
+			"disk_read_bytes_per_sec":  io.ReadBytesPerSec,
+			"disk_write_bytes_per_sec": io.WriteBytesPerSec,
+			"disk_await_ms":            io.AwaitMs,
+			"disk_util_percent":        io.UtilPercent,
+		}
+		for name, value := range ioMetrics {
+			if err := sa.storeSystemMetric(deviceEntity.ID, name, value, now, nil); err != nil {
+				return fmt.Errorf("failed to store disk I/O metrics: %w", err)
+			}
+		}
+	}
+
+	// Store pressure stall information
+	if metrics.Pressure != nil {
+		pressure := map[string]monitor.PressureStats{
+			"cpu":    metrics.Pressure.CPU,
+			"memory": metrics.Pressure.Memory,
+			"io":     metrics.Pressure.IO,
+		}
+		for resource, stats := range pressure {
+			if err := sa.storeSystemMetric(serverEntity.ID, "pressure_"+resource+"_some", stats.SomeAvg10, now, nil); err != nil {
+				return fmt.Errorf("failed to store pressure metrics: %w", err)
+			}
+
+			// The system-wide CPU "full" line is always zero
+			if resource == "cpu" {
+				continue
+			}
+			if err := sa.storeSystemMetric(serverEntity.ID, "pressure_"+resource+"_full", stats.FullAvg10, now, nil); err != nil {
+				return fmt.Errorf("failed to store pressure metrics: %w", err)
+			}
 		}
 	}
 
@@ -286,14 +455,23 @@ func (sa *StorageAdapter) StoreHTTPCheckResults(results []monitor.HTTPCheckResul
 			return fmt.Errorf("failed to update site entity: %w", err)
 		}
 
-		// Store response time metric
+		// Store response time metric, the status code is kept with the entity and failure events
 		if err := sa.storeSystemMetric(siteEntity.ID, "response_time_ms", float64(result.ResponseTime.Milliseconds()), now, map[string]interface{}{
-			"status_code": result.StatusCode,
-			"success":     result.Success,
+			"success": result.Success,
 		}); err != nil {
 			return fmt.Errorf("failed to store response time metric: %w", err)
 		}
 
+		// Store per-step timings of synthetic transaction checks
+		for _, step := range result.Steps {
+			if err := sa.storeSystemMetric(siteEntity.ID, "step_response_time_ms", float64(step.ResponseTime.Milliseconds()), now, map[string]interface{}{
+				"step":    step.Name,
+				"success": step.Success,
+			}); err != nil {
+				return fmt.Errorf("failed to store step response time metric: %w", err)
+			}
+		}
+
 		// Create event if status changed or there's an error
 		if statusChanged || !result.Success {
 			var eventType, severity string
@@ -317,6 +495,9 @@ func (sa *StorageAdapter) StoreHTTPCheckResults(results []monitor.HTTPCheckResul
 			if result.Error != "" {
 				event.Details["error"] = result.Error
 			}
+			if result.FailedStep != "" {
+				event.Details["failed_step"] = result.FailedStep
+			}
 
 			if err := sa.storage.CreateEvent(event); err != nil {
 				return fmt.Errorf("failed to create HTTP check event: %w", err)
@@ -327,6 +508,355 @@ func (sa *StorageAdapter) StoreHTTPCheckResults(results []monitor.HTTPCheckResul
 	return nil
 }
 
+// PROBE INTEGRATION
+
+// StoreProbeResults stores TCP, DNS, ICMP and TLS probe results as entities and metrics
+func (sa *StorageAdapter) StoreProbeResults(results []monitor.ProbeResult) error {
+	now := time.Now()
+
+	for _, result := range results {
+		probeEntity, err := sa.getOrCreateEntity("probe", result.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get probe entity: %w", err)
+		}
+
+		entityStatus := EntityStatusActive
+		if !result.Success {
+			entityStatus = EntityStatusError
+		}
+		statusChanged := probeEntity.Status != entityStatus
+
+		probeEntity.Status = entityStatus
+		probeEntity.Touch()
+		probeEntity.Details["type"] = result.Type
+		probeEntity.Details["target"] = result.Target
+		probeEntity.Details["last_response_time_ms"] = result.ResponseTime.Milliseconds()
+		if result.Error != "" {
+			probeEntity.Details["last_error"] = result.Error
+		}
+		if result.CertExpiry != nil {
+			probeEntity.Details["cert_expiry"] = result.CertExpiry
+		}
+
+		if err := sa.storage.UpdateEntity(probeEntity); err != nil {
+			return fmt.Errorf("failed to update probe entity: %w", err)
+		}
+
+		tags := map[string]interface{}{
+			"type":    result.Type,
+			"target":  result.Target,
+			"success": result.Success,
+		}
+
+		if err := sa.storeSystemMetric(probeEntity.ID, "probe_response_time_ms", float64(result.ResponseTime.Microseconds())/1000, now, tags); err != nil {
+			return fmt.Errorf("failed to store probe response time metric: %w", err)
+		}
+		if result.Type == "icmp" {
+			if err := sa.storeSystemMetric(probeEntity.ID, "probe_packet_loss_percent", result.PacketLoss, now, tags); err != nil {
+				return fmt.Errorf("failed to store probe packet loss metric: %w", err)
+			}
+		}
+
+		// Only record transitions, failing probes would otherwise log an event every interval
+		if statusChanged {
+			event := NewEvent(&probeEntity.ID, EventTypeInfo, fmt.Sprintf("%s probe %s is now succeeding", strings.ToUpper(result.Type), result.Name))
+			if !result.Success {
+				event.Type = EventTypeError
+				event.Severity = SeverityError
+				event.Message = fmt.Sprintf("%s probe %s failed: %s", strings.ToUpper(result.Type), result.Name, result.Error)
+			}
+			event.Details["type"] = result.Type
+			event.Details["target"] = result.Target
+			if result.Error != "" {
+				event.Details["error"] = result.Error
+			}
+
+			if err := sa.storage.CreateEvent(event); err != nil {
+				return fmt.Errorf("failed to create probe event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PM2 INTEGRATION
+
+// StorePM2Apps stores PM2 application status as entities, metrics and events
+func (sa *StorageAdapter) StorePM2Apps(apps []monitor.PM2AppStatus) error {
+	now := time.Now()
+
+	for _, app := range apps {
+		appEntity, err := sa.getOrCreateEntity("pm2_app", app.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get PM2 app entity: %w", err)
+		}
+
+		// Determine entity status
+		var entityStatus string
+		switch app.Status {
+		case "online":
+			entityStatus = EntityStatusActive
+		case "errored":
+			entityStatus = EntityStatusError
+		default:
+			entityStatus = EntityStatusInactive
+		}
+
+		previousStatus := appEntity.Status
+		statusChanged := previousStatus != entityStatus
+
+		appEntity.Status = entityStatus
+		appEntity.Touch()
+		appEntity.Details["pm2_status"] = app.Status
+		appEntity.Details["exec_mode"] = app.ExecMode
+		appEntity.Details["instances"] = app.Instances
+		appEntity.Details["online_instances"] = app.OnlineInstances
+		appEntity.Details["restarts"] = app.Restarts
+		appEntity.Details["unstable_restarts"] = app.UnstableRestarts
+
+		if err := sa.storage.UpdateEntity(appEntity); err != nil {
+			return fmt.Errorf("failed to update PM2 app entity: %w", err)
+		}
+
+		if err := sa.storeSystemMetric(appEntity.ID, "pm2_cpu_percent", app.CPUPercent, now, nil); err != nil {
+			return fmt.Errorf("failed to store PM2 CPU metric: %w", err)
+		}
+		if err := sa.storeSystemMetric(appEntity.ID, "pm2_memory_bytes", float64(app.MemoryBytes), now, nil); err != nil {
+			return fmt.Errorf("failed to store PM2 memory metric: %w", err)
+		}
+		if err := sa.storeSystemMetric(appEntity.ID, "pm2_restarts", float64(app.Restarts), now, nil); err != nil {
+			return fmt.Errorf("failed to store PM2 restarts metric: %w", err)
+		}
+		if err := sa.storeSystemMetric(appEntity.ID, "pm2_recent_restarts", float64(app.RecentRestarts), now, nil); err != nil {
+			return fmt.Errorf("failed to store PM2 restarts metric: %w", err)
+		}
+		if err := sa.storeSystemMetric(appEntity.ID, "pm2_uptime_seconds", app.Uptime.Seconds(), now, nil); err != nil {
+			return fmt.Errorf("failed to store PM2 uptime metric: %w", err)
+		}
+
+		if statusChanged {
+			event := NewEvent(&appEntity.ID, EventTypeInfo, fmt.Sprintf("PM2 app %s status changed to %s", app.Name, app.Status))
+			if entityStatus == EntityStatusError {
+				event.Type = EventTypeError
+				event.Severity = SeverityError
+			}
+
+			event.Details["source"] = "pm2"
+			event.Details["previous_status"] = previousStatus
+			event.Details["new_status"] = entityStatus
+			event.Details["pm2_status"] = app.Status
+			event.Details["restarts"] = app.Restarts
+
+			if err := sa.storage.CreateEvent(event); err != nil {
+				return fmt.Errorf("failed to create PM2 status event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SUPERVISOR INTEGRATION
+
+// StoreSupervisorProcesses stores supervisord process state as entities, metrics and events.
+// Queue worker failures are recorded against the owning Laravel site entity.
+func (sa *StorageAdapter) StoreSupervisorProcesses(processes []monitor.SupervisorProcessStatus) error {
+	now := time.Now()
+
+	for _, proc := range processes {
+		procEntity, err := sa.getOrCreateEntity("supervisor_program", proc.FullName)
+		if err != nil {
+			return fmt.Errorf("failed to get supervisor program entity: %w", err)
+		}
+
+		// Determine entity status
+		var entityStatus string
+		switch proc.State {
+		case "RUNNING", "STARTING":
+			entityStatus = EntityStatusActive
+		case "FATAL", "BACKOFF", "UNKNOWN":
+			entityStatus = EntityStatusError
+		default:
+			entityStatus = EntityStatusInactive
+		}
+
+		previousState, _ := procEntity.Details["state"].(string)
+		stateChanged := previousState != proc.State
+
+		procEntity.Status = entityStatus
+		procEntity.Touch()
+		procEntity.Details["group"] = proc.Group
+		procEntity.Details["state"] = proc.State
+		procEntity.Details["pid"] = proc.PID
+		procEntity.Details["exit_status"] = proc.ExitStatus
+		procEntity.Details["restarts"] = proc.Restarts
+
+		// Link queue workers to their site entity
+		var siteEntityID *int64
+		if proc.Site != "" {
+			siteEntity, err := sa.getOrCreateEntity(EntityTypeSite, proc.Site)
+			if err != nil {
+				return fmt.Errorf("failed to get site entity: %w", err)
+			}
+			siteEntityID = &siteEntity.ID
+			procEntity.Details["site"] = proc.Site
+			procEntity.Details["site_entity_id"] = siteEntity.ID
+		}
+
+		if err := sa.storage.UpdateEntity(procEntity); err != nil {
+			return fmt.Errorf("failed to update supervisor program entity: %w", err)
+		}
+
+		tags := map[string]interface{}{
+			"group": proc.Group,
+		}
+		if proc.Site != "" {
+			tags["site"] = proc.Site
+		}
+
+		if err := sa.storeSystemMetric(procEntity.ID, "supervisor_uptime_seconds", proc.Uptime.Seconds(), now, tags); err != nil {
+			return fmt.Errorf("failed to store supervisor uptime metric: %w", err)
+		}
+		if err := sa.storeSystemMetric(procEntity.ID, "supervisor_restarts", float64(proc.Restarts), now, tags); err != nil {
+			return fmt.Errorf("failed to store supervisor restarts metric: %w", err)
+		}
+
+		if stateChanged {
+			// Failures are attached to the owning site so they show up in its history
+			entityID := &procEntity.ID
+			if siteEntityID != nil {
+				entityID = siteEntityID
+			}
+
+			event := NewEvent(entityID, EventTypeInfo, fmt.Sprintf("Supervisor program %s state changed to %s", proc.FullName, proc.State))
+			switch proc.State {
+			case "FATAL":
+				event.Type = EventTypeError
+				event.Severity = SeverityCritical
+			case "BACKOFF", "EXITED":
+				event.Type = EventTypeWarning
+				event.Severity = SeverityWarning
+			}
+
+			event.Details["source"] = "supervisor"
+			event.Details["program"] = proc.FullName
+			event.Details["program_entity_id"] = procEntity.ID
+			event.Details["previous_state"] = previousState
+			event.Details["new_state"] = proc.State
+			event.Details["exit_status"] = proc.ExitStatus
+			if proc.SpawnError != "" {
+				event.Details["spawn_error"] = proc.SpawnError
+			}
+			if proc.Site != "" {
+				event.Details["site"] = proc.Site
+			}
+
+			if err := sa.storage.CreateEvent(event); err != nil {
+				return fmt.Errorf("failed to create supervisor state event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// JOURNAL INTEGRATION
+
+// StoreLogEvent stores a matched journal entry as an event on its service entity.
+// Kernel messages are attached to the server entity.
+func (sa *StorageAdapter) StoreLogEvent(logEvent monitor.LogEvent) error {
+	var entity *Entity
+	var err error
+	if logEvent.Unit == "kernel" {
+		entity, err = sa.getOrCreateEntity(EntityTypeServer, "localhost")
+	} else {
+		entity, err = sa.getOrCreateEntity(EntityTypeService, logEvent.Unit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get log event entity: %w", err)
+	}
+
+	// Map pattern severity to event type
+	eventType := EventTypeInfo
+	severity := SeverityInfo
+	switch logEvent.Severity {
+	case "critical":
+		eventType = EventTypeError
+		severity = SeverityCritical
+	case "error":
+		eventType = EventTypeError
+		severity = SeverityError
+	case "warning":
+		eventType = EventTypeWarning
+		severity = SeverityWarning
+	}
+
+	event := NewEvent(&entity.ID, eventType, logEvent.Message)
+	event.Timestamp = logEvent.Timestamp
+	event.Severity = severity
+	event.Details["source"] = "journal"
+	event.Details["service"] = logEvent.Unit
+	event.Details["pattern"] = logEvent.Pattern
+	if logEvent.Identifier != "" {
+		event.Details["identifier"] = logEvent.Identifier
+	}
+	if logEvent.PID > 0 {
+		event.Details["pid"] = logEvent.PID
+	}
+
+	if err := sa.storage.CreateEvent(event); err != nil {
+		return fmt.Errorf("failed to create log event: %w", err)
+	}
+
+	return nil
+}
+
+// StoreEvent stores an event raised by the agent itself
+func (sa *StorageAdapter) StoreEvent(event *Event) error {
+	if err := sa.storage.CreateEvent(event); err != nil {
+		return fmt.Errorf("failed to create %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// CUSTOM COLLECTOR INTEGRATION
+
+// StoreCollectorMetrics stores metrics gathered by a generic collector. Labels
+// are stored as tags and the metrics are attached to a per-collector entity.
+func (sa *StorageAdapter) StoreCollectorMetrics(collector string, metrics []monitor.Metric) error {
+	collectorEntity, err := sa.getOrCreateEntity("collector", collector)
+	if err != nil {
+		return fmt.Errorf("failed to get collector entity: %w", err)
+	}
+
+	collectorEntity.Status = EntityStatusActive
+	collectorEntity.Touch()
+	if err := sa.storage.UpdateEntity(collectorEntity); err != nil {
+		return fmt.Errorf("failed to update collector entity: %w", err)
+	}
+
+	batch := make([]BatchItem, 0, len(metrics))
+	for _, m := range metrics {
+		metric := NewMetric(&collectorEntity.ID, m.Name, m.Value)
+		metric.Timestamp = m.Timestamp
+		metric.SetTTL(30)
+		for key, value := range m.Labels {
+			metric.Tags[key] = value
+		}
+		if m.Unit != "" {
+			metric.Tags["unit"] = m.Unit
+		}
+		batch = append(batch, BatchItem{Type: "metric", Data: metric})
+	}
+
+	if err := sa.storage.BatchWrite(batch); err != nil {
+		return fmt.Errorf("failed to store collector metrics: %w", err)
+	}
+
+	return nil
+}
+
 // HELPER METHODS
 
 // getOrCreateEntity gets an existing entity or creates a new one
@@ -354,6 +884,20 @@ func (sa *StorageAdapter) getOrCreateEntity(entityType, name string) (*Entity, e
 	return entity, nil
 }
 
+// StoreDailyMetric stores a daily aggregate of an entity metric, kept for
+// the aggregate retention period instead of the raw metric TTL
+func (sa *StorageAdapter) StoreDailyMetric(entityID int64, metricName string, value float64, day time.Time, sampleCount int) error {
+	metric := NewMetric(&entityID, metricName, value)
+	metric.Timestamp = day
+	metric.AggregationLevel = AggregationLevelDaily
+	metric.SampleCount = sampleCount
+
+	if err := sa.storage.CreateMetric(metric); err != nil {
+		return fmt.Errorf("failed to store daily %s: %w", metricName, err)
+	}
+	return nil
+}
+
 // storeSystemMetric stores a system metric with TTL
 func (sa *StorageAdapter) storeSystemMetric(entityID int64, metricName string, value float64, timestamp time.Time, tags map[string]interface{}) error {
 	metric := NewMetric(&entityID, metricName, value)
@@ -369,17 +913,72 @@ func (sa *StorageAdapter) storeSystemMetric(entityID int64, metricName string, v
 		}
 	}
 
-	return sa.storage.CreateMetric(metric)
+	if err := sa.storage.CreateMetric(metric); err != nil {
+		return err
+	}
+
+	if sa.remoteWrite != nil {
+		sa.remoteWrite.Add(sa.remoteWriteSample(metric))
+	}
+
+	return nil
+}
+
+// remoteWriteSample converts a stored metric into a remote write sample. The
+// entity and string tags become labels, numeric tags are left out as they
+// would create a new series for every value.
+func (sa *StorageAdapter) remoteWriteSample(metric *Metric) remotewrite.Sample {
+	labels := make(map[string]string)
+	for key, value := range metric.Tags {
+		if str, ok := value.(string); ok {
+			labels[key] = str
+		}
+	}
+	if metric.EntityID != nil {
+		for _, entity := range sa.entityCache {
+			if entity.ID == *metric.EntityID {
+				labels["entity_type"] = entity.Type
+				labels["entity"] = entity.Name
+				break
+			}
+		}
+	}
+
+	return remotewrite.Sample{
+		Name:      metric.MetricName,
+		Labels:    labels,
+		Value:     metric.Value,
+		Timestamp: metric.Timestamp,
+	}
+}
+
+// RemoteWriteStatus returns the state of each remote write endpoint
+func (sa *StorageAdapter) RemoteWriteStatus() []remotewrite.Status {
+	if sa.remoteWrite == nil {
+		return nil
+	}
+	return sa.remoteWrite.Status()
 }
 
 // GetStorageStats returns storage statistics
 func (sa *StorageAdapter) GetStorageStats() (interface{}, error) {
-	if sqliteStorage, ok := sa.storage.(*SQLiteStorage); ok {
-		return sqliteStorage.GetDatabaseInfo()
+	if infoStorage, ok := sa.storage.(interface {
+		GetDatabaseInfo() (*DatabaseInfo, error)
+	}); ok {
+		return infoStorage.GetDatabaseInfo()
 	}
 	return nil, fmt.Errorf("storage stats not supported for this storage type")
 }
 
+// DatabaseSize returns the size of the database file, zero for in-memory storage
+func (sa *StorageAdapter) DatabaseSize() (int64, error) {
+	sqliteStorage, ok := sa.storage.(*SQLiteStorage)
+	if !ok {
+		return 0, nil
+	}
+	return sqliteStorage.fileSize()
+}
+
 // GetSystemHealth returns system health from storage
 func (sa *StorageAdapter) GetSystemHealth() (*SystemHealth, error) {
 	return sa.storage.GetSystemHealth()
@@ -415,6 +1014,54 @@ func (sa *StorageAdapter) GetMetricSummary(filter *MetricFilter) (*MetricSummary
 	return sa.storage.GetMetricSummary(filter)
 }
 
+// QueryMetrics returns a metric downsampled to aligned steps
+func (sa *StorageAdapter) QueryMetrics(query *MetricQuery) (*MetricQueryResult, error) {
+	return sa.storage.QueryMetrics(query)
+}
+
+// SaveConfigVersion records a revision of the configuration files
+func (sa *StorageAdapter) SaveConfigVersion(description, monitorConfig, alertsConfig string) (*ConfigVersion, error) {
+	version := &ConfigVersion{
+		Description:   description,
+		MonitorConfig: monitorConfig,
+		AlertsConfig:  alertsConfig,
+	}
+	if err := sa.storage.CreateConfigVersion(version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// GetConfigVersion returns a configuration revision by ID
+func (sa *StorageAdapter) GetConfigVersion(id int64) (*ConfigVersion, error) {
+	return sa.storage.GetConfigVersion(id)
+}
+
+// ListConfigVersions returns the most recent configuration revisions
+func (sa *StorageAdapter) ListConfigVersions(limit int) ([]*ConfigVersion, error) {
+	return sa.storage.ListConfigVersions(limit)
+}
+
+// CreateIncident stores a newly opened incident
+func (sa *StorageAdapter) CreateIncident(incident *Incident) error {
+	return sa.storage.CreateIncident(incident)
+}
+
+// UpdateIncident updates the status, message and details of an incident
+func (sa *StorageAdapter) UpdateIncident(incident *Incident) error {
+	return sa.storage.UpdateIncident(incident)
+}
+
+// GetIncident returns an incident by ID
+func (sa *StorageAdapter) GetIncident(id int64) (*Incident, error) {
+	return sa.storage.GetIncident(id)
+}
+
+// ListIncidents returns incidents based on filter criteria
+func (sa *StorageAdapter) ListIncidents(filter *IncidentFilter) ([]*Incident, error) {
+	return sa.storage.ListIncidents(filter)
+}
+
 // ensureDir creates a directory if it doesn't exist
 func ensureDir(dir string) error {
 	return os.MkdirAll(dir, 0755)
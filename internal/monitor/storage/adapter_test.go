@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crucible/internal/logging"
+	"crucible/internal/monitor"
+)
+
+// benchmarkSystemMetrics returns a collection of a server with four disks, two
+// interfaces and two block devices, its values changing with every round
+func benchmarkSystemMetrics(round int) *monitor.SystemMetrics {
+	value := func(scale float64) float64 {
+		return float64((round*7)%100) * scale
+	}
+
+	metrics := &monitor.SystemMetrics{
+		CPU: monitor.CPUMetrics{
+			UsagePercent:  value(1),
+			UserPercent:   value(0.6),
+			SystemPercent: value(0.3),
+			IdlePercent:   100 - value(1),
+			IOWaitPercent: value(0.1),
+		},
+		Memory: monitor.MemoryMetrics{
+			TotalBytes:     16 << 30,
+			UsedBytes:      uint64(value(1 << 27)),
+			FreeBytes:      uint64(value(1 << 26)),
+			AvailableBytes: uint64(value(1 << 27)),
+			UsagePercent:   value(1),
+		},
+		Load:     monitor.LoadMetrics{Load1: value(0.04), Load5: value(0.03), Load15: value(0.02)},
+		Pressure: &monitor.PressureMetrics{},
+		TCP: monitor.TCPMetrics{
+			Established:   round % 200,
+			TimeWait:      round % 50,
+			Listen:        12,
+			ListenBacklog: round % 5,
+		},
+		Timestamp: time.Now(),
+	}
+	for _, mount := range []string{"/", "/var", "/home", "/srv"} {
+		metrics.Disk = append(metrics.Disk, monitor.DiskMetrics{
+			MountPoint:   mount,
+			Device:       "/dev/sda1",
+			TotalBytes:   100 << 30,
+			UsedBytes:    uint64(value(1 << 30)),
+			FreeBytes:    100<<30 - uint64(value(1<<30)),
+			UsagePercent: value(1),
+			InodesTotal:  6553600,
+			InodesUsed:   uint64(round),
+			InodesFree:   6553600 - uint64(round),
+		})
+	}
+	for _, iface := range []string{"eth0", "eth1"} {
+		metrics.Network = append(metrics.Network, monitor.NetworkMetrics{
+			Interface:       iface,
+			BytesRecvPerSec: value(1000),
+			BytesSentPerSec: value(500),
+		})
+	}
+	for _, device := range []string{"sda", "sdb"} {
+		metrics.DiskIO = append(metrics.DiskIO, monitor.DiskIOMetrics{
+			Device:       device,
+			ReadsPerSec:  value(2),
+			WritesPerSec: value(3),
+			UtilPercent:  value(1),
+		})
+	}
+	metrics.Pressure.CPU.SomeAvg10 = value(0.1)
+	metrics.Pressure.Memory.SomeAvg10 = value(0.05)
+	metrics.Pressure.IO.SomeAvg10 = value(0.2)
+	return metrics
+}
+
+// BenchmarkStoreSystemMetrics stores system metrics collections through the
+// storage adapter, reporting the series they create
+func BenchmarkStoreSystemMetrics(b *testing.B) {
+	dir := b.TempDir()
+	logger, err := logging.NewLogger(filepath.Join(dir, "bench.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	storage, err := NewSQLiteStorage(&Config{DatabasePath: filepath.Join(dir, "monitor.db"), BatchSize: 100}, logger)
+	if err != nil {
+		b.Fatal(err)
+	}
+	adapter := &StorageAdapter{storage: storage, logger: logger, entityCache: make(map[string]*Entity)}
+	defer adapter.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := adapter.StoreSystemMetrics(benchmarkSystemMetrics(i)); err != nil {
+			b.Fatal(fmt.Errorf("round %d: %w", i, err))
+		}
+	}
+	b.StopTimer()
+
+	var series int
+	if err := storage.db.QueryRow(`SELECT COUNT(*) FROM series`).Scan(&series); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(series), "series")
+}
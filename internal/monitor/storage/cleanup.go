@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"crucible/internal/logging"
@@ -102,6 +103,10 @@ func (cs *CleanupScheduler) performCleanup() {
 		"final_db_size_bytes", postStats.DatabaseSize,
 	)
 
+	if cs.storage.config.RetentionPolicy != nil {
+		cs.applyRetentionPolicy(cs.storage.config.RetentionPolicy)
+	}
+
 	// Perform vacuum if significant space was freed
 	if spaceSaved > 1024*1024 { // 1MB threshold
 		cs.logger.Debug("Running VACUUM to reclaim space")
@@ -113,6 +118,51 @@ func (cs *CleanupScheduler) performCleanup() {
 	}
 }
 
+// applyRetentionPolicy applies the configured policy and records evictions
+// as events. Evicted pages are reused by new data, so the file stops growing
+// without a VACUUM.
+func (cs *CleanupScheduler) applyRetentionPolicy(policy *RetentionPolicy) {
+	report, err := cs.storage.ApplyRetentionPolicy(policy)
+	if err != nil {
+		cs.logger.Error("Failed to apply retention policy", "error", err)
+	}
+	if report == nil {
+		return
+	}
+
+	cs.logger.Debug("Retention policy applied",
+		"entities_removed", report.EntitiesRemoved,
+		"events_removed", report.EventsRemoved,
+		"metrics_removed", report.MetricsRemoved,
+		"used_db_size_bytes", report.DatabaseSize,
+	)
+
+	for _, eviction := range report.Evictions {
+		cs.logger.Warn("Evicted data to enforce database size cap",
+			"data", eviction.Data,
+			"rows", eviction.Rows,
+			"oldest", eviction.Oldest,
+			"newest", eviction.Newest,
+			"max_db_size_bytes", report.MaxDatabaseSize,
+		)
+
+		event := NewEvent(nil, EventTypeEviction, fmt.Sprintf("Evicted %d rows of %s to keep the database under %d bytes",
+			eviction.Rows, strings.ReplaceAll(eviction.Data, "_", " "), report.MaxDatabaseSize))
+		event.Severity = SeverityWarning
+		event.Details = JSON{
+			"data":                    eviction.Data,
+			"rows":                    eviction.Rows,
+			"oldest":                  eviction.Oldest,
+			"newest":                  eviction.Newest,
+			"database_size_bytes":     report.DatabaseSize,
+			"max_database_size_bytes": report.MaxDatabaseSize,
+		}
+		if err := cs.storage.CreateEvent(event); err != nil {
+			cs.logger.Error("Failed to record eviction event", "error", err)
+		}
+	}
+}
+
 // RetentionPolicy defines data retention rules
 type RetentionPolicy struct {
 	EntityTypes map[string]time.Duration `yaml:"entity_types"`
@@ -129,71 +179,238 @@ type GlobalRetentionRules struct {
 	AggregationAge  time.Duration `yaml:"aggregation_age"`
 }
 
+// Size cap eviction settings
+const (
+	// evictionTarget is the share of the size cap kept after evicting, so
+	// that eviction does not run again on the next cleanup cycle
+	evictionTarget = 0.9
+
+	// minEvictionBatch is the smallest number of rows deleted per round
+	minEvictionBatch = 1000
+
+	// maxEvictionRounds bounds the deletes per tier and cleanup cycle
+	maxEvictionRounds = 20
+)
+
+// evictionTier is data that can be dropped to enforce the size cap, in
+// the order it is dropped. Each tier is dropped oldest first by timestamp.
+type evictionTier struct {
+	data  string
+	table string
+	where string
+}
+
+var evictionTiers = []evictionTier{
+	{"raw_metrics", "samples", "aggregation_level = 'raw'"},
+	{"aggregated_metrics", "samples", "aggregation_level != 'raw'"},
+	{"info_events", "events", "severity = 'info'"},
+	{"events", "events", "severity != 'info'"},
+}
+
+// Eviction describes data dropped to keep the database under its size cap
+type Eviction struct {
+	Data   string    `json:"data"` // raw_metrics, aggregated_metrics, info_events or events
+	Rows   int64     `json:"rows"`
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+}
+
+// RetentionReport describes what a retention policy removed
+type RetentionReport struct {
+	EntitiesRemoved int64      `json:"entities_removed"`
+	EventsRemoved   int64      `json:"events_removed"`
+	MetricsRemoved  int64      `json:"metrics_removed"`
+	DatabaseSize    int64      `json:"database_size_bytes"` // Used size after applying the policy
+	MaxDatabaseSize int64      `json:"max_database_size_bytes"`
+	Evictions       []Eviction `json:"evictions,omitempty"`
+}
+
 // ApplyRetentionPolicy applies custom retention policies beyond the basic cleanup
-func (s *SQLiteStorage) ApplyRetentionPolicy(policy *RetentionPolicy) error {
+func (s *SQLiteStorage) ApplyRetentionPolicy(policy *RetentionPolicy) (*RetentionReport, error) {
 	now := time.Now()
+	report := &RetentionReport{MaxDatabaseSize: policy.GlobalRules.MaxDatabaseSize}
 
 	// Apply entity-specific retention
 	for entityType, maxAge := range policy.EntityTypes {
 		cutoff := now.Add(-maxAge)
-		_, err := s.db.Exec(`
+		removed, err := s.deleteRows(`
 			DELETE FROM entities 
 			WHERE type = ? AND updated_at < ? AND status != 'active'
 		`, entityType, cutoff.Unix())
 		if err != nil {
-			return fmt.Errorf("failed to apply retention for entity type %s: %w", entityType, err)
+			return report, fmt.Errorf("failed to apply retention for entity type %s: %w", entityType, err)
 		}
+		report.EntitiesRemoved += removed
 	}
 
 	// Apply event-specific retention
 	for eventType, maxAge := range policy.EventTypes {
 		cutoff := now.Add(-maxAge)
-		_, err := s.db.Exec(`
+		removed, err := s.deleteRows(`
 			DELETE FROM events 
 			WHERE event_type = ? AND timestamp < ?
 		`, eventType, cutoff.Unix())
 		if err != nil {
-			return fmt.Errorf("failed to apply retention for event type %s: %w", eventType, err)
+			return report, fmt.Errorf("failed to apply retention for event type %s: %w", eventType, err)
 		}
+		report.EventsRemoved += removed
 	}
 
 	// Apply metric-specific retention
 	for metricName, maxAge := range policy.MetricTypes {
 		cutoff := now.Add(-maxAge)
-		_, err := s.db.Exec(`
-			DELETE FROM metrics 
-			WHERE metric_name = ? AND timestamp < ? AND aggregation_level = 'raw'
+		removed, err := s.deleteRows(`
+			DELETE FROM samples 
+			WHERE series_id IN (SELECT id FROM series WHERE metric_name = ?)
+			AND timestamp < ? AND aggregation_level = 'raw'
 		`, metricName, cutoff.Unix())
 		if err != nil {
-			return fmt.Errorf("failed to apply retention for metric type %s: %w", metricName, err)
+			return report, fmt.Errorf("failed to apply retention for metric type %s: %w", metricName, err)
+		}
+		report.MetricsRemoved += removed
+	}
+
+	// Apply global age limits
+	if policy.GlobalRules.MaxEventAge > 0 {
+		removed, err := s.deleteRows(`DELETE FROM events WHERE timestamp < ?`,
+			now.Add(-policy.GlobalRules.MaxEventAge).Unix())
+		if err != nil {
+			return report, fmt.Errorf("failed to apply maximum event age: %w", err)
+		}
+		report.EventsRemoved += removed
+	}
+	if policy.GlobalRules.MaxMetricAge > 0 {
+		removed, err := s.deleteRows(`DELETE FROM samples WHERE aggregation_level = 'raw' AND timestamp < ?`,
+			now.Add(-policy.GlobalRules.MaxMetricAge).Unix())
+		if err != nil {
+			return report, fmt.Errorf("failed to apply maximum metric age: %w", err)
+		}
+		report.MetricsRemoved += removed
+	}
+	if policy.GlobalRules.AggregationAge > 0 {
+		removed, err := s.deleteRows(`DELETE FROM samples WHERE aggregation_level != 'raw' AND timestamp < ?`,
+			now.Add(-policy.GlobalRules.AggregationAge).Unix())
+		if err != nil {
+			return report, fmt.Errorf("failed to apply aggregation age: %w", err)
 		}
+		report.MetricsRemoved += removed
 	}
 
 	// Apply size-based cleanup if database is too large
 	if policy.GlobalRules.MaxDatabaseSize > 0 {
-		dbInfo, err := s.GetDatabaseInfo()
-		if err != nil {
-			return fmt.Errorf("failed to get database size: %w", err)
+		if err := s.enforceSizeCap(policy.GlobalRules.MaxDatabaseSize, report); err != nil {
+			return report, fmt.Errorf("failed to apply size-based cleanup: %w", err)
 		}
+	}
+
+	return report, nil
+}
+
+// deleteRows executes a delete and returns the number of removed rows
+func (s *SQLiteStorage) deleteRows(query string, args ...interface{}) (int64, error) {
+	result, err := s.db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// usedDatabaseSize returns the bytes of the database file holding data.
+// Deleted rows free pages for reuse without shrinking the file, so free
+// pages are not counted.
+func (s *SQLiteStorage) usedDatabaseSize() (int64, error) {
+	var pageCount, freePages, pageSize int64
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return (pageCount - freePages) * pageSize, nil
+}
 
-		if dbInfo.DatabaseSize > policy.GlobalRules.MaxDatabaseSize {
-			// Remove oldest raw metrics first
-			_, err := s.db.Exec(`
-				DELETE FROM metrics 
-				WHERE aggregation_level = 'raw' 
-				AND id IN (
-					SELECT id FROM metrics 
-					WHERE aggregation_level = 'raw' 
-					ORDER BY timestamp ASC 
-					LIMIT 1000
-				)
-			`)
+// fileSize returns the bytes of the database file, including free pages
+func (s *SQLiteStorage) fileSize() (int64, error) {
+	var pageCount, pageSize int64
+	if err := s.db.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pageCount * pageSize, nil
+}
+
+// enforceSizeCap drops the oldest raw samples, then aggregated samples, then
+// events until the used size is below evictionTarget of maxSize
+func (s *SQLiteStorage) enforceSizeCap(maxSize int64, report *RetentionReport) error {
+	size, err := s.usedDatabaseSize()
+	if err != nil {
+		return fmt.Errorf("failed to get database size: %w", err)
+	}
+	report.DatabaseSize = size
+	if size <= maxSize {
+		return nil
+	}
+
+	target := int64(float64(maxSize) * evictionTarget)
+	for _, tier := range evictionTiers {
+		eviction := Eviction{Data: tier.data}
+		for round := 0; round < maxEvictionRounds && size > target; round++ {
+			// Estimate the rows to delete from the average row size
+			var rows int64
+			if err := s.db.QueryRow(`SELECT (SELECT COUNT(*) FROM samples) + (SELECT COUNT(*) FROM events)`).Scan(&rows); err != nil {
+				return fmt.Errorf("failed to count rows: %w", err)
+			}
+			if rows == 0 {
+				break
+			}
+			batch := (size - target) / max(size/rows, 1)
+			batch = max(batch, minEvictionBatch)
+
+			oldest := fmt.Sprintf(`SELECT id, timestamp FROM %s WHERE %s ORDER BY timestamp ASC LIMIT ?`, tier.table, tier.where)
+			var count int64
+			var first, last *int64
+			err := s.db.QueryRow(`SELECT COUNT(*), MIN(timestamp), MAX(timestamp) FROM (`+oldest+`)`, batch).Scan(&count, &first, &last)
 			if err != nil {
-				return fmt.Errorf("failed to apply size-based cleanup: %w", err)
+				return fmt.Errorf("failed to find oldest %s: %w", tier.data, err)
+			}
+			if count == 0 {
+				break
 			}
+
+			removed, err := s.deleteRows(fmt.Sprintf(`DELETE FROM %s WHERE id IN (SELECT id FROM (%s))`, tier.table, oldest), batch)
+			if err != nil {
+				return fmt.Errorf("failed to evict %s: %w", tier.data, err)
+			}
+			if eviction.Rows == 0 {
+				eviction.Oldest = time.Unix(*first, 0)
+			}
+			eviction.Newest = time.Unix(*last, 0)
+			eviction.Rows += removed
+
+			if size, err = s.usedDatabaseSize(); err != nil {
+				return fmt.Errorf("failed to get database size: %w", err)
+			}
+		}
+
+		if eviction.Rows > 0 {
+			report.Evictions = append(report.Evictions, eviction)
+			if tier.table == "events" {
+				report.EventsRemoved += eviction.Rows
+			} else {
+				report.MetricsRemoved += eviction.Rows
+			}
+		}
+		if size <= target {
+			break
 		}
 	}
 
+	report.DatabaseSize = size
 	return nil
 }
 
@@ -220,7 +437,7 @@ func (s *SQLiteStorage) GetRetentionStats() (*RetentionStats, error) {
 			MIN(timestamp) as oldest_metric,
 			MAX(timestamp) as newest_metric,
 			COUNT(*) as total_metrics
-		FROM metrics
+		FROM samples
 	`).Scan(&stats.OldestMetricUnix, &stats.NewestMetricUnix, &stats.TotalMetrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metric age stats: %w", err)
@@ -229,7 +446,7 @@ func (s *SQLiteStorage) GetRetentionStats() (*RetentionStats, error) {
 	// Get aggregation level distribution
 	rows, err := s.db.Query(`
 		SELECT aggregation_level, COUNT(*) 
-		FROM metrics 
+		FROM samples 
 		GROUP BY aggregation_level
 	`)
 	if err != nil {
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ErrDatabaseLocked is returned when the database is in use by another
+// process in a way that conflicts with the requested access
+var ErrDatabaseLocked = errors.New("database is locked by another process")
+
+// lockDatabase takes an advisory lock on the lock file next to a database.
+// Storages share the lock while open, schema changes need it exclusively so
+// that they cannot run under an agent that would undo them on its next start.
+func lockDatabase(path string, exclusive bool) (*os.File, error) {
+	if path == "" || path == ":memory:" {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database lock: %w", err)
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(file.Fd()), how|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrDatabaseLocked
+		}
+		return nil, fmt.Errorf("failed to lock database: %w", err)
+	}
+	return file, nil
+}
+
+// unlockDatabase releases a lock taken with lockDatabase
+func unlockDatabase(lock *os.File) {
+	if lock != nil {
+		lock.Close()
+	}
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"crucible/internal/logging"
+)
+
+// DatabaseMaintenance runs administrative operations on a SQLite database.
+// Unlike NewSQLiteStorage, opening it leaves pending migrations alone.
+type DatabaseMaintenance struct {
+	storage    *SQLiteStorage
+	migrations *MigrationManager
+}
+
+// DatabaseStatus describes the file and schema of a database
+type DatabaseStatus struct {
+	Path        string            `json:"path"`
+	Driver      string            `json:"driver"`
+	FileSize    int64             `json:"file_size_bytes"`
+	UsedSize    int64             `json:"used_size_bytes"`
+	PageSize    int64             `json:"page_size"`
+	FreePages   int64             `json:"free_pages"`
+	JournalMode string            `json:"journal_mode"`
+	SchemaError string            `json:"schema_error,omitempty"`
+	Migrations  []MigrationStatus `json:"migrations"`
+}
+
+// OpenDatabaseMaintenance opens an existing SQLite database for maintenance
+func OpenDatabaseMaintenance(config *Config, logger *logging.Logger) (*DatabaseMaintenance, error) {
+	if _, err := os.Stat(config.DatabasePath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	storage, err := openSQLiteStorage(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := NewMigrationManager(storage.db, logger)
+	migrations.SetBackupPath(config.DatabasePath)
+	return &DatabaseMaintenance{storage: storage, migrations: migrations}, nil
+}
+
+// Close closes the database
+func (dm *DatabaseMaintenance) Close() error {
+	return dm.storage.db.Close()
+}
+
+// Status returns the size, journal mode and migrations of the database
+func (dm *DatabaseMaintenance) Status() (*DatabaseStatus, error) {
+	status := &DatabaseStatus{Path: dm.storage.config.DatabasePath}
+
+	var err error
+	if status.Driver, err = sqliteDriverName(dm.storage.config.Driver); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(status.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat database: %w", err)
+	}
+	status.FileSize = info.Size()
+
+	if err := dm.storage.db.QueryRow("PRAGMA page_size").Scan(&status.PageSize); err != nil {
+		return nil, fmt.Errorf("failed to get page size: %w", err)
+	}
+	if err := dm.storage.db.QueryRow("PRAGMA freelist_count").Scan(&status.FreePages); err != nil {
+		return nil, fmt.Errorf("failed to get free pages: %w", err)
+	}
+	if err := dm.storage.db.QueryRow("PRAGMA journal_mode").Scan(&status.JournalMode); err != nil {
+		return nil, fmt.Errorf("failed to get journal mode: %w", err)
+	}
+	if status.UsedSize, err = dm.storage.usedDatabaseSize(); err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	if status.Migrations, err = dm.migrations.GetMigrationStatus(); err != nil {
+		return nil, err
+	}
+	if err := dm.migrations.ValidateSchema(); err != nil {
+		status.SchemaError = err.Error()
+	}
+
+	return status, nil
+}
+
+// Migrate applies pending migrations and returns the applied versions and
+// the backup written before
+func (dm *DatabaseMaintenance) Migrate() ([]string, string, error) {
+	lock, err := dm.lockSchema()
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlockDatabase(lock)
+
+	before, err := dm.migrations.GetMigrationStatus()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := dm.migrations.ApplyMigrations(); err != nil {
+		return nil, dm.migrations.LastBackup(), err
+	}
+	if err := dm.storage.initializeMetadata(); err != nil {
+		return nil, dm.migrations.LastBackup(), fmt.Errorf("failed to initialize metadata: %w", err)
+	}
+	if err := dm.migrations.ensureSchemaHash(); err != nil {
+		return nil, dm.migrations.LastBackup(), fmt.Errorf("failed to record schema hash: %w", err)
+	}
+
+	var applied []string
+	for _, migration := range before {
+		if !migration.Applied {
+			applied = append(applied, migration.Version)
+		}
+	}
+	return applied, dm.migrations.LastBackup(), nil
+}
+
+// Rollback rolls back the last applied migration and returns its version and
+// the backup written before
+func (dm *DatabaseMaintenance) Rollback() (string, string, error) {
+	lock, err := dm.lockSchema()
+	if err != nil {
+		return "", "", err
+	}
+	defer unlockDatabase(lock)
+
+	version, err := dm.migrations.RollbackMigration()
+	return version, dm.migrations.LastBackup(), err
+}
+
+// lockSchema locks the database for a schema change, failing while an agent
+// or another command has it open
+func (dm *DatabaseMaintenance) lockSchema() (*os.File, error) {
+	lock, err := lockDatabase(dm.storage.config.DatabasePath, true)
+	if errors.Is(err, ErrDatabaseLocked) {
+		return nil, fmt.Errorf("database is in use, stop the agent first: %w", err)
+	}
+	return lock, err
+}
+
+// Validate returns the problems found in the schema, none when it matches
+// this version
+func (dm *DatabaseMaintenance) Validate() ([]string, error) {
+	migrations, err := dm.migrations.GetMigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, migration := range migrations {
+		switch {
+		case migration.Unknown:
+			problems = append(problems, fmt.Sprintf("migration %s was applied by a newer version", migration.Version))
+		case !migration.Applied:
+			problems = append(problems, fmt.Sprintf("migration %s is pending", migration.Version))
+		case migration.Modified:
+			problems = append(problems, fmt.Sprintf("migration %s was applied with different SQL", migration.Version))
+		}
+	}
+
+	// The hash is only updated by applying migrations
+	if len(problems) == 0 {
+		if err := dm.migrations.ValidateSchema(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems, nil
+}
+
+// Vacuum rebuilds the database file and returns its size before and after
+func (dm *DatabaseMaintenance) Vacuum() (int64, int64, error) {
+	info, err := os.Stat(dm.storage.config.DatabasePath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat database: %w", err)
+	}
+	before := info.Size()
+
+	if err := dm.storage.Vacuum(); err != nil {
+		return before, 0, err
+	}
+	// Move the rebuilt pages from the WAL into the file
+	if _, err := dm.storage.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return before, 0, fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+
+	if info, err = os.Stat(dm.storage.config.DatabasePath); err != nil {
+		return before, 0, fmt.Errorf("failed to stat database: %w", err)
+	}
+	return before, info.Size(), nil
+}
+
+// IntegrityCheck returns the problems reported by SQLite's integrity and
+// foreign key checks, none when the database is intact
+func (dm *DatabaseMaintenance) IntegrityCheck() ([]string, error) {
+	rows, err := dm.storage.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+
+	fkRows, err := dm.storage.db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	defer fkRows.Close()
+
+	for fkRows.Next() {
+		var table, parent string
+		var rowID *int64
+		var fkID int64
+		if err := fkRows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key check: %w", err)
+		}
+		row := "without rowid"
+		if rowID != nil {
+			row = fmt.Sprintf("%d", *rowID)
+		}
+		problems = append(problems, fmt.Sprintf("%s row %s references a missing %s row", table, row, parent))
+	}
+	if err := fkRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,849 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"crucible/internal/logging"
+)
+
+// Keep the most recent configuration versions only
+const memoryMaxConfigVersions = 100
+
+// Keep the most recent incidents only
+const memoryMaxIncidents = 1000
+
+// MemoryConfig represents in-memory storage configuration
+type MemoryConfig struct {
+	MaxEvents  int
+	MaxMetrics int
+}
+
+// MemoryStorage implements storage in ring buffers, so the newest events and
+// metrics are kept within a fixed memory budget and nothing survives a restart
+type MemoryStorage struct {
+	mu     sync.RWMutex
+	config *MemoryConfig
+	logger *logging.Logger
+	closed bool
+
+	entities       map[int64]*Entity
+	events         *ringBuffer[*Event]
+	metrics        *ringBuffer[*Metric]
+	configVersions []*ConfigVersion
+	incidents      []*Incident
+
+	nextEntityID   int64
+	nextEventID    int64
+	nextMetricID   int64
+	nextVersionID  int64
+	nextIncidentID int64
+
+	createdAt   time.Time
+	lastCleanup *time.Time
+}
+
+// NewMemoryStorage creates a new in-memory storage instance
+func NewMemoryStorage(config *MemoryConfig, logger *logging.Logger) *MemoryStorage {
+	if config == nil {
+		config = &MemoryConfig{}
+	}
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = 10000
+	}
+	if config.MaxMetrics <= 0 {
+		config.MaxMetrics = 100000
+	}
+
+	return &MemoryStorage{
+		config:    config,
+		logger:    logger,
+		entities:  make(map[int64]*Entity),
+		events:    newRingBuffer[*Event](config.MaxEvents),
+		metrics:   newRingBuffer[*Metric](config.MaxMetrics),
+		createdAt: time.Now(),
+	}
+}
+
+// ENTITY OPERATIONS
+
+// CreateEntity stores a new entity
+func (m *MemoryStorage) CreateEntity(entity *Entity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.entities {
+		if existing.Type == entity.Type && existing.Name == entity.Name {
+			return fmt.Errorf("failed to create entity: entity already exists: %s/%s", entity.Type, entity.Name)
+		}
+	}
+
+	m.nextEntityID++
+	entity.ID = m.nextEntityID
+	m.entities[entity.ID] = copyEntity(entity)
+
+	return nil
+}
+
+// GetEntity returns an entity by ID
+func (m *MemoryStorage) GetEntity(id int64) (*Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entity, exists := m.entities[id]
+	if !exists {
+		return nil, fmt.Errorf("entity not found: %d", id)
+	}
+	return copyEntity(entity), nil
+}
+
+// GetEntityByName returns an entity by type and name
+func (m *MemoryStorage) GetEntityByName(entityType, name string) (*Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entity := range m.entities {
+		if entity.Type == entityType && entity.Name == name {
+			return copyEntity(entity), nil
+		}
+	}
+	return nil, fmt.Errorf("entity not found: %s/%s", entityType, name)
+}
+
+// UpdateEntity replaces an existing entity
+func (m *MemoryStorage) UpdateEntity(entity *Entity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.entities[entity.ID]
+	if !exists {
+		return fmt.Errorf("entity not found: %d", entity.ID)
+	}
+
+	// Like the SQLite backend, type, name and creation time are immutable
+	entity.UpdatedAt = time.Now()
+	updated := copyEntity(entity)
+	updated.Type, updated.Name, updated.CreatedAt = existing.Type, existing.Name, existing.CreatedAt
+	m.entities[entity.ID] = updated
+	return nil
+}
+
+// DeleteEntity removes an entity
+func (m *MemoryStorage) DeleteEntity(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entities[id]; !exists {
+		return fmt.Errorf("entity not found: %d", id)
+	}
+	delete(m.entities, id)
+	return nil
+}
+
+// ListEntities returns entities based on filter criteria, most recently updated first
+func (m *MemoryStorage) ListEntities(filter *EntityFilter) ([]*Entity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entities []*Entity
+	for _, entity := range m.entities {
+		if filter != nil {
+			if filter.Type != nil && entity.Type != *filter.Type {
+				continue
+			}
+			if filter.Status != nil && entity.Status != *filter.Status {
+				continue
+			}
+			if filter.Name != nil && !strings.Contains(entity.Name, *filter.Name) {
+				continue
+			}
+			if filter.Since != nil && entity.UpdatedAt.Before(*filter.Since) {
+				continue
+			}
+		}
+		entities = append(entities, copyEntity(entity))
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].UpdatedAt.After(entities[j].UpdatedAt)
+	})
+
+	if filter != nil {
+		entities = paginate(entities, filter.Offset, filter.Limit)
+	}
+	return entities, nil
+}
+
+// EVENT OPERATIONS
+
+// CreateEvent stores a new event, dropping the oldest one when the buffer is full
+func (m *MemoryStorage) CreateEvent(event *Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextEventID++
+	event.ID = m.nextEventID
+	m.events.push(copyEvent(event))
+	return nil
+}
+
+// GetEvent returns an event by ID
+func (m *MemoryStorage) GetEvent(id int64) (*Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *Event
+	m.events.each(func(event *Event) bool {
+		if event.ID == id {
+			found = copyEvent(event)
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, fmt.Errorf("event not found: %d", id)
+	}
+	return found, nil
+}
+
+// ListEvents returns events based on filter criteria, newest first
+func (m *MemoryStorage) ListEvents(filter *EventFilter) ([]*Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var events []*Event
+	m.events.each(func(event *Event) bool {
+		if filter != nil {
+			if filter.EntityID != nil && (event.EntityID == nil || *event.EntityID != *filter.EntityID) {
+				return true
+			}
+			if filter.Type != nil && event.Type != *filter.Type {
+				return true
+			}
+			if filter.Severity != nil && event.Severity != *filter.Severity {
+				return true
+			}
+			if filter.Since != nil && event.Timestamp.Before(*filter.Since) {
+				return true
+			}
+			if filter.Until != nil && event.Timestamp.After(*filter.Until) {
+				return true
+			}
+		}
+		events = append(events, copyEvent(event))
+		return true
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.After(events[j].Timestamp)
+	})
+
+	if filter != nil {
+		events = paginate(events, filter.Offset, filter.Limit)
+	}
+	return events, nil
+}
+
+// DeleteEvent removes an event
+func (m *MemoryStorage) DeleteEvent(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.events.removeFunc(func(event *Event) bool { return event.ID == id }) == 0 {
+		return fmt.Errorf("event not found: %d", id)
+	}
+	return nil
+}
+
+// METRIC OPERATIONS
+
+// CreateMetric stores a new metric sample, dropping the oldest one when the buffer is full
+func (m *MemoryStorage) CreateMetric(metric *Metric) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextMetricID++
+	metric.ID = m.nextMetricID
+	m.metrics.push(copyMetric(metric))
+	return nil
+}
+
+// GetMetric returns a metric sample by ID
+func (m *MemoryStorage) GetMetric(id int64) (*Metric, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *Metric
+	m.metrics.each(func(metric *Metric) bool {
+		if metric.ID == id {
+			found = copyMetric(metric)
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, fmt.Errorf("metric not found: %d", id)
+	}
+	return found, nil
+}
+
+// ListMetrics returns metric samples based on filter criteria, newest first
+func (m *MemoryStorage) ListMetrics(filter *MetricFilter) ([]*Metric, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metrics := m.filterMetrics(filter)
+	for i, metric := range metrics {
+		metrics[i] = copyMetric(metric)
+	}
+
+	if filter != nil {
+		metrics = paginate(metrics, filter.Offset, filter.Limit)
+	}
+	return metrics, nil
+}
+
+// GetMetricSummary returns aggregated data for the metric samples matching the filter
+func (m *MemoryStorage) GetMetricSummary(filter *MetricFilter) (*MetricSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	metrics := m.filterMetrics(filter)
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metrics found")
+	}
+
+	// Samples are sorted newest first
+	latest := metrics[0]
+	summary := &MetricSummary{
+		EntityID:   latest.EntityID,
+		MetricName: latest.MetricName,
+		Count:      int64(len(metrics)),
+		Min:        latest.Value,
+		Max:        latest.Value,
+		Latest:     latest.Value,
+		Timestamp:  latest.Timestamp,
+	}
+
+	var sum float64
+	for _, metric := range metrics {
+		sum += metric.Value
+		if metric.Value < summary.Min {
+			summary.Min = metric.Value
+		}
+		if metric.Value > summary.Max {
+			summary.Max = metric.Value
+		}
+	}
+	summary.Average = sum / float64(len(metrics))
+
+	return summary, nil
+}
+
+// QueryMetrics returns a metric downsampled to aligned steps
+func (m *MemoryStorage) QueryMetrics(query *MetricQuery) (*MetricQueryResult, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	filter := &MetricFilter{
+		EntityID:         query.EntityID,
+		MetricName:       &query.MetricName,
+		AggregationLevel: query.AggregationLevel,
+		Tags:             query.Tags,
+		Since:            &query.Since,
+	}
+	metrics := m.filterMetrics(filter)
+
+	// filterMetrics returns the newest first, rate needs them in time order
+	accumulator := newQueryAccumulator(query)
+	for i := len(metrics) - 1; i >= 0; i-- {
+		metric := metrics[i]
+		accumulator.add(metricGroupLabels(query.GroupBy, metric), metricSource(metric), metric.Timestamp.Unix(), metric.Value)
+	}
+
+	return accumulator.result(), nil
+}
+
+// DeleteMetric removes a metric sample
+func (m *MemoryStorage) DeleteMetric(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.metrics.removeFunc(func(metric *Metric) bool { return metric.ID == id }) == 0 {
+		return fmt.Errorf("metric not found: %d", id)
+	}
+	return nil
+}
+
+// filterMetrics returns the stored samples matching the filter, newest first
+func (m *MemoryStorage) filterMetrics(filter *MetricFilter) []*Metric {
+	var metrics []*Metric
+	m.metrics.each(func(metric *Metric) bool {
+		if filter != nil {
+			if filter.EntityID != nil && (metric.EntityID == nil || *metric.EntityID != *filter.EntityID) {
+				return true
+			}
+			if filter.MetricName != nil && metric.MetricName != *filter.MetricName {
+				return true
+			}
+			if filter.AggregationLevel != nil && metric.AggregationLevel != *filter.AggregationLevel {
+				return true
+			}
+			if !matchesTags(metric, filter.Tags) {
+				return true
+			}
+			if filter.Since != nil && metric.Timestamp.Before(*filter.Since) {
+				return true
+			}
+			if filter.Until != nil && metric.Timestamp.After(*filter.Until) {
+				return true
+			}
+		}
+		metrics = append(metrics, metric)
+		return true
+	})
+
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Timestamp.After(metrics[j].Timestamp)
+	})
+
+	return metrics
+}
+
+// BATCH OPERATIONS
+
+// BatchWrite writes multiple items
+func (m *MemoryStorage) BatchWrite(items []BatchItem) error {
+	for _, item := range items {
+		switch data := item.Data.(type) {
+		case *Entity:
+			if err := m.CreateEntity(data); err != nil {
+				return err
+			}
+		case *Event:
+			if err := m.CreateEvent(data); err != nil {
+				return err
+			}
+		case *Metric:
+			if err := m.CreateMetric(data); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown batch item type: %s", item.Type)
+		}
+	}
+	return nil
+}
+
+// FlushBatch is a no-op as writes are applied immediately
+func (m *MemoryStorage) FlushBatch() error {
+	return nil
+}
+
+// CONFIG VERSION OPERATIONS
+
+// CreateConfigVersion stores a revision of the configuration files
+func (m *MemoryStorage) CreateConfigVersion(version *ConfigVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+
+	m.nextVersionID++
+	version.ID = m.nextVersionID
+
+	stored := *version
+	m.configVersions = append(m.configVersions, &stored)
+	if len(m.configVersions) > memoryMaxConfigVersions {
+		m.configVersions = m.configVersions[len(m.configVersions)-memoryMaxConfigVersions:]
+	}
+
+	return nil
+}
+
+// GetConfigVersion returns a configuration revision including file contents
+func (m *MemoryStorage) GetConfigVersion(id int64) (*ConfigVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, version := range m.configVersions {
+		if version.ID == id {
+			found := *version
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("config version not found: %d", id)
+}
+
+// ListConfigVersions returns the most recent configuration revisions without file contents
+func (m *MemoryStorage) ListConfigVersions(limit int) ([]*ConfigVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var versions []*ConfigVersion
+	for i := len(m.configVersions) - 1; i >= 0 && len(versions) < limit; i-- {
+		version := m.configVersions[i]
+		versions = append(versions, &ConfigVersion{
+			ID:          version.ID,
+			CreatedAt:   version.CreatedAt,
+			Description: version.Description,
+		})
+	}
+	return versions, nil
+}
+
+// INCIDENT OPERATIONS
+
+// CreateIncident stores a newly opened incident, dropping the oldest one beyond the limit
+func (m *MemoryStorage) CreateIncident(incident *Incident) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextIncidentID++
+	incident.ID = m.nextIncidentID
+
+	m.incidents = append(m.incidents, copyIncident(incident))
+	if len(m.incidents) > memoryMaxIncidents {
+		m.incidents = m.incidents[len(m.incidents)-memoryMaxIncidents:]
+	}
+
+	return nil
+}
+
+// UpdateIncident updates the status, message and details of an incident
+func (m *MemoryStorage) UpdateIncident(incident *Incident) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.incidents {
+		if existing.ID == incident.ID {
+			updated := copyIncident(incident)
+			updated.RuleID, updated.Title, updated.OpenedAt = existing.RuleID, existing.Title, existing.OpenedAt
+			m.incidents[i] = updated
+			return nil
+		}
+	}
+	return fmt.Errorf("incident not found: %d", incident.ID)
+}
+
+// GetIncident returns an incident by ID
+func (m *MemoryStorage) GetIncident(id int64) (*Incident, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, incident := range m.incidents {
+		if incident.ID == id {
+			return copyIncident(incident), nil
+		}
+	}
+	return nil, fmt.Errorf("incident not found: %d", id)
+}
+
+// ListIncidents returns incidents based on filter criteria, most recently opened first
+func (m *MemoryStorage) ListIncidents(filter *IncidentFilter) ([]*Incident, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var incidents []*Incident
+	for i := len(m.incidents) - 1; i >= 0; i-- {
+		incident := m.incidents[i]
+		if filter != nil {
+			if filter.Status != nil && incident.Status != *filter.Status {
+				continue
+			}
+			if filter.RuleID != nil && incident.RuleID != *filter.RuleID {
+				continue
+			}
+			if filter.Since != nil && incident.OpenedAt.Before(*filter.Since) {
+				continue
+			}
+			if filter.Until != nil && incident.OpenedAt.After(*filter.Until) {
+				continue
+			}
+		}
+		incidents = append(incidents, copyIncident(incident))
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].OpenedAt.After(incidents[j].OpenedAt)
+	})
+
+	if filter != nil {
+		incidents = paginate(incidents, filter.Offset, filter.Limit)
+	}
+	return incidents, nil
+}
+
+// MAINTENANCE OPERATIONS
+
+// Cleanup removes expired events and metrics, the ring buffers bound everything else
+func (m *MemoryStorage) Cleanup() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.events.removeFunc(func(event *Event) bool {
+		return event.ExpiresAt != nil && event.ExpiresAt.Before(now)
+	})
+	m.metrics.removeFunc(func(metric *Metric) bool {
+		return metric.ExpiresAt != nil && metric.ExpiresAt.Before(now)
+	})
+	m.lastCleanup = &now
+
+	return nil
+}
+
+// Vacuum is a no-op for in-memory storage
+func (m *MemoryStorage) Vacuum() error {
+	return nil
+}
+
+// GetSystemHealth returns overall system health metrics
+func (m *MemoryStorage) GetSystemHealth() (*SystemHealth, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	health := &SystemHealth{
+		GeneratedAt: time.Now(),
+	}
+
+	for _, entity := range m.entities {
+		health.TotalEntities++
+		switch entity.Status {
+		case EntityStatusActive:
+			health.ActiveEntities++
+		case EntityStatusError:
+			health.ErrorEntities++
+		}
+		switch {
+		case entity.Type == EntityTypeService && entity.Status == EntityStatusActive:
+			health.ServicesUp++
+		case entity.Type == EntityTypeService:
+			health.ServicesDown++
+		case entity.Type == EntityTypeSite && entity.Status == EntityStatusActive:
+			health.SitesActive++
+		}
+	}
+
+	dayAgo := time.Now().AddDate(0, 0, -1)
+	m.events.each(func(event *Event) bool {
+		if event.Timestamp.Before(dayAgo) {
+			return true
+		}
+		health.RecentEvents++
+		if event.Severity == SeverityError || event.Severity == SeverityCritical {
+			health.RecentErrors++
+		}
+		if event.Type == EventTypeBackup && event.Severity == SeverityInfo &&
+			(health.LastBackup == nil || event.Timestamp.After(*health.LastBackup)) {
+			timestamp := event.Timestamp
+			health.LastBackup = &timestamp
+		}
+		return true
+	})
+
+	for _, metricName := range []string{"cpu_usage", "memory_usage", "disk_usage_root", "load_1"} {
+		name := metricName
+		metrics := m.filterMetrics(&MetricFilter{MetricName: &name, Since: &dayAgo})
+		if len(metrics) == 0 {
+			continue
+		}
+
+		summary := MetricSummary{
+			MetricName: metricName,
+			Count:      int64(len(metrics)),
+			Min:        metrics[0].Value,
+			Max:        metrics[0].Value,
+			Latest:     metrics[0].Value,
+			Timestamp:  metrics[0].Timestamp,
+		}
+		var sum float64
+		for _, metric := range metrics {
+			sum += metric.Value
+			if metric.Value < summary.Min {
+				summary.Min = metric.Value
+			}
+			if metric.Value > summary.Max {
+				summary.Max = metric.Value
+			}
+		}
+		summary.Average = sum / float64(len(metrics))
+		health.MetricsSummary = append(health.MetricsSummary, summary)
+	}
+
+	return health, nil
+}
+
+// GetDatabaseInfo returns storage statistics in the same shape as the SQLite backend
+func (m *MemoryStorage) GetDatabaseInfo() (*DatabaseInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return &DatabaseInfo{
+		DBVersion:       "memory",
+		CrucibleVersion: "1.0.0",
+		CreatedAt:       m.createdAt,
+		UpdatedAt:       time.Now(),
+		LastCleanup:     m.lastCleanup,
+		EntityCount:     int64(len(m.entities)),
+		EventCount:      int64(m.events.len()),
+		MetricCount:     int64(m.metrics.len()),
+	}, nil
+}
+
+// Health reports whether the storage is usable
+func (m *MemoryStorage) Health() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return fmt.Errorf("storage is closed")
+	}
+	return nil
+}
+
+// Close releases the stored data
+func (m *MemoryStorage) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.entities = make(map[int64]*Entity)
+	m.events = newRingBuffer[*Event](m.config.MaxEvents)
+	m.metrics = newRingBuffer[*Metric](m.config.MaxMetrics)
+	m.configVersions = nil
+	return nil
+}
+
+// ringBuffer is a fixed-capacity FIFO that overwrites its oldest item when full
+type ringBuffer[T any] struct {
+	items []T
+	start int
+	size  int
+}
+
+// newRingBuffer creates a ring buffer holding up to capacity items
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	return &ringBuffer[T]{items: make([]T, capacity)}
+}
+
+// push appends an item, overwriting the oldest one when the buffer is full
+func (r *ringBuffer[T]) push(item T) {
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = item
+		r.size++
+		return
+	}
+	r.items[r.start] = item
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// len returns the number of items in the buffer
+func (r *ringBuffer[T]) len() int {
+	return r.size
+}
+
+// each calls fn for every item from oldest to newest until fn returns false
+func (r *ringBuffer[T]) each(fn func(item T) bool) {
+	for i := 0; i < r.size; i++ {
+		if !fn(r.items[(r.start+i)%len(r.items)]) {
+			return
+		}
+	}
+}
+
+// removeFunc removes the items for which fn returns true and returns how many were removed
+func (r *ringBuffer[T]) removeFunc(fn func(item T) bool) int {
+	kept := make([]T, 0, r.size)
+	r.each(func(item T) bool {
+		if !fn(item) {
+			kept = append(kept, item)
+		}
+		return true
+	})
+
+	removed := r.size - len(kept)
+	if removed == 0 {
+		return 0
+	}
+
+	var zero T
+	for i := range r.items {
+		r.items[i] = zero
+	}
+	copy(r.items, kept)
+	r.start, r.size = 0, len(kept)
+
+	return removed
+}
+
+// paginate applies offset and limit to a result list
+func paginate[T any](items []T, offset, limit *int) []T {
+	if offset != nil && *offset > 0 {
+		if *offset >= len(items) {
+			return nil
+		}
+		items = items[*offset:]
+	}
+	if limit != nil && *limit >= 0 && *limit < len(items) {
+		items = items[:*limit]
+	}
+	return items
+}
+
+// copyEntity returns a copy of an entity that does not share its details map
+func copyEntity(entity *Entity) *Entity {
+	copied := *entity
+	copied.Details = copyJSON(entity.Details)
+	if entity.LastSeen != nil {
+		lastSeen := *entity.LastSeen
+		copied.LastSeen = &lastSeen
+	}
+	return &copied
+}
+
+// copyEvent returns a copy of an event that does not share its details map
+func copyEvent(event *Event) *Event {
+	copied := *event
+	copied.Details = copyJSON(event.Details)
+	return &copied
+}
+
+// copyIncident returns a copy of an incident that does not share its details map
+func copyIncident(incident *Incident) *Incident {
+	copied := *incident
+	copied.Details = copyJSON(incident.Details)
+	return &copied
+}
+
+// copyMetric returns a copy of a metric that does not share its tags map
+func copyMetric(metric *Metric) *Metric {
+	copied := *metric
+	copied.Tags = copyJSON(metric.Tags)
+	return &copied
+}
+
+// copyJSON returns a shallow copy of a JSON map
+func copyJSON(data JSON) JSON {
+	if data == nil {
+		return nil
+	}
+	copied := make(JSON, len(data))
+	for key, value := range data {
+		copied[key] = value
+	}
+	return copied
+}
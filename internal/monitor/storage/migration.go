@@ -21,9 +21,11 @@ type Migration struct {
 
 // MigrationManager handles database schema migrations
 type MigrationManager struct {
-	db         *sql.DB
-	logger     *logging.Logger
-	migrations []Migration
+	db           *sql.DB
+	logger       *logging.Logger
+	migrations   []Migration
+	databasePath string // Backups are written next to this file, empty for none
+	lastBackup   string
 }
 
 // NewMigrationManager creates a new migration manager
@@ -35,6 +37,20 @@ func NewMigrationManager(db *sql.DB, logger *logging.Logger) *MigrationManager {
 	}
 }
 
+// SetBackupPath enables a backup of the database file before migrations and
+// rollbacks change its schema
+func (mm *MigrationManager) SetBackupPath(databasePath string) {
+	if databasePath == ":memory:" || strings.Contains(databasePath, "mode=memory") {
+		return
+	}
+	mm.databasePath = databasePath
+}
+
+// LastBackup returns the path of the latest backup, empty when none was written
+func (mm *MigrationManager) LastBackup() string {
+	return mm.lastBackup
+}
+
 // GetAllMigrations returns all available migrations in order
 func GetAllMigrations() []Migration {
 	return []Migration{
@@ -152,6 +168,153 @@ func GetAllMigrations() []Migration {
 				DROP TABLE IF EXISTS schema_migrations;
 			`,
 		},
+		{
+			Version:     "1.2.0",
+			Description: "Add configuration version history",
+			UpSQL: `
+				-- Revisions of monitor.yaml and alerts.yaml written through the API
+				CREATE TABLE IF NOT EXISTS config_versions (
+					id INTEGER PRIMARY KEY,
+					created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+					description TEXT,
+					monitor_config TEXT NOT NULL,
+					alerts_config TEXT NOT NULL
+				);
+			`,
+			DownSQL: `
+				DROP TABLE IF EXISTS config_versions;
+			`,
+		},
+		{
+			Version:     "1.3.0",
+			Description: "Store metrics as tag-indexed series and samples",
+			UpSQL: `
+				-- One row per metric name, entity and canonical tag set (JSON with sorted keys)
+				CREATE TABLE IF NOT EXISTS series (
+					id INTEGER PRIMARY KEY,
+					metric_name TEXT NOT NULL,
+					entity_id INTEGER,
+					tags TEXT NOT NULL DEFAULT '{}',
+					created_at INTEGER NOT NULL DEFAULT (unixepoch()),
+					FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+				);
+
+				-- Tags of each series, so tag filters use an index instead of scanning JSON
+				CREATE TABLE IF NOT EXISTS series_tags (
+					series_id INTEGER NOT NULL,
+					key TEXT NOT NULL,
+					value TEXT,
+					PRIMARY KEY (series_id, key),
+					FOREIGN KEY (series_id) REFERENCES series(id) ON DELETE CASCADE
+				) WITHOUT ROWID;
+
+				-- Metric values keyed by series and timestamp
+				CREATE TABLE IF NOT EXISTS samples (
+					id INTEGER PRIMARY KEY,
+					series_id INTEGER NOT NULL,
+					timestamp INTEGER NOT NULL,
+					value REAL NOT NULL,
+					aggregation_level TEXT NOT NULL DEFAULT 'raw',
+					sample_count INTEGER NOT NULL DEFAULT 1,
+					expires_at INTEGER,
+					FOREIGN KEY (series_id) REFERENCES series(id) ON DELETE CASCADE
+				);
+
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_series_identity ON series(metric_name, IFNULL(entity_id, 0), tags);
+				CREATE INDEX IF NOT EXISTS idx_series_entity_id ON series(entity_id);
+				CREATE INDEX IF NOT EXISTS idx_series_tags_key_value ON series_tags(key, value);
+				CREATE INDEX IF NOT EXISTS idx_samples_series_time ON samples(series_id, timestamp);
+				CREATE INDEX IF NOT EXISTS idx_samples_timestamp ON samples(timestamp);
+				CREATE INDEX IF NOT EXISTS idx_samples_expires_at ON samples(expires_at) WHERE expires_at IS NOT NULL;
+
+				-- Convert existing metrics. Legacy tags also carried sample values such as
+				-- CPU time split, memory and disk sizes or status codes, which would make
+				-- nearly every sample a series of its own, so only stable labels are kept.
+				-- Tags were written by encoding/json so the remaining keys are already sorted.
+				CREATE TEMP TABLE legacy_metric_tags AS
+				SELECT id, json_remove(IFNULL(NULLIF(tags, 'null'), '{}'),
+					'$.user', '$.system', '$.idle', '$.iowait',
+					'$.total', '$.available', '$.used', '$.free',
+					'$.status_code', '$.avg60', '$.avg300', '$.recent_restarts',
+					'$.listen', '$.max_backlog', '$.syn_sent', '$.syn_recv',
+					'$.fin_wait1', '$.fin_wait2', '$.last_ack', '$.closing') AS tags
+				FROM metrics;
+
+				INSERT OR IGNORE INTO series (metric_name, entity_id, tags)
+				SELECT DISTINCT metrics.metric_name, metrics.entity_id, legacy.tags
+				FROM metrics JOIN legacy_metric_tags AS legacy ON legacy.id = metrics.id;
+
+				INSERT INTO series_tags (series_id, key, value)
+				SELECT series.id, tag.key, tag.value FROM series, json_each(series.tags) AS tag;
+
+				INSERT INTO samples (id, series_id, timestamp, value, aggregation_level, sample_count, expires_at)
+				SELECT metrics.id, series.id, metrics.timestamp, metrics.value,
+					IFNULL(metrics.aggregation_level, 'raw'), IFNULL(metrics.sample_count, 1), metrics.expires_at
+				FROM metrics
+				JOIN legacy_metric_tags AS legacy ON legacy.id = metrics.id
+				JOIN series
+					ON series.metric_name = metrics.metric_name
+					AND IFNULL(series.entity_id, 0) = IFNULL(metrics.entity_id, 0)
+					AND series.tags = legacy.tags;
+
+				DROP TABLE legacy_metric_tags;
+				DROP TABLE metrics;
+			`,
+			DownSQL: `
+				CREATE TABLE IF NOT EXISTS metrics (
+					id INTEGER PRIMARY KEY,
+					entity_id INTEGER,
+					timestamp INTEGER NOT NULL,
+					metric_name TEXT NOT NULL,
+					value REAL NOT NULL,
+					aggregation_level TEXT DEFAULT 'raw',
+					sample_count INTEGER DEFAULT 1,
+					tags JSON,
+					expires_at INTEGER,
+					FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+				);
+
+				INSERT INTO metrics (id, entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at)
+				SELECT samples.id, series.entity_id, samples.timestamp, series.metric_name, samples.value,
+					samples.aggregation_level, samples.sample_count, NULLIF(series.tags, '{}'), samples.expires_at
+				FROM samples JOIN series ON series.id = samples.series_id;
+
+				CREATE INDEX IF NOT EXISTS idx_metrics_timestamp ON metrics(timestamp);
+				CREATE INDEX IF NOT EXISTS idx_metrics_metric_name ON metrics(metric_name);
+				CREATE INDEX IF NOT EXISTS idx_metrics_entity_id ON metrics(entity_id);
+				CREATE INDEX IF NOT EXISTS idx_metrics_entity_metric_time ON metrics(entity_id, metric_name, timestamp);
+				CREATE INDEX IF NOT EXISTS idx_metrics_aggregation_level ON metrics(aggregation_level);
+				CREATE INDEX IF NOT EXISTS idx_metrics_expires_at ON metrics(expires_at) WHERE expires_at IS NOT NULL;
+
+				DROP TABLE IF EXISTS samples;
+				DROP TABLE IF EXISTS series_tags;
+				DROP TABLE IF EXISTS series;
+			`,
+		},
+		{
+			Version:     "1.4.0",
+			Description: "Add incidents",
+			UpSQL: `
+				-- One row per alert from firing to resolution
+				CREATE TABLE IF NOT EXISTS incidents (
+					id INTEGER PRIMARY KEY,
+					rule_id TEXT NOT NULL,
+					title TEXT NOT NULL,
+					severity TEXT NOT NULL,
+					status TEXT NOT NULL DEFAULT 'open',
+					message TEXT,
+					details JSON,
+					opened_at INTEGER NOT NULL,
+					resolved_at INTEGER
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_incidents_opened_at ON incidents(opened_at);
+				CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents(status);
+			`,
+			DownSQL: `
+				DROP TABLE IF EXISTS incidents;
+			`,
+		},
 	}
 }
 
@@ -184,6 +347,17 @@ func (mm *MigrationManager) ApplyMigrations() error {
 
 	mm.logger.Info("Applying migrations", "count", len(pending))
 
+	// New databases have nothing to lose
+	var tables int
+	if err := mm.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name != 'schema_migrations'`).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	if tables > 0 {
+		if err := mm.backupDatabase("migration-" + pending[len(pending)-1].Version); err != nil {
+			return err
+		}
+	}
+
 	for _, migration := range pending {
 		if err := mm.applyMigration(migration); err != nil {
 			return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
@@ -199,21 +373,21 @@ func (mm *MigrationManager) ApplyMigrations() error {
 	return nil
 }
 
-// RollbackMigration rolls back the last applied migration
-func (mm *MigrationManager) RollbackMigration() error {
+// RollbackMigration rolls back the last applied migration and returns its version
+func (mm *MigrationManager) RollbackMigration() (string, error) {
 	// Get the last applied migration
 	var version, description string
 	err := mm.db.QueryRow(`
 		SELECT version, description 
 		FROM schema_migrations 
-		ORDER BY applied_at DESC 
+		ORDER BY applied_at DESC, version DESC 
 		LIMIT 1
 	`).Scan(&version, &description)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("no migrations to rollback")
+			return "", fmt.Errorf("no migrations to rollback")
 		}
-		return fmt.Errorf("failed to get last migration: %w", err)
+		return "", fmt.Errorf("failed to get last migration: %w", err)
 	}
 
 	// Find the migration
@@ -226,35 +400,55 @@ func (mm *MigrationManager) RollbackMigration() error {
 	}
 
 	if migration == nil {
-		return fmt.Errorf("migration %s not found", version)
+		return "", fmt.Errorf("migration %s not found", version)
 	}
 
 	mm.logger.Info("Rolling back migration", "version", version, "description", description)
 
+	if err := mm.backupDatabase("rollback-" + version); err != nil {
+		return "", err
+	}
+
 	// Execute rollback in transaction
 	tx, err := mm.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// Remove from migrations table first, rolling back 1.1.0 drops it
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, version); err != nil {
+		return "", fmt.Errorf("failed to remove migration record: %w", err)
+	}
+
 	// Execute down SQL
 	if migration.DownSQL != "" {
 		if _, err := tx.Exec(migration.DownSQL); err != nil {
-			return fmt.Errorf("failed to execute rollback SQL: %w", err)
+			return "", fmt.Errorf("failed to execute rollback SQL: %w", err)
 		}
 	}
 
-	// Remove from migrations table
-	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, version); err != nil {
-		return fmt.Errorf("failed to remove migration record: %w", err)
-	}
-
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit rollback: %w", err)
+		return "", fmt.Errorf("failed to commit rollback: %w", err)
 	}
 
 	mm.logger.Info("Migration rolled back successfully", "version", version)
+	return version, nil
+}
+
+// backupDatabase copies the database next to its file before a schema change
+func (mm *MigrationManager) backupDatabase(reason string) error {
+	if mm.databasePath == "" {
+		return nil
+	}
+
+	path := fmt.Sprintf("%s.pre-%s-%s.bak", mm.databasePath, reason, time.Now().Format("20060102-150405"))
+	if _, err := mm.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up database to %s: %w", path, err)
+	}
+
+	mm.lastBackup = path
+	mm.logger.Info("Database backed up", "path", path)
 	return nil
 }
 
@@ -276,11 +470,28 @@ func (mm *MigrationManager) GetMigrationStatus() ([]MigrationStatus, error) {
 		if info, exists := applied[migration.Version]; exists {
 			ms.Applied = true
 			ms.AppliedAt = &info.AppliedAt
+			ms.Modified = info.Checksum != mm.calculateChecksum(migration.UpSQL)
+			delete(applied, migration.Version)
 		}
 
 		status = append(status, ms)
 	}
 
+	// Migrations applied by a newer version
+	for _, info := range applied {
+		appliedAt := info.AppliedAt
+		status = append(status, MigrationStatus{
+			Version:     info.Version,
+			Description: info.Description,
+			Applied:     true,
+			AppliedAt:   &appliedAt,
+			Unknown:     true,
+		})
+	}
+	sort.SliceStable(status, func(i, j int) bool {
+		return status[i].Version < status[j].Version
+	})
+
 	return status, nil
 }
 
@@ -299,6 +510,15 @@ func (mm *MigrationManager) ensureMigrationTable() error {
 
 // getAppliedMigrations returns a map of applied migrations
 func (mm *MigrationManager) getAppliedMigrations() (map[string]AppliedMigration, error) {
+	// Databases from before 1.1.0 have no tracking table
+	var tables int
+	if err := mm.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&tables); err != nil {
+		return nil, err
+	}
+	if tables == 0 {
+		return make(map[string]AppliedMigration), nil
+	}
+
 	rows, err := mm.db.Query(`
 		SELECT version, description, applied_at, checksum 
 		FROM schema_migrations 
@@ -313,11 +533,14 @@ func (mm *MigrationManager) getAppliedMigrations() (map[string]AppliedMigration,
 	for rows.Next() {
 		var am AppliedMigration
 		var appliedAtUnix int64
+		var description, checksum sql.NullString
 
-		err := rows.Scan(&am.Version, &am.Description, &appliedAtUnix, &am.Checksum)
+		err := rows.Scan(&am.Version, &description, &appliedAtUnix, &checksum)
 		if err != nil {
 			return nil, err
 		}
+		am.Description = description.String
+		am.Checksum = checksum.String
 
 		am.AppliedAt = time.Unix(appliedAtUnix, 0)
 		applied[am.Version] = am
@@ -380,41 +603,53 @@ func (mm *MigrationManager) calculateChecksum(sql string) string {
 
 // updateSchemaHash updates the schema hash in metadata table
 func (mm *MigrationManager) updateSchemaHash() error {
-	// Calculate hash of current schema
-	var allSQL strings.Builder
-	for _, migration := range mm.migrations {
-		allSQL.WriteString(migration.UpSQL)
-	}
+	_, err := mm.db.Exec(`
+		UPDATE metadata 
+		SET schema_hash = ?, updated_at = ? 
+		WHERE id = 1
+	`, mm.schemaHash(), time.Now().Unix())
 
-	hash := mm.calculateChecksum(allSQL.String())
+	return err
+}
 
+// ensureSchemaHash records the schema hash when the metadata has none yet
+func (mm *MigrationManager) ensureSchemaHash() error {
 	_, err := mm.db.Exec(`
 		UPDATE metadata 
 		SET schema_hash = ?, updated_at = ? 
-		WHERE id = 1
-	`, hash, time.Now().Unix())
+		WHERE id = 1 AND schema_hash IS NULL
+	`, mm.schemaHash(), time.Now().Unix())
 
 	return err
 }
 
+// schemaHash calculates the hash of all migrations
+func (mm *MigrationManager) schemaHash() string {
+	var allSQL strings.Builder
+	for _, migration := range mm.migrations {
+		allSQL.WriteString(migration.UpSQL)
+	}
+	return mm.calculateChecksum(allSQL.String())
+}
+
 // ValidateSchema validates the current database schema against migrations
 func (mm *MigrationManager) ValidateSchema() error {
 	// Get current schema hash
-	var currentHash string
-	err := mm.db.QueryRow(`SELECT schema_hash FROM metadata WHERE id = 1`).Scan(&currentHash)
+	var hash sql.NullString
+	err := mm.db.QueryRow(`SELECT schema_hash FROM metadata WHERE id = 1`).Scan(&hash)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("metadata not found")
 		}
 		return fmt.Errorf("failed to get schema hash: %w", err)
 	}
+	if !hash.Valid {
+		return fmt.Errorf("schema validation failed: no schema hash recorded")
+	}
+	currentHash := hash.String
 
 	// Calculate expected hash
-	var allSQL strings.Builder
-	for _, migration := range mm.migrations {
-		allSQL.WriteString(migration.UpSQL)
-	}
-	expectedHash := mm.calculateChecksum(allSQL.String())
+	expectedHash := mm.schemaHash()
 
 	if currentHash != expectedHash {
 		return fmt.Errorf("schema validation failed: hash mismatch (expected: %s, got: %s)", expectedHash, currentHash)
@@ -430,6 +665,8 @@ type MigrationStatus struct {
 	Description string     `json:"description"`
 	Applied     bool       `json:"applied"`
 	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	Modified    bool       `json:"modified,omitempty"` // Applied SQL differs from this version's
+	Unknown     bool       `json:"unknown,omitempty"`  // Applied by a newer version
 }
 
 // AppliedMigration represents a migration that has been applied
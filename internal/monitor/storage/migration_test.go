@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crucible/internal/logging"
+)
+
+// legacyMetric is a row of the metrics table as written before series existed
+type legacyMetric struct {
+	entityID int64
+	name     string
+	value    float64
+	tags     map[string]interface{}
+}
+
+// legacyMetrics returns what the storage adapter wrote for one collection
+// round, sample values included in the tags
+func legacyMetrics(round int) []legacyMetric {
+	value := float64(round)
+	return []legacyMetric{
+		{1, "cpu_usage", value, map[string]interface{}{"user": value * 0.6, "system": value * 0.3, "idle": 100 - value, "iowait": value * 0.1}},
+		{1, "memory_usage", value, map[string]interface{}{"total": 16 << 30, "available": round << 20, "used": round << 21, "free": round << 19}},
+		{1, "load_1", value / 10, nil},
+		{1, "tcp_close_wait", value, map[string]interface{}{"syn_sent": round, "syn_recv": round + 1, "fin_wait1": round, "fin_wait2": 0, "last_ack": 0, "closing": 0}},
+		{2, "disk_usage_root", value, map[string]interface{}{"total": 100 << 30, "used": round << 30, "free": (100 - round) << 30}},
+		{3, "response_time_ms", value * 10, map[string]interface{}{"status_code": 200 + round%2*300, "success": round%2 == 0}},
+	}
+}
+
+// TestMigrateLegacyMetrics converts a database of the legacy metrics table
+// and checks sample values in its tags do not become series of their own
+func TestMigrateLegacyMetrics(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := logging.NewLogger(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	driverName, err := sqliteDriverName("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, filepath.Join(dir, "monitor.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Create the schema of the last release without series
+	mm := NewMigrationManager(db, logger)
+	var legacy []Migration
+	for _, migration := range mm.migrations {
+		if migration.Version < "1.3.0" {
+			legacy = append(legacy, migration)
+		}
+	}
+	mm.migrations = legacy
+	if err := mm.ApplyMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	const rounds = 20
+	start := time.Now().Add(-time.Hour)
+	for round := 0; round < rounds; round++ {
+		for _, metric := range legacyMetrics(round) {
+			tags, err := json.Marshal(metric.tags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = db.Exec(`
+				INSERT INTO metrics (entity_id, timestamp, metric_name, value, tags)
+				VALUES (?, ?, ?, ?, ?)`,
+				metric.entityID, start.Add(time.Duration(round)*time.Minute).Unix(), metric.name, metric.value, string(tags))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	mm.migrations = GetAllMigrations()
+	if err := mm.ApplyMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	// One series per metric, response times split by success only
+	var series, samples int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM series`).Scan(&series); err != nil {
+		t.Fatal(err)
+	}
+	if want := len(legacyMetrics(0)) + 1; series != want {
+		t.Errorf("migrated into %d series, want %d", series, want)
+	}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM samples JOIN series ON series.id = samples.series_id`).Scan(&samples); err != nil {
+		t.Fatal(err)
+	}
+	if want := rounds * len(legacyMetrics(0)); samples != want {
+		t.Errorf("migrated %d samples, want %d", samples, want)
+	}
+
+	rows, err := db.Query(`SELECT tags FROM series WHERE metric_name = 'response_time_ms' ORDER BY tags`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			t.Fatal(err)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) != 2 || tags[0] != `{"success":false}` || tags[1] != `{"success":true}` {
+		t.Errorf("response time series have tags %v, want success only", tags)
+	}
+}
@@ -0,0 +1,388 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric query aggregations
+const (
+	QueryAggregationAvg        = "avg"
+	QueryAggregationMax        = "max"
+	QueryAggregationMin        = "min"
+	QueryAggregationSum        = "sum"
+	QueryAggregationCount      = "count"
+	QueryAggregationRate       = "rate"
+	QueryAggregationPercentile = "percentile"
+)
+
+// GroupByEntityID groups a metric query by entity instead of by a tag
+const GroupByEntityID = "entity_id"
+
+const (
+	// Points per series when a query does not set a step
+	defaultQueryPoints = 300
+	// Upper bound on points per series, so wide ranges need a coarser step
+	maxQueryPoints = 10000
+)
+
+// sqlAggregations are computed from per-step count, sum, min and max in the database
+var sqlAggregations = map[string]bool{
+	QueryAggregationAvg:   true,
+	QueryAggregationMax:   true,
+	QueryAggregationMin:   true,
+	QueryAggregationSum:   true,
+	QueryAggregationCount: true,
+}
+
+// Normalize validates the query and fills in defaults. Since and Until are
+// aligned to the step, so repeated queries return the same buckets.
+func (q *MetricQuery) Normalize() error {
+	if q.MetricName == "" {
+		return fmt.Errorf("metric name is required")
+	}
+
+	if q.Until.IsZero() {
+		q.Until = time.Now()
+	}
+	if q.Since.IsZero() {
+		q.Since = q.Until.Add(-time.Hour)
+	}
+	if !q.Since.Before(q.Until) {
+		return fmt.Errorf("since must be before until")
+	}
+
+	if q.Aggregation == "" {
+		q.Aggregation = QueryAggregationAvg
+	}
+	switch q.Aggregation {
+	case QueryAggregationAvg, QueryAggregationMax, QueryAggregationMin, QueryAggregationSum, QueryAggregationCount, QueryAggregationRate:
+	case QueryAggregationPercentile:
+		if q.Percentile == 0 {
+			q.Percentile = 95
+		}
+		if q.Percentile < 0 || q.Percentile > 100 {
+			return fmt.Errorf("percentile must be between 0 and 100")
+		}
+	default:
+		return fmt.Errorf("unsupported aggregation: %s", q.Aggregation)
+	}
+
+	for _, key := range q.GroupBy {
+		if key == "" {
+			return fmt.Errorf("group by keys must not be empty")
+		}
+	}
+
+	// Metrics are stored with second precision
+	if q.Step <= 0 {
+		q.Step = q.Until.Sub(q.Since) / defaultQueryPoints
+	}
+	if q.Step%time.Second != 0 {
+		q.Step = q.Step.Truncate(time.Second) + time.Second
+	}
+
+	step := int64(q.Step / time.Second)
+	since := q.Since.Unix() / step * step
+	until := (q.Until.Unix() + step - 1) / step * step
+	if points := (until - since) / step; points > maxQueryPoints {
+		return fmt.Errorf("query covers %d steps, at most %d are allowed", points, maxQueryPoints)
+	}
+
+	q.Since = time.Unix(since, 0)
+	q.Until = time.Unix(until, 0)
+	return nil
+}
+
+// queryAccumulator buckets samples into series aligned on the query steps
+type queryAccumulator struct {
+	query  *MetricQuery
+	since  int64
+	step   int64
+	points int
+	series map[string]*seriesAccumulator
+	last   map[string]float64
+}
+
+// seriesAccumulator holds the buckets of one label set
+type seriesAccumulator struct {
+	labels  map[string]string
+	buckets []*bucketAccumulator
+}
+
+// bucketAccumulator holds the samples of one step
+type bucketAccumulator struct {
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	values []float64
+}
+
+// newQueryAccumulator creates an accumulator for a normalized query
+func newQueryAccumulator(query *MetricQuery) *queryAccumulator {
+	step := int64(query.Step / time.Second)
+	return &queryAccumulator{
+		query:  query,
+		since:  query.Since.Unix(),
+		step:   step,
+		points: int((query.Until.Unix() - query.Since.Unix()) / step),
+		series: make(map[string]*seriesAccumulator),
+		last:   make(map[string]float64),
+	}
+}
+
+// bucket returns the bucket holding a Unix timestamp, or nil outside the query range
+func (a *queryAccumulator) bucket(labels map[string]string, timestamp int64) *bucketAccumulator {
+	index := int((timestamp - a.since) / a.step)
+	if timestamp < a.since || index >= a.points {
+		return nil
+	}
+
+	series := a.seriesFor(labels)
+	if series.buckets[index] == nil {
+		series.buckets[index] = &bucketAccumulator{}
+	}
+	return series.buckets[index]
+}
+
+// seriesFor returns the series of a label set, creating it if needed
+func (a *queryAccumulator) seriesFor(labels map[string]string) *seriesAccumulator {
+	key := labelKey(labels)
+	series, exists := a.series[key]
+	if !exists {
+		series = &seriesAccumulator{
+			labels:  labels,
+			buckets: make([]*bucketAccumulator, a.points),
+		}
+		a.series[key] = series
+	}
+	return series
+}
+
+// add feeds a raw sample of the source series identified by source, which
+// may be grouped with others. Samples must be added in time order, as rate is
+// computed per source series from the increase over its previous sample and
+// then summed per group.
+func (a *queryAccumulator) add(labels map[string]string, source string, timestamp int64, value float64) {
+	if a.query.Aggregation == QueryAggregationRate {
+		previous, seen := a.last[source]
+		a.last[source] = value
+		if !seen {
+			return
+		}
+
+		bucket := a.bucket(labels, timestamp)
+		if bucket == nil {
+			return
+		}
+		increase := value - previous
+		if increase < 0 {
+			// The counter was reset, count from zero
+			increase = value
+		}
+		bucket.count++
+		bucket.sum += increase
+		return
+	}
+
+	bucket := a.bucket(labels, timestamp)
+	if bucket == nil {
+		return
+	}
+	if bucket.count == 0 || value < bucket.min {
+		bucket.min = value
+	}
+	if bucket.count == 0 || value > bucket.max {
+		bucket.max = value
+	}
+	bucket.count++
+	bucket.sum += value
+	if a.query.Aggregation == QueryAggregationPercentile {
+		bucket.values = append(bucket.values, value)
+	}
+}
+
+// merge combines a step already aggregated by the database into its group
+func (a *queryAccumulator) merge(labels map[string]string, timestamp int64, count int, sum, min, max float64) {
+	bucket := a.bucket(labels, timestamp)
+	if bucket == nil || count == 0 {
+		return
+	}
+	if bucket.count == 0 || min < bucket.min {
+		bucket.min = min
+	}
+	if bucket.count == 0 || max > bucket.max {
+		bucket.max = max
+	}
+	bucket.count += count
+	bucket.sum += sum
+}
+
+// result builds the aligned series, ordered by labels
+func (a *queryAccumulator) result() *MetricQueryResult {
+	result := &MetricQueryResult{
+		Query:       a.query,
+		StepSeconds: a.step,
+		Timestamps:  make([]time.Time, a.points),
+		Series:      []*MetricSeries{},
+	}
+	for i := range result.Timestamps {
+		result.Timestamps[i] = time.Unix(a.since+int64(i)*a.step, 0)
+	}
+
+	keys := make([]string, 0, len(a.series))
+	for key := range a.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		accumulated := a.series[key]
+		series := &MetricSeries{
+			Labels: accumulated.labels,
+			Values: make([]*float64, a.points),
+		}
+
+		hasValues := false
+		for i, bucket := range accumulated.buckets {
+			if bucket == nil {
+				continue
+			}
+			series.Values[i] = a.bucketValue(bucket)
+			hasValues = hasValues || series.Values[i] != nil
+		}
+
+		// Series with a single sample have no rate
+		if hasValues {
+			result.Series = append(result.Series, series)
+		}
+	}
+
+	return result
+}
+
+// bucketValue applies the query aggregation to a bucket
+func (a *queryAccumulator) bucketValue(bucket *bucketAccumulator) *float64 {
+	if bucket.count == 0 {
+		return nil
+	}
+
+	var value float64
+	switch a.query.Aggregation {
+	case QueryAggregationAvg:
+		value = bucket.sum / float64(bucket.count)
+	case QueryAggregationMax:
+		value = bucket.max
+	case QueryAggregationMin:
+		value = bucket.min
+	case QueryAggregationSum:
+		value = bucket.sum
+	case QueryAggregationCount:
+		value = float64(bucket.count)
+	case QueryAggregationRate:
+		value = bucket.sum / float64(a.step)
+	case QueryAggregationPercentile:
+		value = percentile(bucket.values, a.query.Percentile)
+	}
+	return &value
+}
+
+// percentile returns the p-th percentile of values, interpolating between ranks
+func percentile(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return values[lower]
+	}
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+// sortedTagKeys returns the keys of a tag filter in order
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matchesTags reports whether a metric has all tags of a filter
+func matchesTags(metric *Metric, tags map[string]string) bool {
+	for key, value := range tags {
+		tag, exists := metric.Tags[key]
+		if !exists || formatLabelValue(tag) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// labelKey returns a stable key for a label set
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var key strings.Builder
+	for _, name := range keys {
+		key.WriteString(name + "\xff" + labels[name] + "\xff")
+	}
+	return key.String()
+}
+
+// metricSource identifies the source series of a metric by entity and tags
+func metricSource(metric *Metric) string {
+	tags := make(map[string]string, len(metric.Tags))
+	for key, value := range metric.Tags {
+		tags[key] = formatLabelValue(value)
+	}
+
+	source := ""
+	if metric.EntityID != nil {
+		source = strconv.FormatInt(*metric.EntityID, 10)
+	}
+	return source + "\xff" + labelKey(tags)
+}
+
+// metricGroupLabels returns the group-by labels of a metric
+func metricGroupLabels(groupBy []string, metric *Metric) map[string]string {
+	labels := make(map[string]string, len(groupBy))
+	for _, key := range groupBy {
+		if key == GroupByEntityID {
+			if metric.EntityID != nil {
+				labels[key] = strconv.FormatInt(*metric.EntityID, 10)
+			} else {
+				labels[key] = ""
+			}
+			continue
+		}
+		labels[key] = formatLabelValue(metric.Tags[key])
+	}
+	return labels
+}
+
+// formatLabelValue converts a tag value to a label value
+func formatLabelValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
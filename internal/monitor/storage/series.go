@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// canonicalTags encodes tags as compact JSON with sorted keys, which together
+// with the metric name and entity identifies a series
+func canonicalTags(tags JSON) (string, error) {
+	if len(tags) == 0 {
+		return "{}", nil
+	}
+
+	// encoding/json sorts map keys
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metric tags: %w", err)
+	}
+	return string(data), nil
+}
+
+// maxCachedSeries bounds the series ID cache, other series are looked up in the database
+const maxCachedSeries = 10000
+
+// seriesID returns the series of a metric, creating it on first use. Series
+// the transaction looks up or creates are added to pending, to be cached with
+// cacheSeries once it commits.
+func (s *SQLiteStorage) seriesID(tx *sql.Tx, metric *Metric, pending map[string]int64) (int64, error) {
+	tags, err := canonicalTags(metric.Tags)
+	if err != nil {
+		return 0, err
+	}
+
+	key := metric.MetricName + "\xff" + tags
+	if metric.EntityID != nil {
+		key += "\xff" + strconv.FormatInt(*metric.EntityID, 10)
+	}
+
+	if id, exists := pending[key]; exists {
+		return id, nil
+	}
+	s.seriesMu.Lock()
+	id, exists := s.seriesIDs[key]
+	s.seriesMu.Unlock()
+	if exists {
+		// The series may have been deleted as orphaned since it was cached,
+		// check it still exists within the transaction writing its samples
+		var found int
+		err := tx.QueryRow(`SELECT 1 FROM series WHERE id = ?`, id).Scan(&found)
+		if err == nil {
+			return id, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to get metric series: %w", err)
+		}
+		s.forgetSeries(key, id)
+	}
+
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO series (metric_name, entity_id, tags, created_at)
+		VALUES (?, ?, ?, ?)`,
+		metric.MetricName, metric.EntityID, tags, time.Now().Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create metric series: %w", err)
+	}
+
+	if created, _ := result.RowsAffected(); created > 0 {
+		if id, err = result.LastInsertId(); err != nil {
+			return 0, fmt.Errorf("failed to get series ID: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO series_tags (series_id, key, value)
+			SELECT ?, key, value FROM json_each(?)`, id, tags); err != nil {
+			return 0, fmt.Errorf("failed to index series tags: %w", err)
+		}
+	} else {
+		err := tx.QueryRow(`
+			SELECT id FROM series
+			WHERE metric_name = ? AND IFNULL(entity_id, 0) = IFNULL(?, 0) AND tags = ?`,
+			metric.MetricName, metric.EntityID, tags).Scan(&id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get metric series: %w", err)
+		}
+	}
+
+	pending[key] = id
+	return id, nil
+}
+
+// cacheSeries caches the series of a committed transaction, evicting
+// arbitrary series once the cache is full
+func (s *SQLiteStorage) cacheSeries(ids map[string]int64) {
+	s.seriesMu.Lock()
+	defer s.seriesMu.Unlock()
+
+	for key, id := range ids {
+		if _, exists := s.seriesIDs[key]; !exists && len(s.seriesIDs) >= maxCachedSeries {
+			for evicted := range s.seriesIDs {
+				delete(s.seriesIDs, evicted)
+				break
+			}
+		}
+		s.seriesIDs[key] = id
+	}
+}
+
+// forgetSeries drops a deleted series from the cache, unless it was replaced meanwhile
+func (s *SQLiteStorage) forgetSeries(key string, id int64) {
+	s.seriesMu.Lock()
+	if s.seriesIDs[key] == id {
+		delete(s.seriesIDs, key)
+	}
+	s.seriesMu.Unlock()
+}
+
+// resetSeriesCache forgets cached series IDs, used when series are deleted
+func (s *SQLiteStorage) resetSeriesCache() {
+	s.seriesMu.Lock()
+	s.seriesIDs = make(map[string]int64)
+	s.seriesMu.Unlock()
+}
+
+// seriesFilterSQL returns the conditions of a metric filter on the series table
+func seriesFilterSQL(filter *MetricFilter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	var conditions string
+	var args []interface{}
+	if filter.EntityID != nil {
+		conditions += ` AND series.entity_id = ?`
+		args = append(args, *filter.EntityID)
+	}
+	if filter.MetricName != nil {
+		conditions += ` AND series.metric_name = ?`
+		args = append(args, *filter.MetricName)
+	}
+	for _, key := range sortedTagKeys(filter.Tags) {
+		conditions += ` AND series.id IN (SELECT series_id FROM series_tags WHERE key = ? AND value = ?)`
+		args = append(args, key, filter.Tags[key])
+	}
+
+	return conditions, args
+}
+
+// metricFilterSQL returns the conditions of a metric filter on samples joined with their series
+func metricFilterSQL(filter *MetricFilter) (string, []interface{}) {
+	conditions, args := seriesFilterSQL(filter)
+	if filter == nil {
+		return conditions, args
+	}
+
+	if filter.AggregationLevel != nil {
+		conditions += ` AND samples.aggregation_level = ?`
+		args = append(args, *filter.AggregationLevel)
+	}
+	if filter.Since != nil {
+		conditions += ` AND samples.timestamp >= ?`
+		args = append(args, filter.Since.Unix())
+	}
+	if filter.Until != nil {
+		conditions += ` AND samples.timestamp <= ?`
+		args = append(args, filter.Until.Unix())
+	}
+
+	return conditions, args
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMetric scans a row selected with metricSelect. Rows of the same series
+// share their tags, so decoded tags are kept in tagCache when it is not nil.
+func scanMetric(row rowScanner, tagCache map[string]JSON) (*Metric, error) {
+	metric := &Metric{}
+	var tagsJSON []byte
+	var timestampUnix int64
+	var expiresAtUnix *int64
+
+	err := row.Scan(&metric.ID, &metric.EntityID, &timestampUnix, &metric.MetricName, &metric.Value,
+		&metric.AggregationLevel, &metric.SampleCount, &tagsJSON, &expiresAtUnix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Series without tags store an empty object
+	if len(tagsJSON) > 0 && string(tagsJSON) != "{}" {
+		if tags, cached := tagCache[string(tagsJSON)]; cached {
+			metric.Tags = copyJSON(tags)
+		} else {
+			if err := metric.Tags.Scan(tagsJSON); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal metric tags: %w", err)
+			}
+			if tagCache != nil {
+				tagCache[string(tagsJSON)] = copyJSON(metric.Tags)
+			}
+		}
+	}
+
+	metric.Timestamp = time.Unix(timestampUnix, 0)
+	if expiresAtUnix != nil {
+		expiresAt := time.Unix(*expiresAtUnix, 0)
+		metric.ExpiresAt = &expiresAt
+	}
+
+	return metric, nil
+}
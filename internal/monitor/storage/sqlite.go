@@ -3,11 +3,14 @@ package storage
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"crucible/internal/logging"
-	_ "github.com/mattn/go-sqlite3"
 )
 
 // SQLiteStorage implements persistent storage using SQLite
@@ -17,16 +20,23 @@ type SQLiteStorage struct {
 	logger     *logging.Logger
 	batchSize  int
 	batchItems []BatchItem
+
+	seriesMu  sync.Mutex
+	seriesIDs map[string]int64 // Series IDs by series key
+
+	lock *os.File // Shared database lock, held while open
 }
 
 // Config represents storage configuration
 type Config struct {
-	DatabasePath    string        `yaml:"database_path"`
-	RetentionDays   RetentionDays `yaml:"retention"`
-	BatchSize       int           `yaml:"batch_size"`
-	CleanupInterval time.Duration `yaml:"cleanup_interval"`
-	BackupEnabled   bool          `yaml:"backup_enabled"`
-	BackupInterval  time.Duration `yaml:"backup_interval"`
+	DatabasePath    string           `yaml:"database_path"`
+	Driver          string           `yaml:"driver"` // auto, cgo or purego
+	RetentionDays   RetentionDays    `yaml:"retention"`
+	BatchSize       int              `yaml:"batch_size"`
+	CleanupInterval time.Duration    `yaml:"cleanup_interval"`
+	BackupEnabled   bool             `yaml:"backup_enabled"`
+	BackupInterval  time.Duration    `yaml:"backup_interval"`
+	RetentionPolicy *RetentionPolicy `yaml:"retention_policy"` // Applied after each cleanup, nil for none
 }
 
 // RetentionDays defines data retention periods
@@ -59,24 +69,72 @@ func NewSQLiteStorage(config *Config, logger *logging.Logger) (*SQLiteStorage, e
 		}
 	}
 
-	db, err := sql.Open(sqliteDriverName, config.DatabasePath)
+	lock, err := lockDatabase(config.DatabasePath, false)
+	if errors.Is(err, ErrDatabaseLocked) {
+		return nil, fmt.Errorf("database is being migrated or rolled back by crucible-monitor db: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := openSQLiteStorage(config, logger)
+	if err != nil {
+		unlockDatabase(lock)
+		return nil, err
+	}
+	storage.lock = lock
+
+	if err := storage.initialize(); err != nil {
+		storage.db.Close()
+		unlockDatabase(lock)
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	return storage, nil
+}
+
+// openSQLiteStorage opens the database without changing its schema
+func openSQLiteStorage(config *Config, logger *logging.Logger) (*SQLiteStorage, error) {
+	driverName, err := sqliteDriverName(config.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(driverName, config.DatabasePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	storage := &SQLiteStorage{
+	return &SQLiteStorage{
 		db:         db,
 		config:     config,
 		logger:     logger,
 		batchSize:  config.BatchSize,
 		batchItems: make([]BatchItem, 0, config.BatchSize),
-	}
+		seriesIDs:  make(map[string]int64),
+	}, nil
+}
 
-	if err := storage.initialize(); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
+// sqliteDriverName returns the database/sql driver for a configured SQLite driver.
+// auto prefers the cgo driver and falls back to the pure-Go one when the binary
+// is built without cgo or with the purego tag.
+func sqliteDriverName(driver string) (string, error) {
+	switch driver {
+	case "", "auto":
+		if cgoDriverName != "" {
+			return cgoDriverName, nil
+		}
+		return pureGoDriverName, nil
+	case "cgo":
+		if cgoDriverName == "" {
+			return "", fmt.Errorf("cgo SQLite driver is not available in this build")
+		}
+		return cgoDriverName, nil
+	case "purego":
+		return pureGoDriverName, nil
+	default:
+		return "", fmt.Errorf("unknown SQLite driver: %s", driver)
 	}
-
-	return storage, nil
 }
 
 // initialize sets up the database schema and optimizations
@@ -100,8 +158,10 @@ func (s *SQLiteStorage) initialize() error {
 		}
 	}
 
-	// Run migrations instead of creating schema directly
+	// Run migrations instead of creating schema directly, backing up
+	// existing databases first
 	migrationManager := NewMigrationManager(s.db, s.logger)
+	migrationManager.SetBackupPath(s.config.DatabasePath)
 	if err := migrationManager.ApplyMigrations(); err != nil {
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
@@ -111,6 +171,11 @@ func (s *SQLiteStorage) initialize() error {
 		return fmt.Errorf("failed to initialize metadata: %w", err)
 	}
 
+	// The metadata row of a new database is created after its migrations
+	if err := migrationManager.ensureSchemaHash(); err != nil {
+		s.logger.Warn("Failed to record schema hash", "error", err)
+	}
+
 	return nil
 }
 
@@ -212,6 +277,7 @@ func (s *SQLiteStorage) Close() error {
 		// Log error but don't fail close
 		fmt.Printf("Warning: failed to flush batch during close: %v\n", err)
 	}
+	defer unlockDatabase(s.lock)
 	return s.db.Close()
 }
 
@@ -255,7 +321,7 @@ func (s *SQLiteStorage) GetDatabaseInfo() (*DatabaseInfo, error) {
 	if err := s.db.QueryRow("SELECT COUNT(*) FROM events").Scan(&info.EventCount); err != nil {
 		return nil, err
 	}
-	if err := s.db.QueryRow("SELECT COUNT(*) FROM metrics").Scan(&info.MetricCount); err != nil {
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM samples").Scan(&info.MetricCount); err != nil {
 		return nil, err
 	}
 
@@ -285,7 +351,7 @@ type DatabaseInfo struct {
 	DatabaseSize    int64      `json:"database_size_bytes"`
 }
 
-// Storage interface for the SQLite storage layer
+// Storage is implemented by the SQLite and in-memory backends
 type Storage interface {
 	// Entity operations
 	CreateEntity(entity *Entity) error
@@ -306,12 +372,24 @@ type Storage interface {
 	GetMetric(id int64) (*Metric, error)
 	ListMetrics(filter *MetricFilter) ([]*Metric, error)
 	GetMetricSummary(filter *MetricFilter) (*MetricSummary, error)
+	QueryMetrics(query *MetricQuery) (*MetricQueryResult, error)
 	DeleteMetric(id int64) error
 
 	// Batch operations
 	BatchWrite(items []BatchItem) error
 	FlushBatch() error
 
+	// Configuration history operations
+	CreateConfigVersion(version *ConfigVersion) error
+	GetConfigVersion(id int64) (*ConfigVersion, error)
+	ListConfigVersions(limit int) ([]*ConfigVersion, error)
+
+	// Incident operations
+	CreateIncident(incident *Incident) error
+	UpdateIncident(incident *Incident) error
+	GetIncident(id int64) (*Incident, error)
+	ListIncidents(filter *IncidentFilter) ([]*Incident, error)
+
 	// Maintenance operations
 	Cleanup() error
 	Vacuum() error
@@ -748,53 +826,36 @@ func (s *SQLiteStorage) DeleteEvent(id int64) error {
 
 // METRIC OPERATIONS
 
+// metricSelect selects samples joined with their series as metric rows
+const metricSelect = `
+	SELECT samples.id, series.entity_id, samples.timestamp, series.metric_name, samples.value,
+		samples.aggregation_level, samples.sample_count, series.tags, samples.expires_at
+	FROM samples JOIN series ON series.id = samples.series_id`
+
 // CreateMetric creates a new metric in the database
 func (s *SQLiteStorage) CreateMetric(metric *Metric) error {
-	query := `
-		INSERT INTO metrics (entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-
-	tagsJSON, err := metric.Tags.Value()
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to marshal metric tags: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	var expiresAtUnix *int64
-	if metric.ExpiresAt != nil {
-		unix := metric.ExpiresAt.Unix()
-		expiresAtUnix = &unix
+	pending := make(map[string]int64)
+	if err := s.createMetricInTx(tx, metric, pending); err != nil {
+		return err
 	}
 
-	result, err := s.db.Exec(query,
-		metric.EntityID, metric.Timestamp.Unix(), metric.MetricName, metric.Value,
-		metric.AggregationLevel, metric.SampleCount, tagsJSON, expiresAtUnix)
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to create metric: %w", err)
 	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get metric ID: %w", err)
-	}
-	metric.ID = id
+	s.cacheSeries(pending)
 
 	return nil
 }
 
 // GetMetric retrieves a metric by ID
 func (s *SQLiteStorage) GetMetric(id int64) (*Metric, error) {
-	query := `
-		SELECT id, entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at
-		FROM metrics WHERE id = ?`
-
-	metric := &Metric{}
-	var tagsJSON []byte
-	var timestampUnix int64
-	var expiresAtUnix *int64
-
-	err := s.db.QueryRow(query, id).Scan(
-		&metric.ID, &metric.EntityID, &timestampUnix, &metric.MetricName, &metric.Value,
-		&metric.AggregationLevel, &metric.SampleCount, &tagsJSON, &expiresAtUnix)
+	metric, err := scanMetric(s.db.QueryRow(metricSelect+` WHERE samples.id = ?`, id), nil)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("metric not found: %d", id)
@@ -802,53 +863,13 @@ func (s *SQLiteStorage) GetMetric(id int64) (*Metric, error) {
 		return nil, fmt.Errorf("failed to get metric: %w", err)
 	}
 
-	// Parse JSON tags
-	if len(tagsJSON) > 0 {
-		metric.Tags = make(JSON)
-		if err := metric.Tags.Scan(tagsJSON); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metric tags: %w", err)
-		}
-	}
-
-	// Convert timestamps
-	metric.Timestamp = time.Unix(timestampUnix, 0)
-	if expiresAtUnix != nil {
-		expiresAt := time.Unix(*expiresAtUnix, 0)
-		metric.ExpiresAt = &expiresAt
-	}
-
 	return metric, nil
 }
 
 // ListMetrics returns metrics based on filter criteria
 func (s *SQLiteStorage) ListMetrics(filter *MetricFilter) ([]*Metric, error) {
-	query := `SELECT id, entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at FROM metrics WHERE 1=1`
-	args := []interface{}{}
-
-	if filter != nil {
-		if filter.EntityID != nil {
-			query += ` AND entity_id = ?`
-			args = append(args, *filter.EntityID)
-		}
-		if filter.MetricName != nil {
-			query += ` AND metric_name = ?`
-			args = append(args, *filter.MetricName)
-		}
-		if filter.AggregationLevel != nil {
-			query += ` AND aggregation_level = ?`
-			args = append(args, *filter.AggregationLevel)
-		}
-		if filter.Since != nil {
-			query += ` AND timestamp >= ?`
-			args = append(args, filter.Since.Unix())
-		}
-		if filter.Until != nil {
-			query += ` AND timestamp <= ?`
-			args = append(args, filter.Until.Unix())
-		}
-	}
-
-	query += ` ORDER BY timestamp DESC`
+	conditions, args := metricFilterSQL(filter)
+	query := metricSelect + ` WHERE 1=1` + conditions + ` ORDER BY samples.timestamp DESC`
 
 	if filter != nil {
 		if filter.Limit != nil {
@@ -868,33 +889,12 @@ func (s *SQLiteStorage) ListMetrics(filter *MetricFilter) ([]*Metric, error) {
 	defer rows.Close()
 
 	var metrics []*Metric
+	tagCache := make(map[string]JSON)
 	for rows.Next() {
-		metric := &Metric{}
-		var tagsJSON []byte
-		var timestampUnix int64
-		var expiresAtUnix *int64
-
-		err := rows.Scan(&metric.ID, &metric.EntityID, &timestampUnix, &metric.MetricName, &metric.Value,
-			&metric.AggregationLevel, &metric.SampleCount, &tagsJSON, &expiresAtUnix)
+		metric, err := scanMetric(rows, tagCache)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan metric: %w", err)
 		}
-
-		// Parse JSON tags
-		if len(tagsJSON) > 0 {
-			metric.Tags = make(JSON)
-			if err := metric.Tags.Scan(tagsJSON); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal metric tags: %w", err)
-			}
-		}
-
-		// Convert timestamps
-		metric.Timestamp = time.Unix(timestampUnix, 0)
-		if expiresAtUnix != nil {
-			expiresAt := time.Unix(*expiresAtUnix, 0)
-			metric.ExpiresAt = &expiresAt
-		}
-
 		metrics = append(metrics, metric)
 	}
 
@@ -903,44 +903,20 @@ func (s *SQLiteStorage) ListMetrics(filter *MetricFilter) ([]*Metric, error) {
 
 // GetMetricSummary returns aggregated metric data
 func (s *SQLiteStorage) GetMetricSummary(filter *MetricFilter) (*MetricSummary, error) {
+	conditions, args := metricFilterSQL(filter)
 	query := `
-		SELECT 
-			entity_id,
-			metric_name,
+		SELECT
+			series.entity_id,
+			series.metric_name,
 			COUNT(*) as count,
-			AVG(value) as average,
-			MIN(value) as min,
-			MAX(value) as max,
-			value as latest,
-			timestamp
-		FROM metrics 
-		WHERE 1=1`
-	args := []interface{}{}
-
-	if filter != nil {
-		if filter.EntityID != nil {
-			query += ` AND entity_id = ?`
-			args = append(args, *filter.EntityID)
-		}
-		if filter.MetricName != nil {
-			query += ` AND metric_name = ?`
-			args = append(args, *filter.MetricName)
-		}
-		if filter.AggregationLevel != nil {
-			query += ` AND aggregation_level = ?`
-			args = append(args, *filter.AggregationLevel)
-		}
-		if filter.Since != nil {
-			query += ` AND timestamp >= ?`
-			args = append(args, filter.Since.Unix())
-		}
-		if filter.Until != nil {
-			query += ` AND timestamp <= ?`
-			args = append(args, filter.Until.Unix())
-		}
-	}
-
-	query += ` GROUP BY entity_id, metric_name ORDER BY timestamp DESC LIMIT 1`
+			AVG(samples.value) as average,
+			MIN(samples.value) as min,
+			MAX(samples.value) as max,
+			samples.value as latest,
+			samples.timestamp
+		FROM samples JOIN series ON series.id = samples.series_id
+		WHERE 1=1` + conditions + `
+		GROUP BY series.entity_id, series.metric_name ORDER BY samples.timestamp DESC LIMIT 1`
 
 	summary := &MetricSummary{}
 	var timestampUnix int64
@@ -959,9 +935,104 @@ func (s *SQLiteStorage) GetMetricSummary(filter *MetricFilter) (*MetricSummary,
 	return summary, nil
 }
 
+// QueryMetrics returns a metric downsampled to aligned steps. Average, min,
+// max and sum are aggregated per series in SQL and combined per group, rate
+// and percentile need the raw samples and are computed while streaming them.
+func (s *SQLiteStorage) QueryMetrics(query *MetricQuery) (*MetricQueryResult, error) {
+	if err := query.Normalize(); err != nil {
+		return nil, err
+	}
+
+	filter := &MetricFilter{
+		EntityID:         query.EntityID,
+		MetricName:       &query.MetricName,
+		AggregationLevel: query.AggregationLevel,
+		Tags:             query.Tags,
+	}
+
+	// Group labels are resolved once per series instead of once per sample
+	seriesLabels, err := s.querySeriesLabels(query, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, args := metricFilterSQL(filter)
+	where := ` FROM samples JOIN series ON series.id = samples.series_id
+		WHERE samples.timestamp >= ? AND samples.timestamp < ?` + conditions
+	args = append([]interface{}{query.Since.Unix(), query.Until.Unix()}, args...)
+
+	inSQL := sqlAggregations[query.Aggregation]
+	var sqlQuery string
+	if inSQL {
+		step := int64(query.Step / time.Second)
+		sqlQuery = `SELECT samples.series_id, ? + (samples.timestamp - ?) / ? * ?,
+			COUNT(*), SUM(samples.value), MIN(samples.value), MAX(samples.value)` + where + ` GROUP BY 1, 2`
+		args = append([]interface{}{query.Since.Unix(), query.Since.Unix(), step, step}, args...)
+	} else {
+		sqlQuery = `SELECT samples.series_id, samples.timestamp, samples.value` + where + ` ORDER BY samples.timestamp ASC`
+	}
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	accumulator := newQueryAccumulator(query)
+	for rows.Next() {
+		var seriesID, timestamp int64
+		if inSQL {
+			var count int
+			var sum, min, max float64
+			if err := rows.Scan(&seriesID, &timestamp, &count, &sum, &min, &max); err != nil {
+				return nil, fmt.Errorf("failed to scan metric: %w", err)
+			}
+			accumulator.merge(seriesLabels[seriesID], timestamp, count, sum, min, max)
+			continue
+		}
+
+		var value float64
+		if err := rows.Scan(&seriesID, &timestamp, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan metric: %w", err)
+		}
+		accumulator.add(seriesLabels[seriesID], strconv.FormatInt(seriesID, 10), timestamp, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+
+	return accumulator.result(), nil
+}
+
+// querySeriesLabels returns the group-by labels of each series matching a query
+func (s *SQLiteStorage) querySeriesLabels(query *MetricQuery, filter *MetricFilter) (map[int64]map[string]string, error) {
+	conditions, args := seriesFilterSQL(filter)
+	rows, err := s.db.Query(`SELECT series.id, series.entity_id, series.tags FROM series WHERE 1=1`+conditions, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric series: %w", err)
+	}
+	defer rows.Close()
+
+	labels := make(map[int64]map[string]string)
+	for rows.Next() {
+		var id int64
+		var tagsJSON []byte
+		metric := &Metric{}
+		if err := rows.Scan(&id, &metric.EntityID, &tagsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan metric series: %w", err)
+		}
+		if err := metric.Tags.Scan(tagsJSON); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metric tags: %w", err)
+		}
+		labels[id] = metricGroupLabels(query.GroupBy, metric)
+	}
+
+	return labels, rows.Err()
+}
+
 // DeleteMetric removes a metric from the database
 func (s *SQLiteStorage) DeleteMetric(id int64) error {
-	query := `DELETE FROM metrics WHERE id = ?`
+	query := `DELETE FROM samples WHERE id = ?`
 
 	result, err := s.db.Exec(query, id)
 	if err != nil {
@@ -993,6 +1064,9 @@ func (s *SQLiteStorage) BatchWrite(items []BatchItem) error {
 	}
 	defer tx.Rollback()
 
+	// Series are only cached once they surely exist
+	pending := make(map[string]int64)
+
 	for _, item := range items {
 		switch item.Type {
 		case "entity":
@@ -1016,7 +1090,7 @@ func (s *SQLiteStorage) BatchWrite(items []BatchItem) error {
 			if !ok {
 				return fmt.Errorf("invalid metric data type")
 			}
-			if err := s.createMetricInTx(tx, metric); err != nil {
+			if err := s.createMetricInTx(tx, metric, pending); err != nil {
 				return err
 			}
 		default:
@@ -1024,7 +1098,12 @@ func (s *SQLiteStorage) BatchWrite(items []BatchItem) error {
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.cacheSeries(pending)
+
+	return nil
 }
 
 // FlushBatch flushes any pending batch items
@@ -1105,14 +1184,14 @@ func (s *SQLiteStorage) createEventInTx(tx *sql.Tx, event *Event) error {
 	return nil
 }
 
-func (s *SQLiteStorage) createMetricInTx(tx *sql.Tx, metric *Metric) error {
+func (s *SQLiteStorage) createMetricInTx(tx *sql.Tx, metric *Metric, pending map[string]int64) error {
 	query := `
-		INSERT INTO metrics (entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		INSERT INTO samples (series_id, timestamp, value, aggregation_level, sample_count, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
 
-	tagsJSON, err := metric.Tags.Value()
+	seriesID, err := s.seriesID(tx, metric, pending)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metric tags: %w", err)
+		return err
 	}
 
 	var expiresAtUnix *int64
@@ -1122,8 +1201,7 @@ func (s *SQLiteStorage) createMetricInTx(tx *sql.Tx, metric *Metric) error {
 	}
 
 	result, err := tx.Exec(query,
-		metric.EntityID, metric.Timestamp.Unix(), metric.MetricName, metric.Value,
-		metric.AggregationLevel, metric.SampleCount, tagsJSON, expiresAtUnix)
+		seriesID, metric.Timestamp.Unix(), metric.Value, metric.AggregationLevel, metric.SampleCount, expiresAtUnix)
 	if err != nil {
 		return fmt.Errorf("failed to create metric in transaction: %w", err)
 	}
@@ -1150,7 +1228,7 @@ func (s *SQLiteStorage) Cleanup() error {
 	}
 
 	// Clean up expired metrics
-	_, err = s.db.Exec(`DELETE FROM metrics WHERE expires_at IS NOT NULL AND expires_at < ?`, now.Unix())
+	_, err = s.db.Exec(`DELETE FROM samples WHERE expires_at IS NOT NULL AND expires_at < ?`, now.Unix())
 	if err != nil {
 		return fmt.Errorf("failed to clean up expired metrics: %w", err)
 	}
@@ -1167,18 +1245,24 @@ func (s *SQLiteStorage) Cleanup() error {
 
 	// Clean old raw metrics
 	metricCutoff := now.AddDate(0, 0, -retentionDays.MetricsDays)
-	_, err = s.db.Exec(`DELETE FROM metrics WHERE expires_at IS NULL AND aggregation_level = 'raw' AND timestamp < ?`, metricCutoff.Unix())
+	_, err = s.db.Exec(`DELETE FROM samples WHERE expires_at IS NULL AND aggregation_level = 'raw' AND timestamp < ?`, metricCutoff.Unix())
 	if err != nil {
 		return fmt.Errorf("failed to clean up old raw metrics: %w", err)
 	}
 
 	// Clean old aggregated metrics
 	aggregateCutoff := now.AddDate(0, 0, -retentionDays.AggregatesDays)
-	_, err = s.db.Exec(`DELETE FROM metrics WHERE expires_at IS NULL AND aggregation_level != 'raw' AND timestamp < ?`, aggregateCutoff.Unix())
+	_, err = s.db.Exec(`DELETE FROM samples WHERE expires_at IS NULL AND aggregation_level != 'raw' AND timestamp < ?`, aggregateCutoff.Unix())
 	if err != nil {
 		return fmt.Errorf("failed to clean up old aggregated metrics: %w", err)
 	}
 
+	// Clean series left without samples, such as those of removed checks or
+	// renamed tags, unless created since the raw metric cutoff
+	if err := s.cleanupOrphanedSeries(metricCutoff); err != nil {
+		return err
+	}
+
 	// Update cleanup timestamp
 	_, err = s.db.Exec(`UPDATE metadata SET last_cleanup_timestamp = ?, updated_at = ? WHERE id = 1`,
 		now.Unix(), now.Unix())
@@ -1189,6 +1273,36 @@ func (s *SQLiteStorage) Cleanup() error {
 	return nil
 }
 
+// cleanupOrphanedSeries deletes series created before cutoff that have no
+// samples left, along with their tags
+func (s *SQLiteStorage) cleanupOrphanedSeries(cutoff time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM series
+		WHERE created_at < ? AND NOT EXISTS (SELECT 1 FROM samples WHERE samples.series_id = series.id)`,
+		cutoff.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to clean up orphaned series: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM series_tags WHERE series_id NOT IN (SELECT id FROM series)`); err != nil {
+		return fmt.Errorf("failed to clean up orphaned series tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to clean up orphaned series: %w", err)
+	}
+	// Writers holding a cached ID of a deleted series recreate it, see seriesID
+	if deleted, _ := result.RowsAffected(); deleted > 0 {
+		s.resetSeriesCache()
+	}
+	return nil
+}
+
 // Vacuum optimizes the database by reclaiming space
 func (s *SQLiteStorage) Vacuum() error {
 	_, err := s.db.Exec("VACUUM")
@@ -1267,13 +1381,21 @@ func (s *SQLiteStorage) GetSystemHealth() (*SystemHealth, error) {
 	metricNames := []string{"cpu_usage", "memory_usage", "disk_usage_root", "load_1"}
 	for _, metricName := range metricNames {
 		summary := &MetricSummary{MetricName: metricName}
+		var latestUnix *int64
 		err := s.db.QueryRow(`
-			SELECT COUNT(*), AVG(value), MIN(value), MAX(value), 
-				   (SELECT value FROM metrics WHERE metric_name = ? ORDER BY timestamp DESC LIMIT 1) as latest,
-				   (SELECT timestamp FROM metrics WHERE metric_name = ? ORDER BY timestamp DESC LIMIT 1) as latest_ts
-			FROM metrics WHERE metric_name = ? AND timestamp >= ?
-		`, metricName, metricName, metricName, dayAgo).Scan(
-			&summary.Count, &summary.Average, &summary.Min, &summary.Max, &summary.Latest, &summary.Timestamp)
+			WITH named AS (
+				SELECT samples.value, samples.timestamp FROM samples
+				WHERE samples.series_id IN (SELECT id FROM series WHERE metric_name = ?)
+			)
+			SELECT COUNT(*), IFNULL(AVG(value), 0), IFNULL(MIN(value), 0), IFNULL(MAX(value), 0),
+				   IFNULL((SELECT value FROM named ORDER BY timestamp DESC LIMIT 1), 0) as latest,
+				   (SELECT timestamp FROM named ORDER BY timestamp DESC LIMIT 1) as latest_ts
+			FROM named WHERE timestamp >= ?
+		`, metricName, dayAgo).Scan(
+			&summary.Count, &summary.Average, &summary.Min, &summary.Max, &summary.Latest, &latestUnix)
+		if latestUnix != nil {
+			summary.Timestamp = time.Unix(*latestUnix, 0)
+		}
 
 		if err != nil && err != sql.ErrNoRows {
 			return nil, fmt.Errorf("failed to get metric summary for %s: %w", metricName, err)
@@ -1286,3 +1408,246 @@ func (s *SQLiteStorage) GetSystemHealth() (*SystemHealth, error) {
 
 	return health, nil
 }
+
+// CONFIG VERSION OPERATIONS
+
+// CreateConfigVersion stores a revision of the configuration files
+func (s *SQLiteStorage) CreateConfigVersion(version *ConfigVersion) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO config_versions (created_at, description, monitor_config, alerts_config)
+		VALUES (?, ?, ?, ?)`,
+		version.CreatedAt.Unix(), version.Description, version.MonitorConfig, version.AlertsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create config version: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get config version ID: %w", err)
+	}
+	version.ID = id
+
+	return nil
+}
+
+// GetConfigVersion returns a configuration revision including file contents
+func (s *SQLiteStorage) GetConfigVersion(id int64) (*ConfigVersion, error) {
+	version := &ConfigVersion{}
+	var createdAtUnix int64
+	var description sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, created_at, description, monitor_config, alerts_config
+		FROM config_versions WHERE id = ?`, id).Scan(
+		&version.ID, &createdAtUnix, &description, &version.MonitorConfig, &version.AlertsConfig)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("config version not found: %d", id)
+		}
+		return nil, fmt.Errorf("failed to get config version: %w", err)
+	}
+
+	version.CreatedAt = time.Unix(createdAtUnix, 0)
+	version.Description = description.String
+
+	return version, nil
+}
+
+// ListConfigVersions returns the most recent configuration revisions without file contents
+func (s *SQLiteStorage) ListConfigVersions(limit int) ([]*ConfigVersion, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, created_at, description
+		FROM config_versions ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query config versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*ConfigVersion
+	for rows.Next() {
+		version := &ConfigVersion{}
+		var createdAtUnix int64
+		var description sql.NullString
+
+		if err := rows.Scan(&version.ID, &createdAtUnix, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan config version: %w", err)
+		}
+		version.CreatedAt = time.Unix(createdAtUnix, 0)
+		version.Description = description.String
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+// INCIDENT OPERATIONS
+
+// CreateIncident stores a newly opened incident
+func (s *SQLiteStorage) CreateIncident(incident *Incident) error {
+	detailsJSON, err := incident.Details.Value()
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident details: %w", err)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO incidents (rule_id, title, severity, status, message, details, opened_at, resolved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		incident.RuleID, incident.Title, incident.Severity, incident.Status, incident.Message,
+		detailsJSON, incident.OpenedAt.Unix(), unixOrNil(incident.ResolvedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create incident: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get incident ID: %w", err)
+	}
+	incident.ID = id
+
+	return nil
+}
+
+// UpdateIncident updates the status, message and details of an incident
+func (s *SQLiteStorage) UpdateIncident(incident *Incident) error {
+	detailsJSON, err := incident.Details.Value()
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident details: %w", err)
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE incidents SET severity = ?, status = ?, message = ?, details = ?, resolved_at = ?
+		WHERE id = ?`,
+		incident.Severity, incident.Status, incident.Message, detailsJSON,
+		unixOrNil(incident.ResolvedAt), incident.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update incident: %w", err)
+	}
+
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("incident not found: %d", incident.ID)
+	}
+
+	return nil
+}
+
+// GetIncident returns an incident by ID
+func (s *SQLiteStorage) GetIncident(id int64) (*Incident, error) {
+	row := s.db.QueryRow(`
+		SELECT id, rule_id, title, severity, status, message, details, opened_at, resolved_at
+		FROM incidents WHERE id = ?`, id)
+
+	incident, err := scanIncident(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("incident not found: %d", id)
+		}
+		return nil, fmt.Errorf("failed to get incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// ListIncidents returns incidents based on filter criteria, most recently opened first
+func (s *SQLiteStorage) ListIncidents(filter *IncidentFilter) ([]*Incident, error) {
+	query := `SELECT id, rule_id, title, severity, status, message, details, opened_at, resolved_at FROM incidents WHERE 1=1`
+	args := []interface{}{}
+
+	if filter != nil {
+		if filter.Status != nil {
+			query += ` AND status = ?`
+			args = append(args, *filter.Status)
+		}
+		if filter.RuleID != nil {
+			query += ` AND rule_id = ?`
+			args = append(args, *filter.RuleID)
+		}
+		if filter.Since != nil {
+			query += ` AND opened_at >= ?`
+			args = append(args, filter.Since.Unix())
+		}
+		if filter.Until != nil {
+			query += ` AND opened_at <= ?`
+			args = append(args, filter.Until.Unix())
+		}
+	}
+
+	query += ` ORDER BY opened_at DESC, id DESC`
+
+	if filter != nil {
+		if filter.Limit != nil {
+			query += ` LIMIT ?`
+			args = append(args, *filter.Limit)
+		}
+		if filter.Offset != nil {
+			if filter.Limit == nil {
+				query += ` LIMIT -1`
+			}
+			query += ` OFFSET ?`
+			args = append(args, *filter.Offset)
+		}
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var incidents []*Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, rows.Err()
+}
+
+// scanIncident reads an incident from a row of the incidents table
+func scanIncident(row rowScanner) (*Incident, error) {
+	incident := &Incident{}
+	var message sql.NullString
+	var detailsJSON []byte
+	var openedAtUnix int64
+	var resolvedAtUnix *int64
+
+	if err := row.Scan(&incident.ID, &incident.RuleID, &incident.Title, &incident.Severity, &incident.Status,
+		&message, &detailsJSON, &openedAtUnix, &resolvedAtUnix); err != nil {
+		return nil, err
+	}
+
+	incident.Message = message.String
+	incident.Details = make(JSON)
+	if len(detailsJSON) > 0 {
+		if err := incident.Details.Scan(detailsJSON); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal incident details: %w", err)
+		}
+	}
+	incident.OpenedAt = time.Unix(openedAtUnix, 0)
+	if resolvedAtUnix != nil {
+		resolvedAt := time.Unix(*resolvedAtUnix, 0)
+		incident.ResolvedAt = &resolvedAt
+	}
+
+	return incident, nil
+}
+
+// unixOrNil converts an optional time to Unix seconds
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	unix := t.Unix()
+	return &unix
+}
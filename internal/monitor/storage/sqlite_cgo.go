@@ -1,4 +1,4 @@
-//go:build cgo
+//go:build cgo && !purego
 
 package storage
 
@@ -6,4 +6,5 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-const sqliteDriverName = "sqlite3"
+// cgoDriverName is the database/sql name of the cgo SQLite driver
+const cgoDriverName = "sqlite3"
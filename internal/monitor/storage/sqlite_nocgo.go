@@ -1,9 +1,6 @@
-//go:build !cgo
+//go:build !cgo || purego
 
 package storage
 
-import (
-	_ "modernc.org/sqlite"
-)
-
-const sqliteDriverName = "sqlite"
+// cgoDriverName is empty when the binary is built without the cgo SQLite driver
+const cgoDriverName = ""
@@ -0,0 +1,9 @@
+package storage
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// pureGoDriverName is the database/sql name of the pure-Go SQLite driver,
+// which is always available so cgo-free and cross-compiled builds work
+const pureGoDriverName = "sqlite"
@@ -0,0 +1,250 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crucible/internal/logging"
+)
+
+// benchmarkSamples is how many samples query benchmarks run against
+const benchmarkSamples = 50000
+
+// benchmarkSeries returns the metric series written every benchmark interval:
+// per-mount disk usage, per-interface traffic and untagged CPU usage
+func benchmarkSeries() []*Metric {
+	var series []*Metric
+	for i := 0; i < 10; i++ {
+		series = append(series, &Metric{MetricName: "disk_usage_percent", Tags: JSON{"mount_point": fmt.Sprintf("/mnt/disk%d", i), "device": fmt.Sprintf("/dev/sd%c1", 'a'+i)}})
+	}
+	for i := 0; i < 5; i++ {
+		series = append(series, &Metric{MetricName: "network_rx_bytes", Tags: JSON{"interface": fmt.Sprintf("eth%d", i)}})
+	}
+	series = append(series, &Metric{MetricName: "cpu_usage"})
+	return series
+}
+
+// benchmarkMetrics returns samples of the benchmark series as if collected
+// every 10s until now, along with the time of the first one
+func benchmarkMetrics(samples int) ([]*Metric, time.Time) {
+	series := benchmarkSeries()
+	start := time.Now().Add(-time.Duration(samples/len(series)) * 10 * time.Second)
+	metrics := make([]*Metric, 0, samples)
+	for i := 0; len(metrics) < samples; i++ {
+		for j, s := range series {
+			if len(metrics) == samples {
+				break
+			}
+			metrics = append(metrics, &Metric{
+				Timestamp:        start.Add(time.Duration(i) * 10 * time.Second),
+				MetricName:       s.MetricName,
+				Value:            float64((i*7 + j*13) % 100),
+				AggregationLevel: AggregationLevelRaw,
+				SampleCount:      1,
+				Tags:             s.Tags,
+			})
+		}
+	}
+	return metrics, start
+}
+
+// newBenchmarkStorage opens a series storage in a temporary directory
+func newBenchmarkStorage(b *testing.B) *SQLiteStorage {
+	dir := b.TempDir()
+	logger, err := logging.NewLogger(filepath.Join(dir, "bench.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	storage, err := NewSQLiteStorage(&Config{DatabasePath: filepath.Join(dir, "series.db"), BatchSize: 100}, logger)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+// newLegacyDatabase opens a database with the single metrics table and JSON
+// tags metrics were stored in before series, tuned like SQLiteStorage
+func newLegacyDatabase(b *testing.B) *sql.DB {
+	driverName, err := sqliteDriverName("")
+	if err != nil {
+		b.Fatal(err)
+	}
+	db, err := sql.Open(driverName, filepath.Join(b.TempDir(), "legacy.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	// The initial schema holds the metrics table and its indexes
+	for _, statement := range []string{"PRAGMA journal_mode=WAL", "PRAGMA synchronous=NORMAL", GetAllMigrations()[0].UpSQL} {
+		if _, err := db.Exec(statement); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return db
+}
+
+// writeSeries writes metrics through the storage in batches of 100
+func writeSeries(b *testing.B, storage *SQLiteStorage, metrics []*Metric) {
+	for offset := 0; offset < len(metrics); offset += 100 {
+		end := min(offset+100, len(metrics))
+		items := make([]BatchItem, 0, end-offset)
+		for _, metric := range metrics[offset:end] {
+			items = append(items, BatchItem{Type: "metric", Data: metric})
+		}
+		if err := storage.BatchWrite(items); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// writeLegacy writes metrics to the legacy metrics table in batches of 100
+func writeLegacy(b *testing.B, db *sql.DB, metrics []*Metric) {
+	for offset := 0; offset < len(metrics); offset += 100 {
+		end := min(offset+100, len(metrics))
+		tx, err := db.Begin()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, metric := range metrics[offset:end] {
+			tags, _ := metric.Tags.Value()
+			if _, err := tx.Exec(`
+				INSERT INTO metrics (entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				metric.EntityID, metric.Timestamp.Unix(), metric.MetricName, metric.Value,
+				metric.AggregationLevel, metric.SampleCount, tags); err != nil {
+				tx.Rollback()
+				b.Fatal(err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkIngestMetrics writes batches of samples, into series and into the
+// legacy metrics table
+func BenchmarkIngestMetrics(b *testing.B) {
+	metrics, _ := benchmarkMetrics(100)
+
+	b.Run("series", func(b *testing.B) {
+		storage := newBenchmarkStorage(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			writeSeries(b, storage, metrics)
+		}
+	})
+
+	b.Run("legacy", func(b *testing.B) {
+		db := newLegacyDatabase(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			writeLegacy(b, db, metrics)
+		}
+	})
+}
+
+// BenchmarkListMetricsByTag lists the samples of one disk, filtered on a tag
+func BenchmarkListMetricsByTag(b *testing.B) {
+	metrics, _ := benchmarkMetrics(benchmarkSamples)
+	metricName := "disk_usage_percent"
+
+	b.Run("series", func(b *testing.B) {
+		storage := newBenchmarkStorage(b)
+		writeSeries(b, storage, metrics)
+		filter := &MetricFilter{MetricName: &metricName, Tags: map[string]string{"mount_point": "/mnt/disk3"}}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := storage.ListMetrics(filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("legacy", func(b *testing.B) {
+		db := newLegacyDatabase(b)
+		writeLegacy(b, db, metrics)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rows, err := db.Query(`
+				SELECT id, entity_id, timestamp, metric_name, value, aggregation_level, sample_count, tags, expires_at
+				FROM metrics WHERE metric_name = ? AND json_extract(tags, '$.mount_point') = ?
+				ORDER BY timestamp DESC`, metricName, "/mnt/disk3")
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			// Decode rows the way ListMetrics did before series existed
+			for rows.Next() {
+				if _, err := scanMetric(rows, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := rows.Err(); err != nil {
+				b.Fatal(err)
+			}
+			rows.Close()
+		}
+	})
+}
+
+// BenchmarkQueryMetricsGrouped averages disk usage per hour and mount point
+func BenchmarkQueryMetricsGrouped(b *testing.B) {
+	metrics, start := benchmarkMetrics(benchmarkSamples)
+	metricName := "disk_usage_percent"
+
+	b.Run("series", func(b *testing.B) {
+		storage := newBenchmarkStorage(b)
+		writeSeries(b, storage, metrics)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := storage.QueryMetrics(&MetricQuery{
+				MetricName: metricName,
+				Since:      start,
+				Until:      time.Now(),
+				Step:       time.Hour,
+				GroupBy:    []string{"mount_point"},
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("legacy", func(b *testing.B) {
+		db := newLegacyDatabase(b)
+		writeLegacy(b, db, metrics)
+		since := start.Unix() / 3600 * 3600
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rows, err := db.Query(`
+				SELECT (timestamp - ?) / 3600, json_extract(tags, '$.mount_point'), AVG(value)
+				FROM metrics WHERE metric_name = ? AND timestamp >= ?
+				GROUP BY 1, 2`, since, metricName, since)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for rows.Next() {
+				var bucket int64
+				var mountPoint string
+				var value float64
+				if err := rows.Scan(&bucket, &mountPoint, &value); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := rows.Err(); err != nil {
+				b.Fatal(err)
+			}
+			rows.Close()
+		}
+	})
+}
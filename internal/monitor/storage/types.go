@@ -70,6 +70,29 @@ type Metric struct {
 	ExpiresAt        *time.Time `json:"expires_at,omitempty" db:"expires_at"`
 }
 
+// Incident represents an alert from firing to resolution. Its timeline is
+// made of the events stored around that time.
+type Incident struct {
+	ID         int64      `json:"id" db:"id"`
+	RuleID     string     `json:"rule_id" db:"rule_id"`
+	Title      string     `json:"title" db:"title"`
+	Severity   string     `json:"severity" db:"severity"`
+	Status     string     `json:"status" db:"status"`
+	Message    string     `json:"message" db:"message"` // Alert message when it fired
+	Details    JSON       `json:"details" db:"details"`
+	OpenedAt   time.Time  `json:"opened_at" db:"opened_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// ConfigVersion represents a saved revision of the monitor and alert configuration files
+type ConfigVersion struct {
+	ID            int64     `json:"id" db:"id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	Description   string    `json:"description" db:"description"`
+	MonitorConfig string    `json:"monitor_config,omitempty" db:"monitor_config"`
+	AlertsConfig  string    `json:"alerts_config,omitempty" db:"alerts_config"`
+}
+
 // EntityType constants
 const (
 	EntityTypeSite    = "site"
@@ -103,6 +126,15 @@ const (
 	EventTypeInfo        = "info"
 	EventTypeAlert       = "alert"
 	EventTypeMaintenance = "maintenance"
+	EventTypeEviction    = "eviction"
+	EventTypeAnomaly     = "anomaly"
+	EventTypeConfig      = "config"
+)
+
+// IncidentStatus constants
+const (
+	IncidentStatusOpen     = "open"
+	IncidentStatusResolved = "resolved"
 )
 
 // EventSeverity constants
@@ -141,15 +173,26 @@ type EventFilter struct {
 	Offset   *int       `json:"offset,omitempty"`
 }
 
+// IncidentFilter represents filters for querying incidents
+type IncidentFilter struct {
+	Status *string    `json:"status,omitempty"`
+	RuleID *string    `json:"rule_id,omitempty"`
+	Since  *time.Time `json:"since,omitempty"` // Opened at or after
+	Until  *time.Time `json:"until,omitempty"` // Opened at or before
+	Limit  *int       `json:"limit,omitempty"`
+	Offset *int       `json:"offset,omitempty"`
+}
+
 // MetricFilter represents filters for querying metrics
 type MetricFilter struct {
-	EntityID         *int64     `json:"entity_id,omitempty"`
-	MetricName       *string    `json:"metric_name,omitempty"`
-	AggregationLevel *string    `json:"aggregation_level,omitempty"`
-	Since            *time.Time `json:"since,omitempty"`
-	Until            *time.Time `json:"until,omitempty"`
-	Limit            *int       `json:"limit,omitempty"`
-	Offset           *int       `json:"offset,omitempty"`
+	EntityID         *int64            `json:"entity_id,omitempty"`
+	MetricName       *string           `json:"metric_name,omitempty"`
+	AggregationLevel *string           `json:"aggregation_level,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	Since            *time.Time        `json:"since,omitempty"`
+	Until            *time.Time        `json:"until,omitempty"`
+	Limit            *int              `json:"limit,omitempty"`
+	Offset           *int              `json:"offset,omitempty"`
 }
 
 // MetricSummary represents aggregated metric data
@@ -164,6 +207,35 @@ type MetricSummary struct {
 	Timestamp  time.Time `json:"timestamp"`
 }
 
+// MetricQuery represents a downsampled query over stored metrics
+type MetricQuery struct {
+	MetricName       string            `json:"metric_name"`
+	EntityID         *int64            `json:"entity_id,omitempty"`
+	AggregationLevel *string           `json:"aggregation_level,omitempty"`
+	Tags             map[string]string `json:"tags,omitempty"`
+	Since            time.Time         `json:"since"`
+	Until            time.Time         `json:"until"`
+	Step             time.Duration     `json:"-"`
+	Aggregation      string            `json:"aggregation"`
+	Percentile       float64           `json:"percentile,omitempty"`
+	GroupBy          []string          `json:"group_by,omitempty"`
+}
+
+// MetricSeries represents one series of a metric query, with a value per
+// step and nil where the step has no samples
+type MetricSeries struct {
+	Labels map[string]string `json:"labels"`
+	Values []*float64        `json:"values"`
+}
+
+// MetricQueryResult represents metric series aligned on shared timestamps
+type MetricQueryResult struct {
+	Query       *MetricQuery    `json:"query"`
+	StepSeconds int64           `json:"step_seconds"`
+	Timestamps  []time.Time     `json:"timestamps"`
+	Series      []*MetricSeries `json:"series"`
+}
+
 // SystemHealth represents overall system health metrics
 type SystemHealth struct {
 	TotalEntities  int64           `json:"total_entities"`
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"os"
 	"time"
 )
 
@@ -8,14 +9,18 @@ import (
 type MetricType string
 
 const (
-	MetricTypeCPU     MetricType = "cpu"
-	MetricTypeMemory  MetricType = "memory"
-	MetricTypeDisk    MetricType = "disk"
-	MetricTypeNetwork MetricType = "network"
-	MetricTypeLoad    MetricType = "load"
-	MetricTypeService MetricType = "service"
-	MetricTypeHTTP    MetricType = "http"
-	MetricTypeCustom  MetricType = "custom"
+	MetricTypeCPU        MetricType = "cpu"
+	MetricTypeMemory     MetricType = "memory"
+	MetricTypeDisk       MetricType = "disk"
+	MetricTypeNetwork    MetricType = "network"
+	MetricTypeLoad       MetricType = "load"
+	MetricTypeService    MetricType = "service"
+	MetricTypeHTTP       MetricType = "http"
+	MetricTypePM2        MetricType = "pm2"
+	MetricTypeSupervisor MetricType = "supervisor"
+	MetricTypeLog        MetricType = "log"
+	MetricTypeAgent      MetricType = "agent"
+	MetricTypeCustom     MetricType = "custom"
 )
 
 // Metric represents a single metric data point
@@ -52,6 +57,80 @@ type HTTPCheckResult struct {
 	Timestamp     time.Time     `json:"timestamp"`
 	ContentLength int64         `json:"content_length,omitempty"`
 	SSLExpiry     *time.Time    `json:"ssl_expiry,omitempty"`
+
+	Steps      []HTTPStepResult `json:"steps,omitempty"`       // Synthetic transaction steps that ran
+	FailedStep string           `json:"failed_step,omitempty"` // Name of the step that failed the run
+}
+
+// HTTPStepResult represents one step of a synthetic transaction check run
+type HTTPStepResult struct {
+	Name         string        `json:"name"`
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	StatusCode   int           `json:"status_code"`
+	ResponseTime time.Duration `json:"response_time"`
+	Success      bool          `json:"success"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// ProbeResult represents the result of a TCP, DNS, ICMP or TLS probe
+type ProbeResult struct {
+	Name         string        `json:"name"`
+	Type         string        `json:"type"`
+	Target       string        `json:"target"`
+	Success      bool          `json:"success"`
+	ResponseTime time.Duration `json:"response_time"` // Connect, lookup, handshake or average round-trip time
+	Error        string        `json:"error,omitempty"`
+	PacketLoss   float64       `json:"packet_loss,omitempty"` // ICMP packet loss percent
+	Records      []string      `json:"records,omitempty"`     // DNS records returned
+	TLSVersion   string        `json:"tls_version,omitempty"`
+	CertExpiry   *time.Time    `json:"cert_expiry,omitempty"`
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// PM2AppStatus represents the aggregated state of a PM2-managed application
+type PM2AppStatus struct {
+	Name             string        `json:"name"`
+	Status           string        `json:"status"` // online, stopping, stopped, launching, errored
+	ExecMode         string        `json:"exec_mode"`
+	Instances        int           `json:"instances"`
+	OnlineInstances  int           `json:"online_instances"`
+	Restarts         int           `json:"restarts"`
+	UnstableRestarts int           `json:"unstable_restarts"`
+	RecentRestarts   int           `json:"recent_restarts"` // Restarts observed within the crash loop window
+	CPUPercent       float64       `json:"cpu_percent"`
+	MemoryBytes      uint64        `json:"memory_bytes"`
+	Uptime           time.Duration `json:"uptime"`
+	PIDs             []int         `json:"pids"`
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// SupervisorProcessStatus represents the state of a process managed by supervisord
+type SupervisorProcessStatus struct {
+	Name        string        `json:"name"`      // Process name, e.g. laravel-worker-blog_00
+	Group       string        `json:"group"`     // Program group, e.g. laravel-worker-blog
+	FullName    string        `json:"full_name"` // group:name as understood by supervisorctl
+	State       string        `json:"state"`     // STOPPED, STARTING, RUNNING, BACKOFF, STOPPING, EXITED, FATAL, UNKNOWN
+	StateCode   int           `json:"state_code"`
+	Description string        `json:"description"`
+	PID         int           `json:"pid"`
+	Uptime      time.Duration `json:"uptime"`
+	ExitStatus  int           `json:"exit_status"`
+	SpawnError  string        `json:"spawn_error,omitempty"`
+	Restarts    int           `json:"restarts"`       // Starts observed since the collector started
+	Site        string        `json:"site,omitempty"` // Owning Laravel site for queue workers
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// LogEvent represents a journal entry that matched a configured pattern
+type LogEvent struct {
+	Unit       string    `json:"unit"` // Service name without .service, or "kernel"
+	Identifier string    `json:"identifier,omitempty"`
+	PID        int       `json:"pid,omitempty"`
+	Message    string    `json:"message"`
+	Pattern    string    `json:"pattern"`
+	Severity   string    `json:"severity"` // info, warning, error, critical
+	Timestamp  time.Time `json:"timestamp"`
 }
 
 // SystemMetrics represents system-wide metrics
@@ -61,6 +140,9 @@ type SystemMetrics struct {
 	Disk      []DiskMetrics    `json:"disk"`
 	Network   []NetworkMetrics `json:"network"`
 	Load      LoadMetrics      `json:"load"`
+	DiskIO    []DiskIOMetrics  `json:"disk_io"`
+	Pressure  *PressureMetrics `json:"pressure,omitempty"` // nil when the kernel has no PSI support
+	TCP       TCPMetrics       `json:"tcp"`
 	Timestamp time.Time        `json:"timestamp"`
 }
 
@@ -98,7 +180,40 @@ type DiskMetrics struct {
 	InodesFree   uint64  `json:"inodes_free"`
 }
 
-// NetworkMetrics represents network interface metrics
+// DiskIOMetrics represents throughput and latency for a single block device,
+// computed from the change in /proc/diskstats since the previous collection
+type DiskIOMetrics struct {
+	Device           string  `json:"device"`
+	ReadsPerSec      float64 `json:"reads_per_sec"`
+	WritesPerSec     float64 `json:"writes_per_sec"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadAwaitMs      float64 `json:"read_await_ms"`
+	WriteAwaitMs     float64 `json:"write_await_ms"`
+	AwaitMs          float64 `json:"await_ms"`
+	UtilPercent      float64 `json:"util_percent"`
+	InFlight         uint64  `json:"in_flight"`
+}
+
+// PressureMetrics represents pressure stall information from /proc/pressure
+type PressureMetrics struct {
+	CPU    PressureStats `json:"cpu"`
+	Memory PressureStats `json:"memory"`
+	IO     PressureStats `json:"io"`
+}
+
+// PressureStats represents the share of time tasks were stalled on a resource
+type PressureStats struct {
+	SomeAvg10  float64 `json:"some_avg10"`
+	SomeAvg60  float64 `json:"some_avg60"`
+	SomeAvg300 float64 `json:"some_avg300"`
+	FullAvg10  float64 `json:"full_avg10"`
+	FullAvg60  float64 `json:"full_avg60"`
+	FullAvg300 float64 `json:"full_avg300"`
+}
+
+// NetworkMetrics represents network interface metrics. The counters are
+// cumulative since boot, the rates cover the interval since the previous collection.
 type NetworkMetrics struct {
 	Interface   string `json:"interface"`
 	BytesRecv   uint64 `json:"bytes_recv"`
@@ -109,6 +224,35 @@ type NetworkMetrics struct {
 	ErrorsSent  uint64 `json:"errors_sent"`
 	DroppedRecv uint64 `json:"dropped_recv"`
 	DroppedSent uint64 `json:"dropped_sent"`
+
+	BytesRecvPerSec   float64 `json:"bytes_recv_per_sec"`
+	BytesSentPerSec   float64 `json:"bytes_sent_per_sec"`
+	PacketsRecvPerSec float64 `json:"packets_recv_per_sec"`
+	PacketsSentPerSec float64 `json:"packets_sent_per_sec"`
+	ErrorsRecvPerSec  float64 `json:"errors_recv_per_sec"`
+	ErrorsSentPerSec  float64 `json:"errors_sent_per_sec"`
+	DroppedRecvPerSec float64 `json:"dropped_recv_per_sec"`
+	DroppedSentPerSec float64 `json:"dropped_sent_per_sec"`
+}
+
+// TCPMetrics represents TCP connection state counts from /proc/net/tcp and /proc/net/tcp6
+type TCPMetrics struct {
+	Established int `json:"established"`
+	SynSent     int `json:"syn_sent"`
+	SynRecv     int `json:"syn_recv"`
+	FinWait1    int `json:"fin_wait1"`
+	FinWait2    int `json:"fin_wait2"`
+	TimeWait    int `json:"time_wait"`
+	Close       int `json:"close"`
+	CloseWait   int `json:"close_wait"`
+	LastAck     int `json:"last_ack"`
+	Listen      int `json:"listen"`
+	Closing     int `json:"closing"`
+
+	// Connections waiting to be accepted, summed over all listening sockets
+	ListenBacklog int `json:"listen_backlog"`
+	// Longest accept queue of a single listening socket
+	ListenBacklogMax int `json:"listen_backlog_max"`
 }
 
 // LoadMetrics represents system load average metrics
@@ -156,10 +300,16 @@ const (
 type Config struct {
 	Agent         AgentConfig         `yaml:"agent"`
 	Collectors    CollectorsConfig    `yaml:"collectors"`
+	Discovery     DiscoveryConfig     `yaml:"discovery"`
 	Storage       StorageConfig       `yaml:"storage"`
 	Alerts        AlertsConfig        `yaml:"alerts"`
 	Notifications NotificationsConfig `yaml:"notifications"`
 	AI            AIConfig            `yaml:"ai"`
+	StatusPage    StatusPageConfig    `yaml:"status_page"`
+	SLOs          []SLOConfig         `yaml:"slos"`
+
+	// File the configuration was loaded from, used to reload it
+	path string
 }
 
 // AgentConfig represents agent-specific configuration
@@ -169,6 +319,15 @@ type AgentConfig struct {
 	CollectInterval string `yaml:"collect_interval"`
 	Debug           bool   `yaml:"debug"`
 	LogFile         string `yaml:"log_file"`
+	APIToken        string `yaml:"api_token" json:"-"` // Required by API writes and configuration requests
+}
+
+// GetAPIToken returns the API token, falling back to CRUCIBLE_MONITOR_API_TOKEN
+func (a *AgentConfig) GetAPIToken() string {
+	if a.APIToken != "" {
+		return a.APIToken
+	}
+	return os.Getenv("CRUCIBLE_MONITOR_API_TOKEN")
 }
 
 // CollectorsConfig represents collector configuration
@@ -176,6 +335,11 @@ type CollectorsConfig struct {
 	System     SystemCollectorConfig     `yaml:"system"`
 	Services   ServicesCollectorConfig   `yaml:"services"`
 	HTTPChecks HTTPChecksCollectorConfig `yaml:"http_checks"`
+	Probes     ProbesCollectorConfig     `yaml:"probes"`
+	PM2        PM2CollectorConfig        `yaml:"pm2"`
+	Supervisor SupervisorCollectorConfig `yaml:"supervisor"`
+	Journal    JournalCollectorConfig    `yaml:"journal"`
+	Exec       []ExecCollectorConfig     `yaml:"exec"`
 }
 
 // SystemCollectorConfig represents system metrics collector configuration
@@ -190,6 +354,17 @@ type ServicesCollectorConfig struct {
 	Enabled  bool     `yaml:"enabled"`
 	Interval string   `yaml:"interval"`
 	Services []string `yaml:"services"`
+
+	// Services added by auto-discovery, also present in Services
+	Discovered []string `yaml:"-"`
+}
+
+// DiscoveryConfig represents auto-discovery of services and sites installed by Crucible
+type DiscoveryConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	CaddySitesDir string   `yaml:"caddy_sites_dir"`
+	CheckInterval string   `yaml:"check_interval"` // Interval for discovered HTTP checks
+	Exclude       []string `yaml:"exclude"`        // Service names, check names or domains to skip
 }
 
 // HTTPChecksCollectorConfig represents HTTP health check configuration
@@ -198,6 +373,74 @@ type HTTPChecksCollectorConfig struct {
 	Checks  []HTTPCheck `yaml:"checks"`
 }
 
+// ProbesCollectorConfig represents TCP, DNS, ICMP and TLS probe configuration
+type ProbesCollectorConfig struct {
+	Enabled bool         `yaml:"enabled"`
+	Checks  []ProbeCheck `yaml:"checks"`
+}
+
+// ProbeCheck represents a single probe configuration
+type ProbeCheck struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`   // tcp, dns, icmp or tls
+	Target   string `yaml:"target"` // host:port for tcp and tls, hostname for dns and icmp
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+
+	// DNS options
+	RecordType string   `yaml:"record_type"` // A, AAAA, CNAME, MX, NS or TXT, defaults to A
+	Expected   []string `yaml:"expected"`    // Records that must be present in the answer
+	Resolver   string   `yaml:"resolver"`    // host:port of the DNS server, empty uses the system resolver
+
+	// ICMP options
+	Count int `yaml:"count"` // Echo requests per probe, defaults to 3
+
+	// TLS options
+	ServerName string `yaml:"server_name"` // SNI name, defaults to the target host
+	SkipVerify bool   `yaml:"skip_verify"` // Only check the handshake, not the certificate chain
+}
+
+// PM2CollectorConfig represents PM2 process monitoring configuration
+type PM2CollectorConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Interval        string `yaml:"interval"`
+	PM2Home         string `yaml:"pm2_home"`
+	CrashLoopWindow string `yaml:"crash_loop_window"`
+}
+
+// SupervisorCollectorConfig represents supervisord process monitoring configuration
+type SupervisorCollectorConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Interval   string `yaml:"interval"`
+	SocketPath string `yaml:"socket_path"`
+}
+
+// JournalCollectorConfig represents systemd journal monitoring configuration
+type JournalCollectorConfig struct {
+	Enabled  bool             `yaml:"enabled"`
+	Units    []string         `yaml:"units"` // Defaults to the monitored services, kernel messages are always included
+	Patterns []JournalPattern `yaml:"patterns"`
+}
+
+// JournalPattern maps a regular expression on journal messages to an event severity
+type JournalPattern struct {
+	Name     string `yaml:"name"`
+	Pattern  string `yaml:"pattern"`
+	Severity string `yaml:"severity"` // info, warning, error, critical
+	Unit     string `yaml:"unit"`     // Optional unit or syslog identifier, "kernel" for kernel messages
+}
+
+// ExecCollectorConfig represents a custom collector that runs a script and parses its output
+type ExecCollectorConfig struct {
+	Name     string            `yaml:"name"`
+	Command  string            `yaml:"command"`
+	Args     []string          `yaml:"args"`
+	Interval string            `yaml:"interval"`
+	Timeout  string            `yaml:"timeout"`
+	Format   string            `yaml:"format"` // nagios, json, influx
+	Tags     map[string]string `yaml:"tags"`   // Added to every metric
+}
+
 // HTTPCheck represents a single HTTP health check configuration
 type HTTPCheck struct {
 	Name           string `yaml:"name"`
@@ -205,23 +448,101 @@ type HTTPCheck struct {
 	Interval       string `yaml:"interval"`
 	Timeout        string `yaml:"timeout"`
 	ExpectedStatus int    `yaml:"expected_status"`
+
+	// Synthetic transaction checks run these steps in order instead of a single GET of URL
+	Steps     []HTTPStep        `yaml:"steps"`
+	Variables map[string]string `yaml:"variables"` // Initial values for ${name} substitution
+
+	// Discovered is set for checks created by auto-discovery
+	Discovered bool `yaml:"-"`
+}
+
+// HTTPStep is a single request in a synthetic transaction check. The URL,
+// headers and body may reference ${name} variables and ${env:NAME}.
+type HTTPStep struct {
+	Name           string            `yaml:"name"`
+	Method         string            `yaml:"method"` // Defaults to GET
+	URL            string            `yaml:"url"`
+	Headers        map[string]string `yaml:"headers"`
+	Body           string            `yaml:"body"`
+	ExpectedStatus int               `yaml:"expected_status"` // Defaults to 200
+	Extract        []HTTPExtract     `yaml:"extract"`
+	Assert         []HTTPAssertion   `yaml:"assert"`
+}
+
+// HTTPExtract stores part of a step response in a variable for later steps
+type HTTPExtract struct {
+	Var    string `yaml:"var"`
+	JSON   string `yaml:"json"`   // Dot path into a JSON body, e.g. data.token or items.0.id
+	Regex  string `yaml:"regex"`  // First capture group, or the whole match
+	Header string `yaml:"header"` // Response header name
+}
+
+// HTTPAssertion is a condition a step response must meet. Set exactly one of
+// the checks; Equals applies to JSON and Header.
+type HTTPAssertion struct {
+	BodyContains    string `yaml:"body_contains"`
+	BodyRegex       string `yaml:"body_regex"`
+	JSON            string `yaml:"json"` // Dot path that must be present
+	Header          string `yaml:"header"`
+	Equals          string `yaml:"equals"`
+	MaxResponseTime string `yaml:"max_response_time"`
 }
 
 // StorageConfig represents storage configuration
 type StorageConfig struct {
-	Type        string            `yaml:"type"`
-	SQLite      SQLiteConfig      `yaml:"sqlite"`
-	Aggregation AggregationConfig `yaml:"aggregation"`
+	Type        string              `yaml:"type"`
+	SQLite      SQLiteConfig        `yaml:"sqlite"`
+	Memory      MemoryConfig        `yaml:"memory"`
+	Aggregation AggregationConfig   `yaml:"aggregation"`
+	RemoteWrite []RemoteWriteConfig `yaml:"remote_write"`
+}
+
+// RemoteWriteConfig represents an external time-series database that stored metrics are forwarded to
+type RemoteWriteConfig struct {
+	Name            string            `yaml:"name"`
+	Type            string            `yaml:"type"` // prometheus, influxdb or otlp
+	URL             string            `yaml:"url"`
+	Headers         map[string]string `yaml:"headers"`
+	Username        string            `yaml:"username"` // Basic auth
+	Password        string            `yaml:"password"`
+	Token           string            `yaml:"token"`  // Bearer token, or API token for InfluxDB
+	Labels          map[string]string `yaml:"labels"` // Added to every sample, host defaults to the hostname
+	Timeout         string            `yaml:"timeout"`
+	BatchSize       int               `yaml:"batch_size"`
+	FlushInterval   string            `yaml:"flush_interval"`
+	QueueDir        string            `yaml:"queue_dir"`         // Batches waiting for the remote are kept here
+	MaxQueueBatches int               `yaml:"max_queue_batches"` // Oldest batches are dropped beyond this
+}
+
+// GetTimeout returns the request timeout as a duration
+func (r *RemoteWriteConfig) GetTimeout() time.Duration {
+	duration, _ := time.ParseDuration(r.Timeout)
+	return duration
+}
+
+// GetFlushInterval returns the flush interval as a duration
+func (r *RemoteWriteConfig) GetFlushInterval() time.Duration {
+	duration, _ := time.ParseDuration(r.FlushInterval)
+	return duration
 }
 
 // SQLiteConfig represents SQLite storage configuration
 type SQLiteConfig struct {
 	Path            string          `yaml:"path"`
+	Driver          string          `yaml:"driver"` // auto, cgo or purego
 	BatchSize       int             `yaml:"batch_size"`
 	CleanupInterval time.Duration   `yaml:"cleanup_interval"`
 	BackupEnabled   bool            `yaml:"backup_enabled"`
 	BackupInterval  time.Duration   `yaml:"backup_interval"`
 	Retention       RetentionConfig `yaml:"retention"`
+	RetentionPolicy RetentionPolicy `yaml:"retention_policy"` // Applied on every cleanup cycle
+}
+
+// MemoryConfig represents in-memory storage configuration
+type MemoryConfig struct {
+	MaxEvents  int `yaml:"max_events"`  // Oldest events are dropped beyond this
+	MaxMetrics int `yaml:"max_metrics"` // Oldest metric samples are dropped beyond this
 }
 
 // RetentionConfig represents data retention configuration
@@ -231,6 +552,23 @@ type RetentionConfig struct {
 	AggregatesDays int `yaml:"aggregates_days"`
 }
 
+// RetentionPolicy represents per type retention and a database size cap.
+// Durations are Go durations or days such as "14d".
+type RetentionPolicy struct {
+	EntityTypes map[string]string     `yaml:"entity_types"` // Inactive entities not updated within the duration
+	EventTypes  map[string]string     `yaml:"event_types"`
+	MetricTypes map[string]string     `yaml:"metric_types"` // Raw samples by metric name
+	Global      GlobalRetentionPolicy `yaml:"global"`
+}
+
+// GlobalRetentionPolicy represents retention rules for all data
+type GlobalRetentionPolicy struct {
+	MaxDatabaseSize int64  `yaml:"max_database_size_bytes"` // Oldest data is evicted beyond this
+	MaxEventAge     string `yaml:"max_event_age"`
+	MaxMetricAge    string `yaml:"max_metric_age"`
+	AggregationAge  string `yaml:"aggregation_age"`
+}
+
 // AggregationConfig represents data aggregation configuration
 type AggregationConfig struct {
 	RawRetention    string `yaml:"raw_retention"`
@@ -243,6 +581,15 @@ type AlertsConfig struct {
 	Enabled       bool            `yaml:"enabled"`
 	Thresholds    AlertThresholds `yaml:"thresholds"`
 	CheckInterval string          `yaml:"check_interval"`
+	RulesFile     string          `yaml:"rules_file"` // Alert rules and notifier settings
+	Incidents     IncidentsConfig `yaml:"incidents"`
+}
+
+// IncidentsConfig represents how fired alerts are correlated with surrounding events
+type IncidentsConfig struct {
+	Lookback   string   `yaml:"lookback"`    // Events this long before an alert fired are attached
+	EventTypes []string `yaml:"event_types"` // Event types attached to timelines, all when empty
+	MaxEvents  int      `yaml:"max_events"`  // Timeline length limit
 }
 
 // AlertThresholds represents alert threshold configuration
@@ -278,6 +625,50 @@ type WebhookConfig struct {
 	Timeout string `yaml:"timeout"`
 }
 
+// StatusPageConfig represents the public status page built from HTTP check results
+type StatusPageConfig struct {
+	Enabled     bool                `yaml:"enabled"`
+	Title       string              `yaml:"title"`
+	Interval    string              `yaml:"interval"`    // How often the page is rebuilt
+	Days        int                 `yaml:"days"`        // Days of uptime history, defaults to 90
+	ListenAddr  string              `yaml:"listen_addr"` // Serve /status on its own server here, apart from the API
+	OutputDir   string              `yaml:"output_dir"`  // Also write index.html and status.json here
+	Groups      []StatusPageGroup   `yaml:"groups"`      // Empty shows every HTTP check in one group
+	Maintenance []MaintenanceWindow `yaml:"maintenance"`
+}
+
+// StatusPageGroup is a titled group of components on the status page
+type StatusPageGroup struct {
+	Name       string                `yaml:"name"`
+	Components []StatusPageComponent `yaml:"components"`
+}
+
+// StatusPageComponent shows an HTTP check on the status page
+type StatusPageComponent struct {
+	Check       string `yaml:"check"` // HTTP check name
+	Name        string `yaml:"name"`  // Display name, defaults to the check name
+	Description string `yaml:"description"`
+}
+
+// MaintenanceWindow is scheduled maintenance announced on the status page
+type MaintenanceWindow struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Start       string   `yaml:"start"`  // RFC 3339, e.g. 2025-08-02T22:00:00Z
+	End         string   `yaml:"end"`    // RFC 3339
+	Checks      []string `yaml:"checks"` // Affected HTTP checks, empty for all
+}
+
+// SLOConfig represents a service level objective for an HTTP check
+type SLOConfig struct {
+	Name      string  `yaml:"name"`      // Defaults to <check>-<type>
+	Check     string  `yaml:"check"`     // HTTP check name
+	Type      string  `yaml:"type"`      // availability (default) or latency
+	Objective float64 `yaml:"objective"` // Percent of good checks, e.g. 99.9
+	Window    string  `yaml:"window"`    // Rolling window, defaults to 30d
+	Threshold string  `yaml:"threshold"` // Latency SLOs: successful checks faster than this are good
+}
+
 // AIConfig represents AI/ML configuration
 type AIConfig struct {
 	Enabled            bool                     `yaml:"enabled"`
@@ -287,9 +678,10 @@ type AIConfig struct {
 
 // AnomalyDetectionConfig represents anomaly detection configuration
 type AnomalyDetectionConfig struct {
-	Enabled        bool   `yaml:"enabled"`
-	Sensitivity    string `yaml:"sensitivity"`
-	LearningPeriod string `yaml:"learning_period"`
+	Enabled        bool     `yaml:"enabled"`
+	Sensitivity    string   `yaml:"sensitivity"`
+	LearningPeriod string   `yaml:"learning_period"`
+	Metrics        []string `yaml:"metrics"` // Stored metric names to learn, anomaly alert rules add their own
 }
 
 // PatternRecognitionConfig represents pattern recognition configuration
@@ -0,0 +1,381 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLList is a list in a YAML document that is edited in place. Only the
+// lines of changed entries are rewritten, so formatting and comments
+// elsewhere in the file are kept as they are.
+type YAMLList struct {
+	lines []string
+	path  []string
+}
+
+// yamlListLocation describes where a list sits in the document
+type yamlListLocation struct {
+	items     []*yaml.Node // Entries of an existing list
+	block     bool         // The list exists in block style with at least one entry
+	keyLine   int          // Line of the list key, -1 if it does not exist yet
+	keyIndent int
+	insertAt  int      // Line where missing keys are inserted
+	indent    int      // Indentation of the first missing key
+	missing   []string // Keys of the path that do not exist yet
+}
+
+// EditYAMLList applies edit to the list at path in a YAML document and returns
+// the edited document. Keys missing along the path are created.
+func EditYAMLList(data []byte, path []string, edit func(list *YAMLList) error) ([]byte, error) {
+	list := &YAMLList{path: path}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line != "" {
+			list.lines = append(list.lines, line)
+		}
+	}
+	if n := len(list.lines); n > 0 && !strings.HasSuffix(list.lines[n-1], "\n") {
+		list.lines[n-1] += "\n"
+	}
+
+	if _, err := list.locate(); err != nil {
+		return nil, err
+	}
+	if err := edit(list); err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.Join(list.lines, "")), nil
+}
+
+// Find returns the index of the entry whose key field equals value, or of the
+// scalar entry equal to value when key is empty. It returns -1 if there is no
+// such entry.
+func (l *YAMLList) Find(key, value string) int {
+	loc, err := l.locate()
+	if err != nil {
+		return -1
+	}
+
+	for i, item := range loc.items {
+		if key == "" {
+			if item.Kind == yaml.ScalarNode && item.Value == value {
+				return i
+			}
+			continue
+		}
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			if item.Content[j].Value == key && item.Content[j+1].Value == value {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Append adds value as the last entry of the list
+func (l *YAMLList) Append(value interface{}) error {
+	loc, err := l.locate()
+	if err != nil {
+		return err
+	}
+	node, err := encodeYAMLItem(value)
+	if err != nil {
+		return err
+	}
+
+	if !loc.block {
+		return l.rewrite(loc, append(loc.items, node))
+	}
+
+	last := len(loc.items) - 1
+	_, end, indent := l.itemRange(loc.items[last])
+	return l.splice(end, end, node, indent)
+}
+
+// Replace replaces the entry at index with value, keeping the comments above it
+func (l *YAMLList) Replace(index int, value interface{}) error {
+	loc, err := l.locate()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(loc.items) {
+		return fmt.Errorf("list index %d out of range", index)
+	}
+	node, err := encodeYAMLItem(value)
+	if err != nil {
+		return err
+	}
+
+	if !loc.block {
+		items := append([]*yaml.Node(nil), loc.items...)
+		items[index] = node
+		return l.rewrite(loc, items)
+	}
+
+	start, end, indent := l.itemRange(loc.items[index])
+	return l.splice(start, end, node, indent)
+}
+
+// Remove deletes the entry at index
+func (l *YAMLList) Remove(index int) error {
+	loc, err := l.locate()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(loc.items) {
+		return fmt.Errorf("list index %d out of range", index)
+	}
+
+	items := append(append([]*yaml.Node(nil), loc.items[:index]...), loc.items[index+1:]...)
+	if !loc.block || len(items) == 0 {
+		return l.rewrite(loc, items)
+	}
+
+	start, end, _ := l.itemRange(loc.items[index])
+	return l.splice(start, end, nil, 0)
+}
+
+// locate parses the document and finds the list
+func (l *YAMLList) locate() (*yamlListLocation, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(l.lines, "")), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	loc := &yamlListLocation{keyLine: -1, insertAt: len(l.lines), missing: l.path}
+	if doc.Kind == 0 {
+		return loc, nil
+	}
+
+	node := doc.Content[0]
+	parentLine, parentIndent := -1, -2
+	for i, key := range l.path {
+		// An empty key such as "http_checks:" decodes as null
+		isNull := node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+		if node.Kind != yaml.MappingNode && !isNull {
+			return nil, fmt.Errorf("%s is not a mapping", strings.Join(l.path[:i], "."))
+		}
+		if node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle != 0 {
+			return nil, fmt.Errorf("%s uses flow style and cannot be edited", strings.Join(l.path[:i], "."))
+		}
+
+		var keyNode, valueNode *yaml.Node
+		for j := 0; !isNull && j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				keyNode, valueNode = node.Content[j], node.Content[j+1]
+				break
+			}
+		}
+
+		if keyNode == nil {
+			loc.missing = l.path[i:]
+			loc.indent = parentIndent + 2
+			if !isNull && len(node.Content) > 0 {
+				loc.indent = node.Content[0].Column - 1
+			}
+			if parentLine >= 0 {
+				loc.insertAt = l.blockEnd(parentLine, parentIndent)
+			}
+			return loc, nil
+		}
+
+		parentLine, parentIndent = keyNode.Line-1, keyNode.Column-1
+		node = valueNode
+	}
+
+	loc.missing = nil
+	loc.keyLine, loc.keyIndent = parentLine, parentIndent
+
+	switch {
+	case node.Kind == yaml.ScalarNode && node.Tag == "!!null":
+	case node.Kind == yaml.SequenceNode && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0:
+		loc.items = node.Content
+		loc.block = true
+	case node.Kind == yaml.SequenceNode:
+		if node.Line != loc.keyLine+1 || l.blockEnd(loc.keyLine, loc.keyIndent) != loc.keyLine+1 {
+			return nil, fmt.Errorf("%s must be on a single line", strings.Join(l.path, "."))
+		}
+		loc.items = node.Content
+	default:
+		return nil, fmt.Errorf("%s is not a list", strings.Join(l.path, "."))
+	}
+
+	return loc, nil
+}
+
+// itemRange returns the lines of a block list entry and the indentation of its dash
+func (l *YAMLList) itemRange(item *yaml.Node) (int, int, int) {
+	start := item.Line - 1
+	indent := lineIndent(l.lines[start])
+	return start, l.blockEnd(start, indent), indent
+}
+
+// blockEnd returns the line after the block starting at line start, which
+// continues while lines are indented deeper than indent
+func (l *YAMLList) blockEnd(start, indent int) int {
+	end := start + 1
+	for end < len(l.lines) {
+		if strings.TrimSpace(l.lines[end]) == "" || lineIndent(l.lines[end]) <= indent {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// splice replaces lines [start, end) with node rendered as a list entry
+func (l *YAMLList) splice(start, end int, node *yaml.Node, indent int) error {
+	var lines []string
+	if node != nil {
+		rendered, err := renderYAMLItem(node, indent)
+		if err != nil {
+			return err
+		}
+		lines = rendered
+	}
+
+	l.lines = append(l.lines[:start], append(lines, l.lines[end:]...)...)
+	return nil
+}
+
+// rewrite writes the list in block style, replacing an empty, null or flow
+// style value or creating the missing keys
+func (l *YAMLList) rewrite(loc *yamlListLocation, items []*yaml.Node) error {
+	var lines []string
+	start, end, indent := loc.insertAt, loc.insertAt, loc.indent
+
+	if loc.missing == nil {
+		start, end, indent = loc.keyLine, loc.keyLine+1, loc.keyIndent
+		if loc.block {
+			end = l.blockEnd(loc.keyLine, loc.keyIndent)
+			// Entries of a block list may share the key's indentation
+			for _, item := range loc.items {
+				if _, itemEnd, _ := l.itemRange(item); itemEnd > end {
+					end = itemEnd
+				}
+			}
+		}
+		key := l.path[len(l.path)-1]
+		line := strings.Repeat(" ", indent) + key + ":"
+		if len(items) == 0 {
+			line += " []"
+		}
+		lines = append(lines, line+lineComment(l.lines[loc.keyLine])+"\n")
+	} else {
+		for i, key := range loc.missing {
+			line := strings.Repeat(" ", indent+2*i) + key + ":"
+			if i == len(loc.missing)-1 && len(items) == 0 {
+				line += " []"
+			}
+			lines = append(lines, line+"\n")
+		}
+		indent += 2 * (len(loc.missing) - 1)
+	}
+
+	for _, item := range items {
+		rendered, err := renderYAMLItem(item, indent+2)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, rendered...)
+	}
+
+	l.lines = append(l.lines[:start], append(lines, l.lines[end:]...)...)
+	return nil
+}
+
+// encodeYAMLItem encodes value as a YAML node, leaving out empty settings so
+// written entries look like hand-written ones
+func encodeYAMLItem(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	pruneEmptyNodes(node)
+	return node, nil
+}
+
+// renderYAMLItem renders node as a block list entry with the dash at indent
+func renderYAMLItem(node *yaml.Node, indent int) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	prefix := strings.Repeat(" ", indent)
+	var lines []string
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		switch {
+		case i == 0:
+			lines = append(lines, prefix+"- "+line+"\n")
+		case line == "":
+			lines = append(lines, "\n")
+		default:
+			lines = append(lines, prefix+"  "+line+"\n")
+		}
+	}
+	return lines, nil
+}
+
+// pruneEmptyNodes removes mapping entries with null, empty or zero values
+func pruneEmptyNodes(node *yaml.Node) {
+	for _, child := range node.Content {
+		pruneEmptyNodes(child)
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	content := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		empty := false
+		switch value.Kind {
+		case yaml.ScalarNode:
+			empty = value.Tag == "!!null" || (value.Tag == "!!str" && value.Value == "") ||
+				(value.Tag == "!!int" && value.Value == "0")
+		case yaml.MappingNode, yaml.SequenceNode:
+			empty = len(value.Content) == 0
+		}
+		if !empty {
+			content = append(content, node.Content[i], value)
+		}
+	}
+	node.Content = content
+}
+
+// lineIndent returns the number of leading spaces of a line
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// lineComment returns the trailing comment of a key line, including the
+// separating space, so it survives the key being rewritten
+func lineComment(line string) string {
+	line = strings.TrimRight(line, "\n")
+	inQuote := rune(0)
+	for i, r := range line {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			}
+		case r == '"' || r == '\'':
+			inQuote = r
+		case r == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t'):
+			return " " + line[i:]
+		}
+	}
+	return ""
+}
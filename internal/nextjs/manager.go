@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"crucible/internal/git"
+	"crucible/internal/monitor/collectors"
 )
 
 // NextJSManager handles Next.js site management
@@ -356,27 +357,66 @@ func (nm *NextJSManager) findAvailablePort(startPort int) int {
 // GetSiteStatus returns the current status of a site
 func (nm *NextJSManager) GetSiteStatus(siteName string) (*SiteStatus, error) {
 	// Get PM2 process information
-	cmd := exec.Command("pm2", "jlist")
-	_, err := cmd.Output()
+	app, err := collectors.NewPM2Collector("", 0).GetApp(siteName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PM2 status: %w", err)
 	}
 
-	// Parse PM2 output and extract site information
-	// This is a simplified implementation
 	status := &SiteStatus{
 		Name:      siteName,
-		Status:    "running",
-		PM2Status: "online",
-		Instances: 2,
-		CPU:       15.5,
-		Memory:    "245MB",
-		Uptime:    "2d 3h 15m",
+		Status:    siteStatusFromPM2(app.Status),
+		PM2Status: app.Status,
+		Instances: app.Instances,
+		CPU:       app.CPUPercent,
+		Memory:    formatMemory(app.MemoryBytes),
+		Uptime:    formatUptime(app.Uptime),
 	}
 
 	return status, nil
 }
 
+// siteStatusFromPM2 maps a PM2 process status to a site status
+func siteStatusFromPM2(pm2Status string) string {
+	switch pm2Status {
+	case "online":
+		return "running"
+	case "stopping", "stopped":
+		return "stopped"
+	case "launching", "one-launch-status":
+		return "starting"
+	default:
+		return "error"
+	}
+}
+
+// formatMemory formats a byte count as a short human-readable string
+func formatMemory(bytes uint64) string {
+	const mb = 1024 * 1024
+	if bytes >= 1024*mb {
+		return fmt.Sprintf("%.1fGB", float64(bytes)/(1024*mb))
+	}
+	return fmt.Sprintf("%dMB", bytes/mb)
+}
+
+// formatUptime formats a duration as days, hours and minutes
+func formatUptime(d time.Duration) string {
+	if d <= 0 {
+		return "0m"
+	}
+
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
 // ListSites returns a list of all managed Next.js sites
 func (nm *NextJSManager) ListSites() ([]*Site, error) {
 	// This would read from a configuration file or database
@@ -499,7 +539,7 @@ func (nm *NextJSManager) GetSiteWithRepositoryInfo(siteName string) (*SiteInfo,
 		return nil, fmt.Errorf("failed to get repository status: %w", err)
 	}
 
-	// Get PM2 status (simplified)
+	// Get PM2 status
 	siteStatus, err := nm.GetSiteStatus(siteName)
 	if err != nil {
 		// Don't fail if PM2 status is unavailable, just set defaults
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -214,6 +215,7 @@ func (a *AppModel) handleCommandCompleted(msg CmdCompletedMsg) (tea.Model, tea.C
 
 	if msg.Result.Error != nil {
 		// Command failed - stop queue and show error
+		recordOperation(queue.Operation(msg.Result))
 		queue.Reset()
 		queue.AddResult(fmt.Sprintf("❌ Failed: %v", msg.Result.Error))
 		a.shared.ProcessingMsg = ""
@@ -237,6 +239,7 @@ func (a *AppModel) handleCommandCompleted(msg CmdCompletedMsg) (tea.Model, tea.C
 	// All commands completed
 	queue.IsProcessing = false
 	a.shared.ProcessingMsg = ""
+	recordOperation(queue.Operation(msg.Result))
 
 	// Navigate to processing view to show results
 	return a.navigateTo(StateProcessing, nil)
@@ -371,12 +374,14 @@ func ExecuteCommandBatchAsync(commands []string, descriptions []string, serviceN
 		var firstError error
 		var totalDuration time.Duration
 		startTime := time.Now()
+		steps := 0
 
 		// Execute commands in sequence
 		for i, command := range commands {
 			result := executeCommand(command, descriptions[i])
 			allOutput = append(allOutput, fmt.Sprintf("Step %d (%s):\n%s", i+1, descriptions[i], result.Output))
 			totalDuration += result.Duration
+			steps++
 
 			// Stop on first error
 			if result.Error != nil {
@@ -385,17 +390,28 @@ func ExecuteCommandBatchAsync(commands []string, descriptions []string, serviceN
 			}
 		}
 
+		result := logging.LoggedExecResult{
+			Command:   fmt.Sprintf("batch execution (%d commands)", len(commands)),
+			Output:    fmt.Sprintf("=== BATCH EXECUTION RESULTS ===\n%s\n=== END RESULTS ===", strings.Join(allOutput, "\n---\n")),
+			Error:     firstError,
+			StartTime: startTime,
+			EndTime:   time.Now(),
+			Duration:  totalDuration,
+			ExitCode:  getExitCodeFromError(firstError),
+		}
+
+		recordOperation(Operation{
+			Name:      serviceName,
+			StartedAt: startTime,
+			Steps:     steps,
+			Total:     len(commands),
+			ExitCode:  result.ExitCode,
+			Err:       firstError,
+		})
+
 		// Return combined result
 		return CmdCompletedMsg{
-			Result: logging.LoggedExecResult{
-				Command:   fmt.Sprintf("batch execution (%d commands)", len(commands)),
-				Output:    fmt.Sprintf("=== BATCH EXECUTION RESULTS ===\n%s\n=== END RESULTS ===", strings.Join(allOutput, "\n---\n")),
-				Error:     firstError,
-				StartTime: startTime,
-				EndTime:   time.Now(),
-				Duration:  totalDuration,
-				ExitCode:  getExitCodeFromError(firstError),
-			},
+			Result:      result,
 			ServiceName: serviceName,
 		}
 	}
@@ -406,7 +422,9 @@ func getExitCodeFromError(err error) int {
 	if err == nil {
 		return 0
 	}
-	if exitError, ok := err.(*exec.ExitError); ok {
+	// Batch errors wrap the exit error of the failed step
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
 		return exitError.ExitCode()
 	}
 	return -1
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,8 +32,13 @@ const (
 	MonitoringViewHistorical
 	MonitoringViewEvents
 	MonitoringViewStorage
+	MonitoringViewIncidents
+	MonitoringViewSLOs
 )
 
+// incidentListLimit is the number of recent incidents listed in the incidents view
+const incidentListLimit = 20
+
 // HistoricalTimeRange represents time range options for historical data
 type HistoricalTimeRange int
 
@@ -100,11 +106,24 @@ type HistoricalData struct {
 	MemoryHistory []float64
 	LoadHistory   []float64
 	Timestamps    []time.Time
+	Markers       []OperationMarker // Crucible operations in the range, oldest first
 	AvgCPU        float64
 	AvgMemory     float64
 	AvgLoad       float64
 }
 
+// OperationMarker represents a Crucible operation shown on the historical charts
+type OperationMarker struct {
+	Type      string
+	Operation string
+	User      string
+	Failed    bool
+	Timestamp time.Time
+}
+
+// markerEventTypes are the event types of operations that change the server
+var markerEventTypes = []string{"install", "update", "restart", "start", "stop", "maintenance"}
+
 // MonitoringEvent represents a system event or alert
 type MonitoringEvent struct {
 	ID        string
@@ -122,6 +141,8 @@ type StorageStatistics struct {
 	Databases         []DatabaseInfo
 	LogFiles          []LogFileInfo
 	LaravelSites      []SiteStorageInfo
+	Forecasts         []CapacityForecast
+	ForecastError     error
 	TotalDatabaseSize int64
 	TotalLogSize      int64
 	TotalSiteSize     int64
@@ -136,6 +157,56 @@ type DiskUsage struct {
 	UsedPercent float64
 }
 
+// CapacityForecast represents when a disk, its inodes or the monitor database is forecast to fill up
+type CapacityForecast struct {
+	Resource     string     `json:"resource"`
+	Target       string     `json:"target"`
+	Current      float64    `json:"current"`
+	Capacity     float64    `json:"capacity"`
+	Unit         string     `json:"unit"`
+	GrowthPerDay float64    `json:"growth_per_day"`
+	FullAt       *time.Time `json:"full_at,omitempty"`
+	Timestamp    time.Time  `json:"timestamp"`
+}
+
+// Incident represents an alert from firing to resolution, as stored by the monitoring agent
+type Incident struct {
+	ID         int64      `json:"id"`
+	RuleID     string     `json:"rule_id"`
+	Title      string     `json:"title"`
+	Severity   string     `json:"severity"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message"`
+	OpenedAt   time.Time  `json:"opened_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SLOStatus represents the error budget of a service level objective, as tracked by the monitoring agent
+type SLOStatus struct {
+	Name            string             `json:"name"`
+	Check           string             `json:"check"`
+	Type            string             `json:"type"`
+	Objective       float64            `json:"objective"`
+	Window          string             `json:"window"`
+	ThresholdMs     *int64             `json:"threshold_ms,omitempty"`
+	Status          string             `json:"status"`
+	Total           int                `json:"total"`
+	Good            int                `json:"good"`
+	SLI             *float64           `json:"sli,omitempty"`
+	P95Ms           *float64           `json:"p95_ms,omitempty"`
+	BudgetRemaining *float64           `json:"budget_remaining,omitempty"`
+	BurnRates       map[string]float64 `json:"burn_rates"`
+	DataSince       *time.Time         `json:"data_since,omitempty"`
+}
+
+// IncidentTimeline represents an incident with the events recorded around it, oldest first
+type IncidentTimeline struct {
+	Incident Incident      `json:"incident"`
+	Since    time.Time     `json:"since"`
+	Until    time.Time     `json:"until"`
+	Events   []StoredEvent `json:"events"`
+}
+
 // DatabaseInfo represents database storage information
 type DatabaseInfo struct {
 	Name      string
@@ -167,6 +238,7 @@ type MonitoringModel struct {
 	view         MonitoringView
 	timeRange    HistoricalTimeRange
 	scrollPos    int
+	incident     int // Selected incident in the incidents view
 	data         MonitoringData
 	refreshing   bool
 	autoRefresh  bool
@@ -225,6 +297,22 @@ func (m *MonitoringModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "s":
 			m.setView(MonitoringViewStorage)
 			return m, m.fetchData()
+		case "i":
+			m.setView(MonitoringViewIncidents)
+			return m, m.fetchData()
+		case "o":
+			m.setView(MonitoringViewSLOs)
+			return m, m.fetchData()
+
+		// Incident selection (for incidents view)
+		case "n":
+			if m.getView() == MonitoringViewIncidents {
+				m.adjustIncident(1)
+			}
+		case "p":
+			if m.getView() == MonitoringViewIncidents {
+				m.adjustIncident(-1)
+			}
 
 		// Time range selection (for historical and events views)
 		case "1":
@@ -346,6 +434,10 @@ func (m *MonitoringModel) getViewName() string {
 		return "Events"
 	case MonitoringViewStorage:
 		return "Storage"
+	case MonitoringViewIncidents:
+		return "Incidents"
+	case MonitoringViewSLOs:
+		return "SLOs"
 	default:
 		return "Unknown"
 	}
@@ -395,6 +487,10 @@ func (m *MonitoringModel) renderViewContent() string {
 		return m.renderEventsView()
 	case MonitoringViewStorage:
 		return m.renderStorageView()
+	case MonitoringViewIncidents:
+		return m.renderIncidentsView()
+	case MonitoringViewSLOs:
+		return m.renderSLOsView()
 	default:
 		return "Unknown view"
 	}
@@ -505,21 +601,40 @@ func (m *MonitoringModel) renderHistoricalView() string {
 	// CPU Usage Chart
 	s.WriteString(infoStyle.Render("CPU Usage Over Time:"))
 	s.WriteString("\n")
-	s.WriteString(m.renderSimpleChart("CPU", histData.CPUHistory, "%"))
+	s.WriteString(m.renderSimpleChart("CPU", histData.CPUHistory, "%", m.renderMarkerRow(histData)))
 	s.WriteString("\n\n")
 
 	// Memory Usage Chart
 	s.WriteString(infoStyle.Render("Memory Usage Over Time:"))
 	s.WriteString("\n")
-	s.WriteString(m.renderSimpleChart("Memory", histData.MemoryHistory, "%"))
+	s.WriteString(m.renderSimpleChart("Memory", histData.MemoryHistory, "%", m.renderMarkerRow(histData)))
 	s.WriteString("\n\n")
 
 	// Load Average Chart
 	s.WriteString(infoStyle.Render("Load Average Over Time:"))
 	s.WriteString("\n")
-	s.WriteString(m.renderSimpleChart("Load", histData.LoadHistory, ""))
+	s.WriteString(m.renderSimpleChart("Load", histData.LoadHistory, "", m.renderMarkerRow(histData)))
 	s.WriteString("\n\n")
 
+	// Operations marked on the charts
+	if len(histData.Markers) > 0 {
+		s.WriteString(infoStyle.Render("Crucible Operations (▲ on the charts, ✖ failed):"))
+		s.WriteString("\n")
+		for _, marker := range histData.Markers {
+			line := fmt.Sprintf("%s %s %-8s %s", m.markerSymbol(marker),
+				marker.Timestamp.Format("01-02 15:04"), marker.Type, marker.Operation)
+			if marker.User != "" {
+				line += " by " + marker.User
+			}
+			if marker.Failed {
+				line = errorStyle.Render(line)
+			}
+			s.WriteString(line)
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+	}
+
 	// Current vs Historical Summary
 	s.WriteString(infoStyle.Render("Summary:"))
 	s.WriteString("\n")
@@ -677,6 +792,22 @@ func (m *MonitoringModel) renderStorageView() string {
 	}
 	s.WriteString("\n")
 
+	// Capacity forecasts from the monitoring agent
+	s.WriteString(infoStyle.Render("📈 CAPACITY FORECAST"))
+	s.WriteString("\n")
+	if storageStats.ForecastError != nil {
+		s.WriteString(helpStyle.Render("Forecasts unavailable: monitoring agent not reachable"))
+		s.WriteString("\n")
+	} else if len(storageStats.Forecasts) == 0 {
+		s.WriteString(helpStyle.Render("Not enough history yet, forecasts need about 2 hours of stored metrics"))
+		s.WriteString("\n")
+	}
+	for _, forecast := range storageStats.Forecasts {
+		s.WriteString(m.formatForecast(forecast))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
 	// Summary
 	totalUsed := int64(0)
 	totalAvail := int64(0)
@@ -697,11 +828,314 @@ func (m *MonitoringModel) renderStorageView() string {
 	return s.String()
 }
 
+// formatForecast formats a capacity forecast as a single line
+func (m *MonitoringModel) formatForecast(forecast CapacityForecast) string {
+	name := forecast.Target
+	switch forecast.Resource {
+	case "inodes":
+		name = "inodes " + forecast.Target
+	case "database":
+		name = "monitor database"
+	}
+
+	amount := func(value float64) string {
+		if forecast.Unit == "bytes" {
+			if value < 0 {
+				return "-" + m.formatBytes(int64(-value))
+			}
+			return m.formatBytes(int64(value))
+		}
+		return fmt.Sprintf("%.1f%%", value)
+	}
+
+	statusIcon := "🟢"
+	fullIn := "not filling up"
+	if forecast.FullAt != nil {
+		remaining := time.Until(*forecast.FullAt)
+		if remaining < 0 {
+			remaining = 0
+		}
+		switch {
+		case remaining < 72*time.Hour:
+			statusIcon = "🔴"
+		case remaining < 14*24*time.Hour:
+			statusIcon = "🟡"
+		}
+		fullIn = "full in " + m.formatDuration(remaining)
+	}
+
+	growth := amount(forecast.GrowthPerDay)
+	if forecast.GrowthPerDay >= 0 {
+		growth = "+" + growth
+	}
+
+	return fmt.Sprintf("%s %-25s %s used, %s/day, %s",
+		statusIcon, name, amount(forecast.Current), growth, fullIn)
+}
+
+// renderIncidentsView renders recent incidents and the timeline of the selected one
+func (m *MonitoringModel) renderIncidentsView() string {
+	var s strings.Builder
+
+	s.WriteString(infoStyle.Render("=== INCIDENTS ==="))
+	s.WriteString("\n\n")
+
+	incidents, err := m.fetchIncidents()
+	if err != nil {
+		s.WriteString(warnStyle.Render("⚠ Incidents unavailable: monitoring agent not reachable or storage not configured"))
+		s.WriteString("\n")
+		return s.String()
+	}
+	if len(incidents) == 0 {
+		s.WriteString(helpStyle.Render("No incidents recorded, they open when an alert fires"))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	selected := m.getIncident()
+	if selected >= len(incidents) {
+		selected = len(incidents) - 1
+	}
+
+	// Recent incidents, the selected one marked
+	s.WriteString(infoStyle.Render("🚨 RECENT INCIDENTS"))
+	s.WriteString("\n")
+	for i, incident := range incidents {
+		marker := "  "
+		if i == selected {
+			marker = "▶ "
+		}
+		s.WriteString(marker + m.formatIncident(incident))
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	// Timeline of the selected incident
+	timeline, err := m.fetchIncidentTimeline(incidents[selected].ID)
+	if err != nil {
+		s.WriteString(warnStyle.Render(fmt.Sprintf("⚠ Failed to load incident timeline: %v", err)))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	s.WriteString(infoStyle.Render(fmt.Sprintf("🕒 TIMELINE #%d: %s", timeline.Incident.ID, timeline.Incident.Title)))
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render(fmt.Sprintf("%s → %s, %d events",
+		timeline.Since.Format("2006-01-02 15:04:05"), timeline.Until.Format("2006-01-02 15:04:05"), len(timeline.Events))))
+	s.WriteString("\n")
+	if timeline.Incident.Message != "" {
+		s.WriteString(timeline.Incident.Message)
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	for _, storedEvent := range timeline.Events {
+		event := MonitoringEvent{
+			ID:        fmt.Sprintf("%d", storedEvent.ID),
+			Type:      m.mapEventType(storedEvent.Type),
+			Severity:  storedEvent.Severity,
+			Source:    m.getEventSource(storedEvent),
+			Message:   storedEvent.Message,
+			Timestamp: storedEvent.Timestamp,
+			Resolved:  storedEvent.Type == "alert" && storedEvent.Severity == "info",
+		}
+
+		// Events before the incident opened are context, mark when it started
+		prefix := "  "
+		if !storedEvent.Timestamp.Before(timeline.Incident.OpenedAt) {
+			prefix = "│ "
+		}
+		s.WriteString(prefix + m.formatEvent(event))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// formatIncident formats an incident as a single line
+func (m *MonitoringModel) formatIncident(incident Incident) string {
+	statusIcon := "✅"
+	duration := "ongoing for " + m.formatDuration(time.Since(incident.OpenedAt))
+	if incident.ResolvedAt != nil {
+		duration = "lasted " + m.formatDuration(incident.ResolvedAt.Sub(incident.OpenedAt))
+	} else {
+		switch incident.Severity {
+		case "critical":
+			statusIcon = "🔴"
+		case "warning":
+			statusIcon = "🟡"
+		default:
+			statusIcon = "🔵"
+		}
+	}
+
+	line := fmt.Sprintf("%s #%-4d [%s] %-30s %s, %s",
+		statusIcon, incident.ID, incident.OpenedAt.Format("01-02 15:04"), incident.Title, incident.Status, duration)
+	if incident.ResolvedAt == nil && incident.Severity == "critical" {
+		return errorStyle.Render(line)
+	}
+	return line
+}
+
+// fetchIncidents fetches recent incidents from the monitoring agent, most recent first
+func (m *MonitoringModel) fetchIncidents() ([]Incident, error) {
+	resp, err := http.Get(fmt.Sprintf("http://localhost:9090/api/v1/incidents?limit=%d", incidentListLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to monitoring agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("monitoring agent returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var response struct {
+		Incidents []Incident `json:"incidents"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse incidents response: %w", err)
+	}
+
+	return response.Incidents, nil
+}
+
+// fetchIncidentTimeline fetches an incident with the events recorded around it
+func (m *MonitoringModel) fetchIncidentTimeline(id int64) (*IncidentTimeline, error) {
+	resp, err := http.Get(fmt.Sprintf("http://localhost:9090/api/v1/incidents/%d", id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to monitoring agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("monitoring agent returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var timeline IncidentTimeline
+	if err := json.Unmarshal(body, &timeline); err != nil {
+		return nil, fmt.Errorf("failed to parse incident timeline response: %w", err)
+	}
+
+	return &timeline, nil
+}
+
+// renderSLOsView renders the error budget and burn rates of every SLO
+func (m *MonitoringModel) renderSLOsView() string {
+	var s strings.Builder
+
+	s.WriteString(infoStyle.Render("=== SERVICE LEVEL OBJECTIVES ==="))
+	s.WriteString("\n\n")
+
+	slos, err := m.fetchSLOs()
+	if err != nil {
+		s.WriteString(warnStyle.Render("⚠ SLOs unavailable: monitoring agent not reachable or storage not configured"))
+		s.WriteString("\n")
+		return s.String()
+	}
+	if len(slos) == 0 {
+		s.WriteString(helpStyle.Render("No SLOs configured, add them under slos in the monitor configuration"))
+		s.WriteString("\n")
+		return s.String()
+	}
+
+	for _, slo := range slos {
+		s.WriteString(m.formatSLO(slo))
+		s.WriteString("\n")
+
+		details := fmt.Sprintf("   %s over %s", slo.Check, slo.Window)
+		if slo.SLI != nil {
+			details += fmt.Sprintf(", %.3f%% good (%d/%d checks)", *slo.SLI, slo.Good, slo.Total)
+		}
+		if slo.P95Ms != nil {
+			details += fmt.Sprintf(", p95 %.0fms", *slo.P95Ms)
+		}
+		if slo.DataSince != nil {
+			details += ", data since " + slo.DataSince.Format("2006-01-02 15:04")
+		}
+		s.WriteString(helpStyle.Render(details))
+		s.WriteString("\n")
+
+		if len(slo.BurnRates) > 0 {
+			var rates []string
+			for _, window := range []string{"5m", "30m", "1h", "6h", "1d", "3d"} {
+				if rate, ok := slo.BurnRates[window]; ok {
+					rates = append(rates, fmt.Sprintf("%s %.1fx", window, rate))
+				}
+			}
+			s.WriteString(helpStyle.Render("   Burn rate: " + strings.Join(rates, ", ")))
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// formatSLO formats the objective and remaining error budget of an SLO as a single line
+func (m *MonitoringModel) formatSLO(slo SLOStatus) string {
+	objective := fmt.Sprintf("%.2f%% available", slo.Objective)
+	if slo.ThresholdMs != nil {
+		objective = fmt.Sprintf("%.2f%% under %dms", slo.Objective, *slo.ThresholdMs)
+	}
+
+	budget := "no data"
+	if slo.BudgetRemaining != nil {
+		budget = fmt.Sprintf("%.1f%% budget left", *slo.BudgetRemaining)
+	}
+
+	switch slo.Status {
+	case "breached":
+		return errorStyle.Render(fmt.Sprintf("🔴 %-30s %-24s %s, breached", slo.Name, objective, budget))
+	case "at_risk":
+		return warnStyle.Render(fmt.Sprintf("🟡 %-30s %-24s %s, at risk", slo.Name, objective, budget))
+	case "met":
+		return fmt.Sprintf("🟢 %-30s %-24s %s", slo.Name, objective, budget)
+	default:
+		return fmt.Sprintf("⚪ %-30s %-24s %s", slo.Name, objective, budget)
+	}
+}
+
+// fetchSLOs fetches the SLO statuses from the monitoring agent
+func (m *MonitoringModel) fetchSLOs() ([]SLOStatus, error) {
+	resp, err := http.Get("http://localhost:9090/api/v1/slos")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to monitoring agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("monitoring agent returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var slos []SLOStatus
+	if err := json.Unmarshal(body, &slos); err != nil {
+		return nil, fmt.Errorf("failed to parse SLOs response: %w", err)
+	}
+
+	return slos, nil
+}
+
 // renderHelp renders the help text
 func (m *MonitoringModel) renderHelp() string {
 	help := []string{
-		"Navigation: l=Live, h=Historical, e=Events, s=Storage",
+		"Navigation: l=Live, h=Historical, e=Events, s=Storage, i=Incidents, o=SLOs",
 		"Time Range: 1=1h, 6=6h, d=24h, w=7d, m=30d",
+		"Incidents: n=Next, p=Previous",
 		"Controls: r=Refresh, a=Toggle auto-refresh, ↑/↓=Scroll",
 		"Esc=Back to menu, q=Quit",
 	}
@@ -756,11 +1190,15 @@ func (m *MonitoringModel) getMaxScrollUnsafe() int {
 	case MonitoringViewLive:
 		contentLines = 25 // Estimated lines for live view
 	case MonitoringViewHistorical:
-		contentLines = 35 // Estimated lines for historical view
+		contentLines = 45 // Estimated lines for historical view
 	case MonitoringViewEvents:
 		contentLines = 30 // Estimated lines for events view
 	case MonitoringViewStorage:
-		contentLines = 40 // Estimated lines for storage view
+		contentLines = 50 // Estimated lines for storage view
+	case MonitoringViewIncidents:
+		contentLines = 60 // Estimated lines for incidents view
+	case MonitoringViewSLOs:
+		contentLines = 40 // Estimated lines for SLOs view
 	default:
 		contentLines = 20
 	}
@@ -935,9 +1373,65 @@ func (m *MonitoringModel) fetchHistoricalData(timeRange HistoricalTimeRange) (Hi
 		histData.AvgLoad = loadSum / float64(len(loadHistory))
 	}
 
+	// Markers are optional, the charts are shown without them
+	histData.Markers, _ = m.fetchOperationMarkers(since, now)
+
 	return histData, nil
 }
 
+// fetchOperationMarkers fetches the Crucible operations that changed the server in a range, oldest first
+func (m *MonitoringModel) fetchOperationMarkers(since, until time.Time) ([]OperationMarker, error) {
+	var markers []OperationMarker
+	for _, eventType := range markerEventTypes {
+		params := fmt.Sprintf("type=%s&since=%s&until=%s&limit=50", eventType,
+			url.QueryEscape(since.Format(time.RFC3339)), url.QueryEscape(until.Format(time.RFC3339)))
+
+		resp, err := http.Get("http://localhost:9090/api/v1/events?" + params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to monitoring agent: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("monitoring agent returned status %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		var response struct {
+			Events []StoredEvent `json:"events"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse events response: %w", err)
+		}
+
+		// Only operations recorded by Crucible itself are marked
+		for _, event := range response.Events {
+			if source, _ := event.Details["source"].(string); source != "crucible" {
+				continue
+			}
+			operation, _ := event.Details["operation"].(string)
+			if action, ok := event.Details["action"].(string); ok {
+				operation = action + " " + operation
+			}
+			user, _ := event.Details["user"].(string)
+			markers = append(markers, OperationMarker{
+				Type:      event.Type,
+				Operation: operation,
+				User:      user,
+				Failed:    event.Severity == "error",
+				Timestamp: event.Timestamp,
+			})
+		}
+	}
+
+	sort.Slice(markers, func(i, j int) bool {
+		return markers[i].Timestamp.Before(markers[j].Timestamp)
+	})
+	return markers, nil
+}
+
 // Metric represents a stored metric from the monitoring agent API
 type StoredMetric struct {
 	ID         int64                  `json:"id"`
@@ -987,11 +1481,11 @@ func (m *MonitoringModel) getServerEntityID() (int64, error) {
 	return response.Entities[0].ID, nil
 }
 
-// fetchMetricHistory fetches historical metrics for a specific metric from the monitoring agent
+// fetchMetricHistory fetches a metric averaged over up to 100 steps of the time range, oldest first
 func (m *MonitoringModel) fetchMetricHistory(entityID int64, metricName string, since, until time.Time) ([]StoredMetric, error) {
 	// Build query parameters with proper URL encoding
-	baseURL := "http://localhost:9090/api/v1/metrics"
-	params := fmt.Sprintf("entity_id=%d&metric_name=%s&since=%s&until=%s&limit=100",
+	baseURL := "http://localhost:9090/api/v1/metrics/query"
+	params := fmt.Sprintf("entity_id=%d&metric_name=%s&since=%s&until=%s&aggregation=avg&points=100",
 		entityID,
 		metricName,
 		url.QueryEscape(since.Format(time.RFC3339)),
@@ -1015,15 +1509,33 @@ func (m *MonitoringModel) fetchMetricHistory(entityID int64, metricName string,
 	}
 
 	var response struct {
-		Metrics []StoredMetric `json:"metrics"`
-		Count   int            `json:"count"`
+		Timestamps []time.Time `json:"timestamps"`
+		Series     []struct {
+			Values []*float64 `json:"values"`
+		} `json:"series"`
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse metrics response: %w", err)
 	}
 
-	return response.Metrics, nil
+	// Steps without samples are skipped
+	var metrics []StoredMetric
+	for _, series := range response.Series {
+		for i, value := range series.Values {
+			if value == nil || i >= len(response.Timestamps) {
+				continue
+			}
+			metrics = append(metrics, StoredMetric{
+				EntityID:   &entityID,
+				Timestamp:  response.Timestamps[i],
+				MetricName: metricName,
+				Value:      *value,
+			})
+		}
+	}
+
+	return metrics, nil
 }
 
 // StoredEvent represents an event from the monitoring agent API
@@ -1290,8 +1802,8 @@ func (m *MonitoringModel) generateHistoricalData(timeRange HistoricalTimeRange)
 	return data
 }
 
-// renderSimpleChart creates a simple ASCII chart
-func (m *MonitoringModel) renderSimpleChart(name string, values []float64, unit string) string {
+// renderSimpleChart creates a simple ASCII chart, with an optional marker row under its axis
+func (m *MonitoringModel) renderSimpleChart(name string, values []float64, unit string, markerRow string) string {
 	if len(values) == 0 {
 		return "No data available"
 	}
@@ -1309,11 +1821,7 @@ func (m *MonitoringModel) renderSimpleChart(name string, values []float64, unit
 
 	// Create chart with simple bars
 	var chart strings.Builder
-	contentWidth := m.shared.GetContentWidth()
-	chartWidth := contentWidth - 20 // Reserve space for y-axis labels and margins
-	if chartWidth < 20 {
-		chartWidth = 20 // Minimum chart width
-	}
+	chartWidth := m.chartWidth()
 	chartHeight := 8
 
 	// Normalize values to chart height
@@ -1349,6 +1857,10 @@ func (m *MonitoringModel) renderSimpleChart(name string, values []float64, unit
 		chart.WriteString("-")
 	}
 	chart.WriteString("\n")
+	if markerRow != "" {
+		chart.WriteString(markerRow)
+		chart.WriteString("\n")
+	}
 
 	// Add min/max info
 	chart.WriteString(fmt.Sprintf("      Min: %.1f%s  Max: %.1f%s  Points: %d", min, unit, max, unit, len(values)))
@@ -1356,6 +1868,54 @@ func (m *MonitoringModel) renderSimpleChart(name string, values []float64, unit
 	return chart.String()
 }
 
+// chartWidth returns the number of data points a chart shows
+func (m *MonitoringModel) chartWidth() int {
+	chartWidth := m.shared.GetContentWidth() - 20 // Reserve space for y-axis labels and margins
+	if chartWidth < 20 {
+		chartWidth = 20 // Minimum chart width
+	}
+	return chartWidth
+}
+
+// renderMarkerRow renders a row under a chart marking the steps in which operations ran
+func (m *MonitoringModel) renderMarkerRow(histData HistoricalData) string {
+	points := len(histData.Timestamps)
+	if points > m.chartWidth() {
+		points = m.chartWidth()
+	}
+	if len(histData.Markers) == 0 || points == 0 {
+		return ""
+	}
+
+	row := []rune(strings.Repeat(" ", points))
+	for _, marker := range histData.Markers {
+		if marker.Timestamp.Before(histData.Timestamps[0]) {
+			continue
+		}
+
+		// Mark the last step starting at or before the operation
+		column := 0
+		for i := 0; i < points; i++ {
+			if !histData.Timestamps[i].After(marker.Timestamp) {
+				column = i
+			}
+		}
+		if row[column] == ' ' || marker.Failed {
+			row[column] = []rune(m.markerSymbol(marker))[0]
+		}
+	}
+
+	return "       " + warnStyle.Render(string(row))
+}
+
+// markerSymbol returns the chart symbol of an operation
+func (m *MonitoringModel) markerSymbol(marker OperationMarker) string {
+	if marker.Failed {
+		return "✖"
+	}
+	return "▲"
+}
+
 func (m *MonitoringModel) getAutoRefresh() bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -1394,6 +1954,27 @@ func (m *MonitoringModel) adjustScrollPos(delta int) {
 	m.scrollPos = newPos
 }
 
+// getIncident returns the selected incident index
+func (m *MonitoringModel) getIncident() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.incident
+}
+
+// adjustIncident moves the incident selection, rendering clamps it to the incidents listed
+func (m *MonitoringModel) adjustIncident(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	newIncident := m.incident + delta
+	if newIncident < 0 {
+		newIncident = 0
+	}
+	if newIncident >= incidentListLimit {
+		newIncident = incidentListLimit - 1
+	}
+	m.incident = newIncident
+}
+
 // generateHistoricalEvents creates mock historical events for the events view
 func (m *MonitoringModel) generateHistoricalEvents(timeRange HistoricalTimeRange) []MonitoringEvent {
 	var events []MonitoringEvent
@@ -1570,9 +2151,37 @@ func (m *MonitoringModel) generateStorageStatistics() StorageStatistics {
 		stats.TotalSiteSize += site.SizeBytes
 	}
 
+	// Get capacity forecasts from the monitoring agent
+	stats.Forecasts, stats.ForecastError = m.fetchForecasts()
+
 	return stats
 }
 
+// fetchForecasts fetches the capacity forecasts from the monitoring agent, soonest full first
+func (m *MonitoringModel) fetchForecasts() ([]CapacityForecast, error) {
+	resp, err := http.Get("http://localhost:9090/api/v1/forecasts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to monitoring agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("monitoring agent returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var forecasts []CapacityForecast
+	if err := json.Unmarshal(body, &forecasts); err != nil {
+		return nil, fmt.Errorf("failed to parse forecasts response: %w", err)
+	}
+
+	return forecasts, nil
+}
+
 // getRealDiskUsage gets actual disk usage from mounted filesystems
 func (m *MonitoringModel) getRealDiskUsage() []DiskUsage {
 	var diskUsage []DiskUsage
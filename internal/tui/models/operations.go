@@ -0,0 +1,199 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"crucible/internal/monitor"
+)
+
+// operationDefaultAgentAddr is where the monitoring agent listens when no configuration is found
+const operationDefaultAgentAddr = "localhost:9090"
+
+// operationEventTimeout bounds how long a finished operation waits for the agent
+const operationEventTimeout = 2 * time.Second
+
+// Operation represents a completed Crucible action such as an install, deploy or backup
+type Operation struct {
+	Name      string // Queue service name, e.g. "mysql" or "Laravel Site Updates"
+	Action    string // Service control action, empty for queued commands
+	StartedAt time.Time
+	Steps     int // Commands that ran
+	Total     int // Commands that were queued
+	ExitCode  int
+	Err       error
+}
+
+// operationEvent is an operation as stored by the monitoring agent
+type operationEvent struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Type      string                 `json:"event_type"`
+	Severity  string                 `json:"severity"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details"`
+}
+
+// recordOperation pushes a completed operation to the monitoring agent as an
+// event, giving an audit trail and deploy markers on the historical charts.
+// Operations that only read state are skipped, and recording is best effort:
+// the agent may not be running, so it is posted in the background.
+func recordOperation(op Operation) {
+	eventType := operationEventType(op)
+	if eventType == "" {
+		return
+	}
+
+	now := time.Now()
+	duration := now.Sub(op.StartedAt)
+	if op.StartedAt.IsZero() {
+		duration = 0
+	}
+
+	subject := op.Name
+	if op.Action != "" {
+		subject = fmt.Sprintf("%s %s", op.Action, op.Name)
+	}
+
+	username := operationUser()
+	event := operationEvent{
+		Timestamp: now,
+		Type:      eventType,
+		Severity:  "info",
+		Message:   fmt.Sprintf("Crucible %s completed in %s", subject, duration.Round(time.Second)),
+		Details: map[string]interface{}{
+			"source":      "crucible",
+			"operation":   op.Name,
+			"duration_ms": duration.Milliseconds(),
+			"exit_code":   op.ExitCode,
+			"user":        username,
+			"steps":       op.Steps,
+			"total_steps": op.Total,
+		},
+	}
+	if op.Action != "" {
+		event.Details["action"] = op.Action
+	}
+	if op.Err != nil {
+		event.Severity = "error"
+		event.Message = fmt.Sprintf("Crucible %s failed at step %d of %d with exit code %d",
+			subject, op.Steps, op.Total, op.ExitCode)
+		event.Details["error"] = op.Err.Error()
+	}
+	if username != "" {
+		event.Message += " by " + username
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	go postOperationEvent(body)
+}
+
+// postOperationEvent posts an encoded operation event to the monitoring agent
+func postOperationEvent(body []byte) {
+	agent := loadOperationAgent()
+	req, err := http.NewRequest(http.MethodPost, agent.eventsURL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if agent.token != "" {
+		req.Header.Set("Authorization", "Bearer "+agent.token)
+	}
+	client := &http.Client{Timeout: operationEventTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// operationAgent is where and how operation events are posted
+type operationAgent struct {
+	eventsURL string
+	token     string // Only needed when the agent has an API token
+}
+
+var (
+	operationAgentOnce   sync.Once
+	operationAgentConfig operationAgent
+)
+
+// loadOperationAgent reads the listen address and API token of the monitoring
+// agent from its configuration, once
+func loadOperationAgent() operationAgent {
+	operationAgentOnce.Do(func() {
+		addr := operationDefaultAgentAddr
+		token := os.Getenv("CRUCIBLE_MONITOR_API_TOKEN")
+		if config, err := monitor.LoadConfig(""); err == nil {
+			addr = config.Agent.ListenAddr
+			token = config.Agent.GetAPIToken()
+		}
+
+		// Agents listening on every interface are reached on localhost
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+				addr = net.JoinHostPort("localhost", port)
+			}
+		}
+		operationAgentConfig = operationAgent{
+			eventsURL: "http://" + addr + "/api/v1/events",
+			token:     token,
+		}
+	})
+	return operationAgentConfig
+}
+
+// operationEventType maps an operation to a storage event type, empty for read-only operations
+func operationEventType(op Operation) string {
+	if op.Action != "" {
+		switch strings.ToLower(op.Action) {
+		case "restart", "reload":
+			return "restart"
+		case "start", "enable":
+			return "start"
+		case "stop", "disable":
+			return "stop"
+		default:
+			return ""
+		}
+	}
+
+	name := strings.ToLower(op.Name)
+	for _, readOnly := range []string{"status", "assessment", "report", "ssh test"} {
+		if strings.Contains(name, readOnly) {
+			return ""
+		}
+	}
+
+	switch {
+	case strings.Contains(name, "backup"):
+		return "backup"
+	case strings.Contains(name, "update"):
+		return "update"
+	case strings.Contains(name, "hardening"), strings.Contains(name, "queue workers"), strings.Contains(name, "key generation"):
+		return "maintenance"
+	default:
+		return "install"
+	}
+}
+
+// operationUser returns the user who ran Crucible, looking through sudo
+func operationUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
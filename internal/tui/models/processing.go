@@ -301,6 +301,7 @@ func (m *ProcessingModel) handleCommandCompleted(msg CmdCompletedMsg) {
 
 	if msg.Result.Error != nil {
 		// Command failed
+		operation := queue.Operation(msg.Result)
 		queue.Reset()
 		m.report = append(m.report, errorStyle.Render(fmt.Sprintf("❌ Failed: %v", msg.Result.Error)))
 		if strings.TrimSpace(msg.Result.Output) != "" {
@@ -308,6 +309,7 @@ func (m *ProcessingModel) handleCommandCompleted(msg CmdCompletedMsg) {
 		}
 		m.message = ""
 		m.isComplete = true
+		recordOperation(operation)
 		return
 	}
 
@@ -330,6 +332,7 @@ func (m *ProcessingModel) handleCommandCompleted(msg CmdCompletedMsg) {
 		}
 	} else {
 		// All commands completed
+		operation := queue.Operation(msg.Result)
 		queue.Reset()
 		m.message = ""
 		m.isComplete = true
@@ -338,6 +341,7 @@ func (m *ProcessingModel) handleCommandCompleted(msg CmdCompletedMsg) {
 		if !isSecurityOperation(msg.ServiceName) {
 			m.report = append(m.report, infoStyle.Render("✅ All operations completed successfully"))
 		}
+		recordOperation(operation)
 	}
 }
 
@@ -1345,6 +1349,13 @@ func (m *ProcessingModel) handleServiceControl(serviceName, serviceAction string
 		"",
 	}
 
+	operation := Operation{
+		Name:      serviceName,
+		Action:    serviceAction,
+		StartedAt: time.Now(),
+		Total:     len(commands),
+	}
+
 	// Execute each command
 	for i, command := range commands {
 		if i < len(descriptions) {
@@ -1365,7 +1376,14 @@ func (m *ProcessingModel) handleServiceControl(serviceName, serviceAction string
 		}
 
 		output, err := cmd.CombinedOutput()
+		if operation.Err == nil {
+			operation.Steps = i + 1
+		}
 		if err != nil {
+			if operation.Err == nil {
+				operation.Err = err
+				operation.ExitCode = getExitCodeFromError(err)
+			}
 			report = append(report, errorStyle.Render(fmt.Sprintf("❌ Command failed: %v", err)))
 			if len(output) > 0 {
 				report = append(report, fmt.Sprintf("Output: %s", string(output)))
@@ -1381,6 +1399,7 @@ func (m *ProcessingModel) handleServiceControl(serviceName, serviceAction string
 
 	m.SetReport(report)
 	m.SetComplete(true)
+	recordOperation(operation)
 }
 
 // handleSecurityAssessment runs a security assessment
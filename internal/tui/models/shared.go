@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"crucible/internal/logging"
 )
 
@@ -23,6 +25,7 @@ type CommandQueue struct {
 	ServiceName  string
 	Results      []string
 	IsProcessing bool
+	StartedAt    time.Time // When the first command was taken
 }
 
 // AppState represents the different states of the application
@@ -112,6 +115,9 @@ func (cq *CommandQueue) Next() (command, description string, ok bool) {
 	if !cq.HasNext() {
 		return "", "", false
 	}
+	if cq.Index == 0 {
+		cq.StartedAt = time.Now()
+	}
 	command = cq.Commands[cq.Index]
 	description = cq.Descriptions[cq.Index]
 	cq.Index++
@@ -126,6 +132,19 @@ func (cq *CommandQueue) Reset() {
 	cq.ServiceName = ""
 	cq.Results = []string{}
 	cq.IsProcessing = false
+	cq.StartedAt = time.Time{}
+}
+
+// Operation returns the queue as a completed operation that ended with result
+func (cq *CommandQueue) Operation(result logging.LoggedExecResult) Operation {
+	return Operation{
+		Name:      cq.ServiceName,
+		StartedAt: cq.StartedAt,
+		Steps:     cq.Index,
+		Total:     len(cq.Commands),
+		ExitCode:  result.ExitCode,
+		Err:       result.Error,
+	}
 }
 
 // AddResult adds a result to the queue